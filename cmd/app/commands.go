@@ -0,0 +1,314 @@
+package main
+
+import (
+	"course/internal/blackjack"
+	"course/internal/counting"
+	"course/internal/simulator"
+	"course/internal/tui"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+type command struct {
+	name        string
+	description string
+	// Arguments after the flags in the usage text
+	args string
+	// Number of the arguments after the flags, maxArgs is not limited if negative
+	minArgs int
+	maxArgs int
+	// Whether the command takes the table flags and config
+	table bool
+	// Adds the flags of the command
+	flags func(fs *flag.FlagSet, opts *options, env *envSource)
+	run   func(opts *options, stdout io.Writer, stderr io.Writer) error
+}
+
+var playCommand = &command{
+	name:        "play",
+	description: "Play blackjack against the dealer together with bots",
+	table:       true,
+	flags: func(fs *flag.FlagSet, opts *options, env *envSource) {
+		fs.BoolVar(&opts.cfg.Trainer, "trainer", env.getBool("BLACKJACK_TRAINER", false), "compare your moves with the basic strategy and show the mistakes (BLACKJACK_TRAINER)")
+		fs.StringVar(&opts.seats, "seats", env.getString("BLACKJACK_SEATS", ""), fmt.Sprintf("comma-separated seats of a hot-seat game in the dealing order: the player names and %q for the bots, overrides -username and -bots (BLACKJACK_SEATS)", seatBot))
+		fs.BoolVar(&opts.cfg.CountQuiz, "count-quiz", env.getBool("BLACKJACK_COUNT_QUIZ", false), "ask for the running count of -count-system between the rounds (BLACKJACK_COUNT_QUIZ)")
+		fs.StringVar(&opts.cfg.CountSystem, "count-system", env.getString("BLACKJACK_COUNT_SYSTEM", counting.HiLo.Name), fmt.Sprintf("counting system of the count quiz: %s (BLACKJACK_COUNT_SYSTEM)", strings.Join(counting.SystemNames(), ", ")))
+		fs.StringVar(&opts.loadFile, "load", env.getString("BLACKJACK_LOAD", ""), fmt.Sprintf("file of a saved game to resume, the save command of the game writes to it too, %s if empty (BLACKJACK_LOAD)", blackjack.DefaultSaveFile))
+		fs.StringVar(&opts.scenarioFile, "scenario", env.getString("BLACKJACK_SCENARIO", ""), "file with the cards stacked on top of the shoes, a line for every shoe, the shoes are random after them (BLACKJACK_SCENARIO)")
+		fs.StringVar(&opts.ui, "ui", env.getString("BLACKJACK_UI", uiAuto), fmt.Sprintf("view of the game: %s for the full screen, %s for the text, %s for the full screen if the output is a terminal and the text is printed (BLACKJACK_UI)", uiFull, uiPlain, uiAuto))
+	},
+	run: runPlay,
+}
+
+var commands = []*command{
+	playCommand,
+	{
+		name:        "simulate",
+		description: "Play the given number of rounds with bots only and print their results",
+		table:       true,
+		flags: func(fs *flag.FlagSet, opts *options, env *envSource) {
+			// Every seat is taken by a bot, so the username is not required
+			opts.cfg.BotsOnly = true
+
+			fs.IntVar(&opts.rounds, "rounds", env.getInt("BLACKJACK_ROUNDS", 1000), "number of rounds to play (BLACKJACK_ROUNDS)")
+			fs.BoolVar(&opts.verbose, "verbose", false, "print the game text")
+			fs.StringVar(&opts.strategies, "strategies", "", fmt.Sprintf("comma-separated strategies to measure headless instead of playing the table: %s", strings.Join(blackjack.StrategyNames(), ", ")))
+			fs.IntVar(&opts.workers, "workers", 0, "number of goroutines of the strategies simulation, the number of CPUs if 0")
+		},
+		run: runSimulate,
+	},
+	{
+		name:        "replay",
+		description: "Step through a hand from the hand history file and check it with the engine",
+		args:        "history-file",
+		minArgs:     1,
+		maxArgs:     1,
+		flags: func(fs *flag.FlagSet, opts *options, env *envSource) {
+			fs.IntVar(&opts.handNumber, "hand", 0, "number of the hand to replay, the first one by default")
+			addCardStyleFlag(fs, opts, env)
+		},
+		run: func(opts *options, stdout io.Writer, stderr io.Writer) error {
+			return runReplay(opts.args[0], opts.handNumber, opts.cfg.CardStyle, stdout)
+		},
+	},
+	{
+		name:        "stats",
+		description: "Print the results of the players for every hand history file and for all the files together",
+		args:        "history-file...",
+		minArgs:     1,
+		maxArgs:     -1,
+		run:         runStats,
+	},
+	{
+		name:        "deck",
+		description: "Print a shuffled deck of the table config",
+		table:       true,
+		flags: func(fs *flag.FlagSet, opts *options, env *envSource) {
+			fs.BoolVar(&opts.summary, "summary", false, "print the number of cards of every value instead of the cards")
+		},
+		run: runDeck,
+	},
+	{
+		name:        "serve",
+		description: "Host a table for the players connected over TCP with the client command or over HTTP",
+		table:       true,
+		flags: func(fs *flag.FlagSet, opts *options, env *envSource) {
+			addNetworkFlags(fs, opts, env)
+			fs.StringVar(&opts.httpAddr, "http", env.getString("BLACKJACK_HTTP", ""), "address of the HTTP/JSON API of the tables, the API is not served if empty (BLACKJACK_HTTP)")
+			fs.StringVar(&opts.allowOrigins, "allow-origins", env.getString("BLACKJACK_ALLOW_ORIGINS", ""), "comma-separated origins of the other sites whose pages may open the event streams of the HTTP API (BLACKJACK_ALLOW_ORIGINS)")
+			fs.IntVar(&opts.users, "users", env.getInt("BLACKJACK_USERS", 1), "number of the seats for the players, the game starts when all of them are taken (BLACKJACK_USERS)")
+		},
+		run: runServe,
+	},
+	{
+		name:        "client",
+		description: "Play at a table of the serve command",
+		flags: func(fs *flag.FlagSet, opts *options, env *envSource) {
+			addNetworkFlags(fs, opts, env)
+			fs.StringVar(&opts.cfg.Username, "username", env.getString("BLACKJACK_USERNAME", "Arasaki"), "your player name (BLACKJACK_USERNAME)")
+			fs.BoolVar(&opts.wait, "wait", env.getBool("BLACKJACK_WAIT", false), "wait for a seat if the table is full (BLACKJACK_WAIT)")
+			addCardStyleFlag(fs, opts, env)
+		},
+		run: runClient,
+	},
+}
+
+func findCommand(name string) *command {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+
+	return nil
+}
+
+// Create the game of the table config with the records requested by the options.
+// The game text is printed to out. The returned function closes the game and its records.
+func newGame(opts *options, out io.Writer, stdout io.Writer) (*blackjack.Blackjack, func(), error) {
+	cfg := opts.cfg
+	cfg.HandHistoryFile = opts.historyFile
+	cfg.Output = out
+
+	// The game is saved to the file it is resumed from
+	if opts.loadFile != "" {
+		cfg.SaveFile = opts.loadFile
+	}
+
+	if opts.scenarioFile != "" {
+		decks, err := blackjack.LoadScenario(opts.scenarioFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		cfg.Decks = decks
+	}
+
+	var eventLogs []io.Writer
+	var closers []io.Closer
+
+	closeAll := func() {
+		for _, closer := range closers {
+			closer.Close()
+		}
+	}
+
+	if opts.eventsFile != "" {
+		file, err := os.OpenFile(opts.eventsFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error when opening event log: %w", err)
+		}
+
+		closers = append(closers, file)
+		eventLogs = append(eventLogs, file)
+	}
+
+	if opts.output == outputJSONL {
+		eventLogs = append(eventLogs, stdout)
+	}
+
+	if len(eventLogs) > 0 {
+		cfg.EventLog = io.MultiWriter(eventLogs...)
+	}
+
+	bj, err := blackjack.NewBlackjack(cfg)
+	if err != nil {
+		closeAll()
+		return nil, nil, err
+	}
+
+	// The game closes the hand history file opened by it
+	closers = append([]io.Closer{bj}, closers...)
+
+	if opts.loadFile != "" {
+		if err := bj.LoadFile(opts.loadFile); err != nil {
+			closeAll()
+			return nil, nil, fmt.Errorf("error when loading the game: %w", err)
+		}
+		fmt.Fprintf(out, "The game is loaded from %s\n", opts.loadFile)
+	}
+
+	return bj, closeAll, nil
+}
+
+func runPlay(opts *options, stdout io.Writer, stderr io.Writer) error {
+	out := stdout
+	if opts.output == outputJSONL {
+		out = stderr
+	}
+
+	if useFullScreen(opts, stdout) {
+		return runFullScreen(opts, stdout)
+	}
+
+	bj, closeGame, err := newGame(opts, out, stdout)
+	if err != nil {
+		return err
+	}
+	defer closeGame()
+
+	return bj.Run()
+}
+
+// The full screen is used if it is asked for, or in the auto mode if the game text is printed to a terminal
+func useFullScreen(opts *options, stdout io.Writer) bool {
+	switch opts.ui {
+	case uiFull:
+		return true
+	case uiAuto:
+		return opts.output == outputText && isTerminal(stdout)
+	default:
+		return false
+	}
+}
+
+// Play on the full screen. The game text is shown in its messages panel
+func runFullScreen(opts *options, stdout io.Writer) error {
+	screen := tui.NewScreen(stdout, opts.cfg.Rules)
+	opts.cfg.Observers = append(opts.cfg.Observers, screen)
+
+	bj, closeGame, err := newGame(opts, screen, stdout)
+	if err != nil {
+		return err
+	}
+	defer closeGame()
+
+	if err = screen.Open(); err != nil {
+		return err
+	}
+
+	err = bj.Run()
+	if closeErr := screen.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+// The writer is a terminal, not a file or a pipe
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := file.Stat()
+
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func runSimulate(opts *options, stdout io.Writer, stderr io.Writer) error {
+	if opts.strategies != "" {
+		return runStrategies(opts, stdout)
+	}
+
+	opts.cfg.NoDelay = true
+
+	// The results are printed after the game text or the event log
+	out, report := io.Discard, stdout
+	if opts.verbose {
+		out = stdout
+	}
+	if opts.output == outputJSONL {
+		report = stderr
+		if opts.verbose {
+			out = stderr
+		}
+	}
+
+	stats := blackjack.NewStats()
+	opts.cfg.Observers = append(opts.cfg.Observers, stats)
+
+	bj, closeGame, err := newGame(opts, out, stdout)
+	if err != nil {
+		return err
+	}
+	defer closeGame()
+
+	if err = bj.Simulate(opts.rounds); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(report)
+	return stats.Report(report)
+}
+
+// Measure the strategies with the rules of the table config
+func runStrategies(opts *options, stdout io.Writer) error {
+	results, err := simulator.Run(simulator.Config{
+		Rules:       opts.cfg.Rules,
+		DecksNumber: opts.cfg.DecksNumber,
+		Penetration: opts.cfg.Penetration,
+		Rounds:      opts.rounds,
+		Workers:     opts.workers,
+		Seed:        opts.cfg.Seed,
+		Strategies:  strings.Split(opts.strategies, ","),
+	})
+	if err != nil {
+		return err
+	}
+
+	return simulator.Report(stdout, results)
+}
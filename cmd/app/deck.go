@@ -0,0 +1,40 @@
+package main
+
+import (
+	"course/internal/deck"
+	"course/pkg/random"
+	"fmt"
+	"io"
+)
+
+// Print the cards of a shuffled deck of the table config in the dealing order,
+// or the number of cards of every value
+func runDeck(opts *options, stdout io.Writer, stderr io.Writer) error {
+	shoe, err := deck.New(deck.NewDeckOptions{
+		DecksNumber: opts.cfg.DecksNumber,
+		// The deck is shuffled with the time if the seed is 0
+		Random: random.New(random.Config{Seed: opts.cfg.Seed}),
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "Decks: %d, cards: %d\n", opts.cfg.DecksNumber, shoe.Len())
+
+	if !opts.summary {
+		for i, card := range shoe.Cards() {
+			fmt.Fprintf(stdout, "%3d. %s\n", i+1, card.Format(opts.cfg.CardStyle))
+		}
+
+		return nil
+	}
+
+	counts := shoe.Composition()
+
+	// The ranks are printed from the lowest one
+	for _, rank := range deck.Ranks() {
+		fmt.Fprintf(stdout, "%-6s %d\n", rank, counts[rank])
+	}
+
+	return nil
+}
@@ -0,0 +1,439 @@
+package main
+
+import (
+	"course/internal/blackjack"
+	"course/internal/deck"
+	"course/internal/preset"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Output formats of the game
+const (
+	// The game is printed as text
+	outputText = "text"
+	// Game events are printed as JSON Lines, the game text goes to stderr
+	outputJSONL = "jsonl"
+)
+
+// Views of the play command
+const (
+	// The full-screen view if the output is a terminal, the text otherwise
+	uiAuto = "auto"
+	// The table is drawn on the full terminal screen
+	uiFull = "full"
+	// The game is printed as text
+	uiPlain = "plain"
+)
+
+// Seat of a bot in the -seats flag
+const seatBot = "bot"
+
+// Flags that override the preset, with their environment variables
+var presetFlags = []struct {
+	name    string
+	env     string
+	restore func(dst *blackjack.Config, src blackjack.Config)
+}{
+	{
+		name: "decks",
+		env:  "BLACKJACK_DECKS",
+		restore: func(dst *blackjack.Config, src blackjack.Config) {
+			dst.DecksNumber = src.DecksNumber
+		},
+	},
+	{
+		name: "h17",
+		env:  "BLACKJACK_H17",
+		restore: func(dst *blackjack.Config, src blackjack.Config) {
+			dst.Rules.DealerHitsSoft17 = src.Rules.DealerHitsSoft17
+		},
+	},
+	{
+		name: "pays-3to2",
+		env:  "BLACKJACK_PAYS_3TO2",
+		restore: func(dst *blackjack.Config, src blackjack.Config) {
+			dst.Rules.BlackjackPays3To2 = src.Rules.BlackjackPays3To2
+		},
+	},
+	{
+		name: "no-hole-card",
+		env:  "BLACKJACK_NO_HOLE_CARD",
+		restore: func(dst *blackjack.Config, src blackjack.Config) {
+			dst.Rules.NoHoleCard = src.Rules.NoHoleCard
+		},
+	},
+	{
+		name: "penetration",
+		env:  "BLACKJACK_PENETRATION",
+		restore: func(dst *blackjack.Config, src blackjack.Config) {
+			dst.Penetration = src.Penetration
+		},
+	},
+	{
+		name: "bots",
+		env:  "BLACKJACK_BOTS",
+		restore: func(dst *blackjack.Config, src blackjack.Config) {
+			dst.BotsNumber = src.BotsNumber
+		},
+	},
+	{
+		name: "money",
+		env:  "BLACKJACK_MONEY",
+		restore: func(dst *blackjack.Config, src blackjack.Config) {
+			dst.PlayersStartingMoney = src.PlayersStartingMoney
+		},
+	},
+	{
+		name: "bot-strategies",
+		env:  "BLACKJACK_BOT_STRATEGIES",
+		restore: func(dst *blackjack.Config, src blackjack.Config) {
+			dst.BotStrategyNames = src.BotStrategyNames
+		},
+	},
+	{
+		name: "delay",
+		env:  "BLACKJACK_DELAY",
+		restore: func(dst *blackjack.Config, src blackjack.Config) {
+			dst.Delay = src.Delay
+		},
+	},
+}
+
+type options struct {
+	cfg blackjack.Config
+	// Config file with the table presets
+	configFile string
+	// Name of the preset
+	preset string
+	// Output format
+	output string
+	// View of the play command, empty for the other commands
+	ui string
+	// Style of the printed cards
+	cardStyle string
+	// Strategy names of the bot seats
+	botStrategies string
+	// Seats of a hot-seat game
+	seats string
+	// Hand history file
+	historyFile string
+	// JSON Lines event log file
+	eventsFile string
+	// File with the stacked shoes of the play command
+	scenarioFile string
+	// Saved game to resume
+	loadFile string
+	// Number of the hand to replay
+	handNumber int
+	// Number of rounds to simulate
+	rounds int
+	// Print the game text of the simulation
+	verbose bool
+	// Strategies to measure with the simulator
+	strategies string
+	// Number of goroutines of the simulator
+	workers int
+	// Print the composition of the deck instead of the cards
+	summary bool
+	// TCP address of the table server
+	addr string
+	// Name of the table at the server
+	table string
+	// Number of the user seats of the served table
+	users int
+	// HTTP address of the table server
+	httpAddr string
+	// Comma-separated origins of the other sites allowed to open the event streams
+	allowOrigins string
+	// Wait for a seat if the table is full
+	wait bool
+	// Arguments after the flags
+	args []string
+	// Print the usage text
+	usage func()
+}
+
+// Default flag values from the environment variables.
+// Keeps the first error of parsing a variable.
+type envSource struct {
+	getenv func(string) string
+	err    error
+}
+
+func (e *envSource) getString(name string, value string) string {
+	if env := e.getenv(name); env != "" {
+		return env
+	}
+
+	return value
+}
+
+func (e *envSource) getInt(name string, value int) int {
+	env := e.getenv(name)
+	if env == "" || e.err != nil {
+		return value
+	}
+
+	parsed, err := strconv.Atoi(env)
+	if err != nil {
+		e.err = fmt.Errorf("invalid %s value %q: expected a number", name, env)
+		return value
+	}
+
+	return parsed
+}
+
+func (e *envSource) getInt64(name string, value int64) int64 {
+	env := e.getenv(name)
+	if env == "" || e.err != nil {
+		return value
+	}
+
+	parsed, err := strconv.ParseInt(env, 10, 64)
+	if err != nil {
+		e.err = fmt.Errorf("invalid %s value %q: expected a number", name, env)
+		return value
+	}
+
+	return parsed
+}
+
+func (e *envSource) getFloat64(name string, value float64) float64 {
+	env := e.getenv(name)
+	if env == "" || e.err != nil {
+		return value
+	}
+
+	parsed, err := strconv.ParseFloat(env, 64)
+	if err != nil {
+		e.err = fmt.Errorf("invalid %s value %q: expected a number", name, env)
+		return value
+	}
+
+	return parsed
+}
+
+func (e *envSource) getBool(name string, value bool) bool {
+	env := e.getenv(name)
+	if env == "" || e.err != nil {
+		return value
+	}
+
+	parsed, err := strconv.ParseBool(env)
+	if err != nil {
+		e.err = fmt.Errorf("invalid %s value %q: expected true or false", name, env)
+		return value
+	}
+
+	return parsed
+}
+
+func (e *envSource) getDuration(name string, value time.Duration) time.Duration {
+	env := e.getenv(name)
+	if env == "" || e.err != nil {
+		return value
+	}
+
+	parsed, err := time.ParseDuration(env)
+	if err != nil {
+		e.err = fmt.Errorf("invalid %s value %q: expected a duration like 500ms or 1s", name, env)
+		return value
+	}
+
+	return parsed
+}
+
+// Parse the command line flags of the command. Flags that are not passed are taken
+// from the environment variables shown in the usage text.
+func parseOptions(cmd *command, args []string, getenv func(string) string, output io.Writer) (*options, error) {
+	opts := &options{}
+	env := &envSource{getenv: getenv}
+
+	fs := flag.NewFlagSet("blackjack "+cmd.name, flag.ContinueOnError)
+	fs.SetOutput(output)
+
+	if cmd.table {
+		addTableFlags(fs, opts, env)
+	}
+
+	if cmd.flags != nil {
+		cmd.flags(fs, opts, env)
+	}
+
+	opts.usage = func() {
+		fmt.Fprintf(output, "Usage: %s\n\n", strings.TrimSpace("blackjack "+cmd.name+" [flags] "+cmd.args))
+		fmt.Fprintf(output, "%s.\n", cmd.description)
+		fmt.Fprintf(output, "The environment variable in brackets is used when the flag is not passed.\n\n")
+		fmt.Fprintf(output, "Flags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Usage = opts.usage
+
+	if env.err != nil {
+		fmt.Fprintln(output, env.err)
+		opts.usage()
+		return nil, env.err
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	opts.args = fs.Args()
+
+	if fs.NArg() < cmd.minArgs || (cmd.maxArgs >= 0 && fs.NArg() > cmd.maxArgs) {
+		err := fmt.Errorf("unexpected arguments: %v", fs.Args())
+		if fs.NArg() < cmd.minArgs {
+			err = fmt.Errorf("missing arguments: %s", cmd.args)
+		}
+		fmt.Fprintln(output, err)
+		opts.usage()
+		return nil, err
+	}
+
+	if opts.cardStyle != "" {
+		style, err := deck.ParseStyle(opts.cardStyle)
+		if err != nil {
+			fmt.Fprintln(output, err)
+			opts.usage()
+			return nil, err
+		}
+		opts.cfg.CardStyle = style
+	}
+
+	if !cmd.table {
+		return opts, nil
+	}
+
+	if opts.output != outputText && opts.output != outputJSONL {
+		err := fmt.Errorf("unknown output format %q", opts.output)
+		fmt.Fprintln(output, err)
+		opts.usage()
+		return nil, err
+	}
+
+	if opts.ui != "" && opts.ui != uiAuto && opts.ui != uiFull && opts.ui != uiPlain {
+		err := fmt.Errorf("unknown UI %q", opts.ui)
+		fmt.Fprintln(output, err)
+		opts.usage()
+		return nil, err
+	}
+
+	if opts.botStrategies != "" {
+		opts.cfg.BotStrategyNames = nil
+
+		for _, name := range strings.Split(opts.botStrategies, ",") {
+			name = strings.TrimSpace(name)
+			// The game creates the strategies with its seeded random source
+			if _, err := blackjack.NewStrategy(name, nil); err != nil {
+				fmt.Fprintln(output, err)
+				opts.usage()
+				return nil, err
+			}
+			opts.cfg.BotStrategyNames = append(opts.cfg.BotStrategyNames, name)
+		}
+	}
+
+	if opts.seats != "" {
+		opts.cfg.Seats = parseSeats(opts.seats)
+	}
+
+	if opts.preset != "" {
+		if err := opts.applyPreset(fs, getenv); err != nil {
+			fmt.Fprintln(output, err)
+			return nil, err
+		}
+	}
+
+	opts.cfg.NoDelay = opts.cfg.Delay == 0
+
+	if err := opts.cfg.Validate(); err != nil {
+		fmt.Fprintln(output, "invalid table config:", err)
+		opts.usage()
+		return nil, err
+	}
+
+	return opts, nil
+}
+
+// Seats like "Alex, bot, Sam": the names of the users and seatBot for the bots
+func parseSeats(value string) []blackjack.SeatConfig {
+	var seats []blackjack.SeatConfig
+
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+
+		if name == seatBot {
+			seats = append(seats, blackjack.SeatConfig{Bot: true})
+		} else {
+			seats = append(seats, blackjack.SeatConfig{Name: name})
+		}
+	}
+
+	return seats
+}
+
+// Flags of the table config and the game records, shared by the commands that run the engine
+func addTableFlags(fs *flag.FlagSet, opts *options, env *envSource) {
+	fs.StringVar(&opts.cfg.Username, "username", env.getString("BLACKJACK_USERNAME", "Arasaki"), "your player name (BLACKJACK_USERNAME)")
+	fs.IntVar(&opts.cfg.PlayersStartingMoney, "money", env.getInt("BLACKJACK_MONEY", 100), "starting money of every player (BLACKJACK_MONEY)")
+	fs.IntVar(&opts.cfg.BotsNumber, "bots", env.getInt("BLACKJACK_BOTS", 3), fmt.Sprintf("number of bots, from %d to %d (BLACKJACK_BOTS)", blackjack.MinBotsNumber, blackjack.MaxBotsNumber))
+	fs.IntVar(&opts.cfg.DecksNumber, "decks", env.getInt("BLACKJACK_DECKS", 1), fmt.Sprintf("number of decks, from %d to %d (BLACKJACK_DECKS)", blackjack.MinDecksNumber, blackjack.MaxDecksNumber))
+	fs.Float64Var(&opts.cfg.Penetration, "penetration", env.getFloat64("BLACKJACK_PENETRATION", 0), fmt.Sprintf("part of the shoe dealt before it is reshuffled, up to %g, a new shoe every round if 0 (BLACKJACK_PENETRATION)", blackjack.MaxPenetration))
+	fs.BoolVar(&opts.cfg.Rules.DealerHitsSoft17, "h17", env.getBool("BLACKJACK_H17", false), "the dealer takes a card on soft 17 (BLACKJACK_H17)")
+	fs.BoolVar(&opts.cfg.Rules.BlackjackPays3To2, "pays-3to2", env.getBool("BLACKJACK_PAYS_3TO2", false), "a natural blackjack pays 3 to 2 (BLACKJACK_PAYS_3TO2)")
+	fs.BoolVar(&opts.cfg.Rules.NoHoleCard, "no-hole-card", env.getBool("BLACKJACK_NO_HOLE_CARD", false), "the dealer takes the second card on his turn (BLACKJACK_NO_HOLE_CARD)")
+	fs.StringVar(&opts.botStrategies, "bot-strategies", env.getString("BLACKJACK_BOT_STRATEGIES", ""), fmt.Sprintf("comma-separated strategies of the bot seats in order, naive for the rest: %s (BLACKJACK_BOT_STRATEGIES)", strings.Join(blackjack.StrategyNames(), ", ")))
+	fs.Int64Var(&opts.cfg.Seed, "seed", env.getInt64("BLACKJACK_SEED", 0), "seed of the random source, random if 0 (BLACKJACK_SEED)")
+	fs.DurationVar(&opts.cfg.Delay, "delay", env.getDuration("BLACKJACK_DELAY", blackjack.Delay), "pause between the moves of the bots and the dealer, 0 to play without pauses (BLACKJACK_DELAY)")
+	fs.StringVar(&opts.output, "output", env.getString("BLACKJACK_OUTPUT", outputText), fmt.Sprintf("output format: %s or %s (BLACKJACK_OUTPUT)", outputText, outputJSONL))
+	addCardStyleFlag(fs, opts, env)
+	fs.StringVar(&opts.configFile, "config", env.getString("BLACKJACK_CONFIG", "configs/presets.yaml"), "YAML or JSON file with the table presets (BLACKJACK_CONFIG)")
+	fs.StringVar(&opts.preset, "preset", env.getString("BLACKJACK_PRESET", ""), "name of the table preset from the config file, the flags override it (BLACKJACK_PRESET)")
+	fs.StringVar(&opts.historyFile, "history", env.getString("BLACKJACK_HISTORY", ""), "file to append the hand history to (BLACKJACK_HISTORY)")
+	fs.StringVar(&opts.eventsFile, "events", env.getString("BLACKJACK_EVENTS", ""), "file to append the JSON Lines event log to (BLACKJACK_EVENTS)")
+}
+
+// Flag of the style of the printed cards
+func addCardStyleFlag(fs *flag.FlagSet, opts *options, env *envSource) {
+	styles := make([]string, 0, len(deck.Styles()))
+	for _, style := range deck.Styles() {
+		styles = append(styles, string(style))
+	}
+
+	fs.StringVar(&opts.cardStyle, "cards", env.getString("BLACKJACK_CARDS", string(deck.StyleShort)), fmt.Sprintf("style of the printed cards: %s (BLACKJACK_CARDS)", strings.Join(styles, ", ")))
+}
+
+// Set the preset fields that are not passed with the flags or the environment variables
+func (opts *options) applyPreset(fs *flag.FlagSet, getenv func(string) string) error {
+	presets, err := preset.Load(opts.configFile)
+	if err != nil {
+		return err
+	}
+
+	tablePreset, err := presets.Get(opts.preset)
+	if err != nil {
+		return err
+	}
+
+	passed := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		passed[f.Name] = true
+	})
+
+	cfg := opts.cfg
+	tablePreset.Apply(&opts.cfg)
+
+	for _, presetFlag := range presetFlags {
+		if passed[presetFlag.name] || getenv(presetFlag.env) != "" {
+			presetFlag.restore(&opts.cfg, cfg)
+		}
+	}
+
+	return nil
+}
@@ -1,24 +1,62 @@
 package main
 
 import (
-	"course/internal/blackjack"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 )
 
 func main() {
-	bj, err := blackjack.NewBlackjack(blackjack.Config{
-		PlayersStartingMoney: 100,
-		BotsNumber:           3,
-		Username:             "Arasaki",
-	})
-	if err != nil {
-		fmt.Println("error when creating blackjack game:", err)
-		return
+	os.Exit(run(os.Args[1:], os.Getenv, os.Stdout, os.Stderr))
+}
+
+// Run the command from the arguments and return the exit code.
+// The play command is run when the arguments start with a flag.
+func run(args []string, getenv func(string) string, stdout io.Writer, stderr io.Writer) int {
+	cmd := playCommand
+
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		if args[0] == "help" {
+			printUsage(stdout)
+			return 0
+		}
+
+		cmd = findCommand(args[0])
+		if cmd == nil {
+			fmt.Fprintf(stderr, "unknown command %q\n\n", args[0])
+			printUsage(stderr)
+			return 2
+		}
+		args = args[1:]
 	}
 
-	err = bj.Run()
+	opts, err := parseOptions(cmd, args, getenv, stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
 	if err != nil {
-		fmt.Println("error when running blackjack game:", err)
-		return
+		return 2
+	}
+
+	if err = cmd.run(opts, stdout, stderr); err != nil {
+		fmt.Fprintf(stderr, "error when running the %s command: %v\n", cmd.name, err)
+		return 1
 	}
+
+	return 0
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: blackjack <command> [flags]\n\n")
+	fmt.Fprintf(w, "Commands:\n")
+
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-9s %s\n", cmd.name, cmd.description)
+	}
+
+	fmt.Fprintf(w, "\nThe play command is run when no command is passed.\n")
+	fmt.Fprintf(w, "Run \"blackjack <command> -h\" to see the flags of the command.\n")
 }
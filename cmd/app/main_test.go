@@ -1,9 +1,18 @@
 package main
 
 import (
+	"bytes"
 	"course/internal/blackjack"
+	"course/internal/counting"
+	"course/internal/deck"
+	"course/internal/server"
 	"github.com/stretchr/testify/require"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func Test_main(t *testing.T) {
@@ -16,3 +25,592 @@ func Test_main(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, bj)
 }
+
+func Test_findHand(t *testing.T) {
+	hands := []*blackjack.RecordedHand{{Number: 4}, {Number: 5}}
+
+	testCases := []struct {
+		name       string
+		hands      []*blackjack.RecordedHand
+		handNumber int
+		check      func(hand *blackjack.RecordedHand, err error)
+	}{
+		{
+			name:       "First Hand",
+			hands:      hands,
+			handNumber: 0,
+			check: func(hand *blackjack.RecordedHand, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 4, hand.Number)
+			},
+		},
+		{
+			name:       "Hand By Number",
+			hands:      hands,
+			handNumber: 5,
+			check: func(hand *blackjack.RecordedHand, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 5, hand.Number)
+			},
+		},
+		{
+			name:       "Unknown Hand",
+			hands:      hands,
+			handNumber: 6,
+			check: func(hand *blackjack.RecordedHand, err error) {
+				require.Error(t, err)
+				require.Nil(t, hand)
+			},
+		},
+		{
+			name:       "Empty History",
+			hands:      nil,
+			handNumber: 0,
+			check: func(hand *blackjack.RecordedHand, err error) {
+				require.Error(t, err)
+				require.Nil(t, hand)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			hand, err := findHand(tc.hands, tc.handNumber)
+			tc.check(hand, err)
+		})
+	}
+}
+
+func Test_parseOptions(t *testing.T) {
+	testCases := []struct {
+		name  string
+		cmd   *command
+		args  []string
+		env   map[string]string
+		check func(opts *options, err error)
+	}{
+		{
+			name: "Defaults",
+			cmd:  playCommand,
+			check: func(opts *options, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "Arasaki", opts.cfg.Username)
+				require.Equal(t, 100, opts.cfg.PlayersStartingMoney)
+				require.Equal(t, 3, opts.cfg.BotsNumber)
+				require.Equal(t, 1, opts.cfg.DecksNumber)
+				require.Equal(t, blackjack.Delay, opts.cfg.Delay)
+				require.Equal(t, outputText, opts.output)
+				require.Equal(t, uiAuto, opts.ui)
+				require.False(t, opts.cfg.Trainer)
+			},
+		},
+		{
+			name: "Trainer",
+			cmd:  playCommand,
+			args: []string{"-trainer"},
+			check: func(opts *options, err error) {
+				require.NoError(t, err)
+				require.True(t, opts.cfg.Trainer)
+			},
+		},
+		{
+			name: "Counting",
+			cmd:  playCommand,
+			args: []string{"-count-quiz", "-decks", "6", "-bot-strategies", "counter"},
+			env:  map[string]string{"BLACKJACK_PENETRATION": "0.8"},
+			check: func(opts *options, err error) {
+				require.NoError(t, err)
+				require.True(t, opts.cfg.CountQuiz)
+				require.Equal(t, counting.HiLo.Name, opts.cfg.CountSystem)
+				require.Equal(t, 0.8, opts.cfg.Penetration)
+				require.Equal(t, blackjack.StrategyCounter, opts.cfg.BotStrategyNames[0])
+			},
+		},
+		{
+			name: "Count System",
+			cmd:  playCommand,
+			args: []string{"-count-quiz", "-count-system", "omega-ii"},
+			check: func(opts *options, err error) {
+				require.NoError(t, err)
+				require.Equal(t, counting.OmegaII.Name, opts.cfg.CountSystem)
+			},
+		},
+		{
+			name: "Unknown Count System",
+			cmd:  playCommand,
+			args: []string{"-count-quiz", "-count-system", "zen"},
+			check: func(opts *options, err error) {
+				require.ErrorIs(t, err, counting.ErrUnknownSystem)
+				require.Nil(t, opts)
+			},
+		},
+		{
+			name: "Hot-Seat",
+			cmd:  playCommand,
+			args: []string{"-seats", "Alex, bot, Sam", "-bot-strategies", "basic"},
+			check: func(opts *options, err error) {
+				require.NoError(t, err)
+				require.Equal(t, []blackjack.SeatConfig{{Name: "Alex"}, {Bot: true}, {Name: "Sam"}}, opts.cfg.Seats)
+			},
+		},
+		{
+			name: "Duplicate Seats",
+			cmd:  playCommand,
+			env:  map[string]string{"BLACKJACK_SEATS": "Alex,Alex"},
+			check: func(opts *options, err error) {
+				require.ErrorIs(t, err, blackjack.ErrDuplicateSeatName)
+			},
+		},
+		{
+			name: "Serve",
+			cmd:  findCommand("serve"),
+			args: []string{"-users", "2", "-bots", "1"},
+			env:  map[string]string{"BLACKJACK_ADDR": ":5000", "BLACKJACK_ALLOW_ORIGINS": "https://example.com"},
+			check: func(opts *options, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 2, opts.users)
+				require.Equal(t, ":5000", opts.addr)
+				require.Equal(t, "main", opts.table)
+				require.Equal(t, "https://example.com", opts.allowOrigins)
+			},
+		},
+		{
+			name: "Client",
+			cmd:  findCommand("client"),
+			args: []string{"-addr", "office:4000", "-table", "vip", "-username", "Sam"},
+			check: func(opts *options, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "office:4000", opts.addr)
+				require.Equal(t, "vip", opts.table)
+				require.Equal(t, "Sam", opts.cfg.Username)
+			},
+		},
+		{
+			name: "Invalid Penetration",
+			cmd:  playCommand,
+			args: []string{"-penetration", "0.95"},
+			check: func(opts *options, err error) {
+				require.ErrorIs(t, err, blackjack.ErrInvalidPenetration)
+			},
+		},
+		{
+			name: "Flags",
+			cmd:  playCommand,
+			args: []string{"-username", "Alex", "-money", "500", "-bots", "5", "-decks", "6", "-h17", "-pays-3to2", "-seed", "42", "-delay", "0", "-output", "jsonl"},
+			check: func(opts *options, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "Alex", opts.cfg.Username)
+				require.Equal(t, 500, opts.cfg.PlayersStartingMoney)
+				require.Equal(t, 5, opts.cfg.BotsNumber)
+				require.Equal(t, 6, opts.cfg.DecksNumber)
+				require.True(t, opts.cfg.Rules.DealerHitsSoft17)
+				require.True(t, opts.cfg.Rules.BlackjackPays3To2)
+				require.Equal(t, int64(42), opts.cfg.Seed)
+				require.True(t, opts.cfg.NoDelay)
+				require.Equal(t, outputJSONL, opts.output)
+			},
+		},
+		{
+			name: "Environment",
+			cmd:  playCommand,
+			env:  map[string]string{"BLACKJACK_USERNAME": "Env", "BLACKJACK_BOTS": "2", "BLACKJACK_H17": "true", "BLACKJACK_DELAY": "500ms"},
+			check: func(opts *options, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "Env", opts.cfg.Username)
+				require.Equal(t, 2, opts.cfg.BotsNumber)
+				require.True(t, opts.cfg.Rules.DealerHitsSoft17)
+				require.Equal(t, 500*time.Millisecond, opts.cfg.Delay)
+			},
+		},
+		{
+			name: "Flag Overrides Environment",
+			cmd:  playCommand,
+			args: []string{"-bots", "4"},
+			env:  map[string]string{"BLACKJACK_BOTS": "2"},
+			check: func(opts *options, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 4, opts.cfg.BotsNumber)
+			},
+		},
+		{
+			name: "Invalid Environment",
+			cmd:  playCommand,
+			env:  map[string]string{"BLACKJACK_MONEY": "much"},
+			check: func(opts *options, err error) {
+				require.Error(t, err)
+				require.Nil(t, opts)
+			},
+		},
+		{
+			name: "Preset",
+			cmd:  playCommand,
+			args: []string{"-config", "../../configs/presets.yaml", "-preset", "european"},
+			check: func(opts *options, err error) {
+				require.NoError(t, err)
+				require.True(t, opts.cfg.Rules.NoHoleCard)
+				require.Equal(t, 6, opts.cfg.DecksNumber)
+				require.Equal(t, 200, opts.cfg.PlayersStartingMoney)
+			},
+		},
+		{
+			name: "Flags Override Preset",
+			cmd:  playCommand,
+			args: []string{"-config", "../../configs/presets.yaml", "-preset", "european", "-decks", "2", "-delay", "0"},
+			env:  map[string]string{"BLACKJACK_MONEY": "50"},
+			check: func(opts *options, err error) {
+				require.NoError(t, err)
+				require.True(t, opts.cfg.Rules.NoHoleCard)
+				require.Equal(t, 2, opts.cfg.DecksNumber)
+				require.Equal(t, 50, opts.cfg.PlayersStartingMoney)
+				require.True(t, opts.cfg.NoDelay)
+			},
+		},
+		{
+			name: "Unknown Preset",
+			cmd:  playCommand,
+			args: []string{"-config", "../../configs/presets.yaml", "-preset", "monte-carlo"},
+			check: func(opts *options, err error) {
+				require.Error(t, err)
+				require.Nil(t, opts)
+			},
+		},
+		{
+			name: "Bot Strategies",
+			cmd:  playCommand,
+			args: []string{"-bots", "3", "-bot-strategies", "basic, random"},
+			check: func(opts *options, err error) {
+				require.NoError(t, err)
+				require.Len(t, opts.cfg.BotStrategyNames, 2)
+				require.Equal(t, blackjack.StrategyBasic, opts.cfg.BotStrategyNames[0])
+				require.Equal(t, blackjack.StrategyRandom, opts.cfg.BotStrategyNames[1])
+			},
+		},
+		{
+			name: "Preset Bot Strategies",
+			cmd:  playCommand,
+			args: []string{"-config", "../../configs/presets.yaml", "-preset", "single-deck"},
+			check: func(opts *options, err error) {
+				require.NoError(t, err)
+				require.Len(t, opts.cfg.BotStrategyNames, 1)
+				require.Equal(t, blackjack.StrategyBasic, opts.cfg.BotStrategyNames[0])
+			},
+		},
+		{
+			name: "Unknown Bot Strategy",
+			cmd:  playCommand,
+			args: []string{"-bot-strategies", "psychic"},
+			check: func(opts *options, err error) {
+				require.ErrorIs(t, err, blackjack.ErrUnknownStrategy)
+				require.Nil(t, opts)
+			},
+		},
+		{
+			name: "Invalid Config",
+			cmd:  playCommand,
+			args: []string{"-bots", "0"},
+			check: func(opts *options, err error) {
+				require.ErrorIs(t, err, blackjack.ErrBotsNumberLessThan)
+				require.Nil(t, opts)
+			},
+		},
+		{
+			name: "Simulate Without Username",
+			cmd:  findCommand("simulate"),
+			args: []string{"-username", "", "-rounds", "10"},
+			check: func(opts *options, err error) {
+				require.NoError(t, err)
+				require.True(t, opts.cfg.BotsOnly)
+				require.Equal(t, 10, opts.rounds)
+			},
+		},
+		{
+			name: "Replay File",
+			cmd:  findCommand("replay"),
+			args: []string{"-hand", "2", "hands.txt"},
+			check: func(opts *options, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 2, opts.handNumber)
+				require.Equal(t, []string{"hands.txt"}, opts.args)
+			},
+		},
+		{
+			name: "Replay Without File",
+			cmd:  findCommand("replay"),
+			check: func(opts *options, err error) {
+				require.Error(t, err)
+				require.Nil(t, opts)
+			},
+		},
+		{
+			name: "Stats Files",
+			cmd:  findCommand("stats"),
+			args: []string{"a.txt", "b.txt"},
+			check: func(opts *options, err error) {
+				require.NoError(t, err)
+				require.Equal(t, []string{"a.txt", "b.txt"}, opts.args)
+			},
+		},
+		{
+			name: "Unknown Output",
+			cmd:  playCommand,
+			args: []string{"-output", "xml"},
+			check: func(opts *options, err error) {
+				require.Error(t, err)
+				require.Nil(t, opts)
+			},
+		},
+		{
+			name: "Card Style",
+			cmd:  findCommand("replay"),
+			args: []string{"-cards", "ascii", "hands.txt"},
+			check: func(opts *options, err error) {
+				require.NoError(t, err)
+				require.Equal(t, deck.StyleASCII, opts.cfg.CardStyle)
+			},
+		},
+		{
+			name: "Unknown Card Style",
+			cmd:  playCommand,
+			args: []string{"-cards", "braille"},
+			check: func(opts *options, err error) {
+				require.ErrorIs(t, err, deck.ErrInvalidStyle)
+				require.Nil(t, opts)
+			},
+		},
+		{
+			name: "Unknown UI",
+			cmd:  playCommand,
+			args: []string{"-ui", "3d"},
+			check: func(opts *options, err error) {
+				require.Error(t, err)
+				require.Nil(t, opts)
+			},
+		},
+		{
+			name: "Unexpected Argument",
+			cmd:  playCommand,
+			args: []string{"play"},
+			check: func(opts *options, err error) {
+				require.Error(t, err)
+				require.Nil(t, opts)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			getenv := func(name string) string {
+				return tc.env[name]
+			}
+
+			opts, err := parseOptions(tc.cmd, tc.args, getenv, io.Discard)
+			tc.check(opts, err)
+		})
+	}
+}
+
+func Test_run(t *testing.T) {
+	historyFile := filepath.Join(t.TempDir(), "hands.txt")
+
+	testCases := []struct {
+		name  string
+		args  []string
+		check func(code int, stdout string, stderr string)
+	}{
+		{
+			name: "Help",
+			args: []string{"help"},
+			check: func(code int, stdout string, stderr string) {
+				require.Equal(t, 0, code)
+				for _, cmd := range commands {
+					require.Contains(t, stdout, cmd.name)
+				}
+			},
+		},
+		{
+			name: "Command Help",
+			args: []string{"simulate", "-h"},
+			check: func(code int, stdout string, stderr string) {
+				require.Equal(t, 0, code)
+				require.Contains(t, stderr, "Usage: blackjack simulate")
+				require.Contains(t, stderr, "-rounds")
+			},
+		},
+		{
+			name: "Unknown Command",
+			args: []string{"fly"},
+			check: func(code int, stdout string, stderr string) {
+				require.Equal(t, 2, code)
+				require.Contains(t, stderr, `unknown command "fly"`)
+			},
+		},
+		{
+			name: "Simulate",
+			args: []string{"simulate", "-rounds", "25", "-bots", "2", "-seed", "7", "-history", historyFile},
+			check: func(code int, stdout string, stderr string) {
+				require.Equal(t, 0, code)
+				require.Contains(t, stdout, "Rounds: 25")
+				require.Equal(t, 2, strings.Count(stdout, "[bot]"))
+			},
+		},
+		{
+			name: "Simulate Strategies",
+			args: []string{"simulate", "-rounds", "1000", "-seed", "7", "-strategies", "naive,basic", "-pays-3to2"},
+			check: func(code int, stdout string, stderr string) {
+				require.Equal(t, 0, code)
+				require.Contains(t, stdout, "95% CI")
+				require.Contains(t, stdout, "naive ")
+				require.Contains(t, stdout, "basic ")
+			},
+		},
+		{
+			name: "Simulate Strategies With Penetration",
+			args: []string{"simulate", "-rounds", "1000", "-seed", "7", "-strategies", "basic", "-penetration", "0.75"},
+			check: func(code int, stdout string, stderr string) {
+				require.Equal(t, 0, code)
+
+				// The same seed with a new shoe every round gives other results
+				var newShoes bytes.Buffer
+				args := []string{"simulate", "-rounds", "1000", "-seed", "7", "-strategies", "basic"}
+				require.Equal(t, 0, run(args, func(string) string { return "" }, &newShoes, io.Discard))
+				require.NotEqual(t, newShoes.String(), stdout)
+			},
+		},
+		{
+			name: "Unknown Strategy",
+			args: []string{"simulate", "-strategies", "psychic"},
+			check: func(code int, stdout string, stderr string) {
+				require.Equal(t, 1, code)
+				require.Contains(t, stderr, "unknown strategy")
+			},
+		},
+		{
+			name: "Stats",
+			args: []string{"stats", historyFile, historyFile},
+			check: func(code int, stdout string, stderr string) {
+				require.Equal(t, 0, code)
+				require.Contains(t, stdout, "Session "+historyFile)
+				require.Contains(t, stdout, "Profile of 2 sessions")
+				require.Contains(t, stdout, "Rounds: 50")
+			},
+		},
+		{
+			name: "Stats Without File",
+			args: []string{"stats", filepath.Join(t.TempDir(), "none.txt")},
+			check: func(code int, stdout string, stderr string) {
+				require.Equal(t, 1, code)
+				require.Contains(t, stderr, "error when running the stats command")
+			},
+		},
+		{
+			name: "Play Without Scenario",
+			args: []string{"play", "-ui", "plain", "-scenario", filepath.Join(t.TempDir(), "none.txt")},
+			check: func(code int, stdout string, stderr string) {
+				require.Equal(t, 1, code)
+				require.Contains(t, stderr, "error when running the play command")
+				require.Contains(t, stderr, "none.txt")
+			},
+		},
+		{
+			name: "Play Without Save",
+			args: []string{"play", "-ui", "plain", "-load", filepath.Join(t.TempDir(), "none.save")},
+			check: func(code int, stdout string, stderr string) {
+				require.Equal(t, 1, code)
+				require.Contains(t, stderr, "error when running the play command: error when loading the game")
+				require.Contains(t, stderr, "none.save")
+			},
+		},
+		{
+			name: "Deck Summary",
+			args: []string{"deck", "-decks", "2", "-summary"},
+			check: func(code int, stdout string, stderr string) {
+				require.Equal(t, 0, code)
+				require.Contains(t, stdout, "Decks: 2, cards: 104")
+				require.Contains(t, stdout, "ace    8")
+				require.Contains(t, stdout, "10     8")
+			},
+		},
+		{
+			name: "Deck Cards",
+			args: []string{"deck", "-seed", "3"},
+			check: func(code int, stdout string, stderr string) {
+				require.Equal(t, 0, code)
+				require.Equal(t, 53, strings.Count(stdout, "\n"))
+			},
+		},
+	}
+
+	// The cases are run in order, the stats are collected from the simulated history
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			getenv := func(name string) string {
+				return ""
+			}
+
+			var stdout, stderr bytes.Buffer
+			code := run(tc.args, getenv, &stdout, &stderr)
+			tc.check(code, stdout.String(), stderr.String())
+		})
+	}
+}
+
+// Input lines of the user, ActionExit when the lines are over
+type scriptInput struct {
+	lines []string
+}
+
+func (s *scriptInput) Input() string {
+	if len(s.lines) == 0 {
+		return string(blackjack.ActionExit)
+	}
+
+	line := s.lines[0]
+	s.lines = s.lines[1:]
+
+	return line
+}
+
+func Test_playRemote(t *testing.T) {
+	s, err := server.New(server.TableConfig{
+		Name:  "main",
+		Users: 1,
+		Game: blackjack.Config{
+			PlayersStartingMoney: 100,
+			BotsNumber:           1,
+			NoDelay:              true,
+		},
+	})
+	require.NoError(t, err)
+	defer s.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go s.Serve(listener)
+
+	c, err := server.Dial(listener.Addr().String())
+	require.NoError(t, err)
+	defer c.Close()
+	require.NoError(t, c.Join("main", "Alex"))
+
+	var out bytes.Buffer
+	// An invalid bet is asked again, the user stands and leaves on the next bet
+	input := &scriptInput{lines: []string{"1000", "10", string(blackjack.ActionPass)}}
+
+	require.NoError(t, playRemote(c, input, deck.StyleLong, &out))
+	require.Empty(t, input.lines)
+
+	text := out.String()
+	require.Contains(t, text, "You have taken the seat 1 at the table \"main\"")
+	require.Contains(t, text, "Incorrect input")
+	require.Contains(t, text, "Alex bets 10")
+	require.Contains(t, text, "Alex stands on")
+	require.Contains(t, text, "dealer gets")
+	require.Contains(t, text, " of ")
+	require.Contains(t, text, "Alex: ")
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"course/internal/blackjack"
+	"course/internal/console"
+	"course/internal/deck"
+	"course/internal/server"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+)
+
+// Flags of the table server address, shared by the server and the client
+func addNetworkFlags(fs *flag.FlagSet, opts *options, env *envSource) {
+	fs.StringVar(&opts.addr, "addr", env.getString("BLACKJACK_ADDR", "localhost:4000"), "TCP address of the table server (BLACKJACK_ADDR)")
+	fs.StringVar(&opts.table, "table", env.getString("BLACKJACK_TABLE", "main"), "name of the table (BLACKJACK_TABLE)")
+}
+
+// Serve the table of the config until the process is interrupted
+func runServe(opts *options, stdout io.Writer, stderr io.Writer) error {
+	cfg := opts.cfg
+	cfg.HandHistoryFile = opts.historyFile
+
+	s, err := server.New(server.TableConfig{
+		Name:  opts.table,
+		Users: opts.users,
+		Game:  cfg,
+	})
+	if err != nil {
+		return err
+	}
+
+	if opts.allowOrigins != "" {
+		for _, origin := range strings.Split(opts.allowOrigins, ",") {
+			s.AllowOrigins(strings.TrimSpace(origin))
+		}
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	var httpServer *http.Server
+
+	if opts.httpAddr != "" {
+		httpServer = &http.Server{Addr: opts.httpAddr, Handler: s.HTTPHandler()}
+
+		go func() {
+			if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				fmt.Fprintf(stderr, "HTTP server: %v\n", err)
+			}
+		}()
+
+		fmt.Fprintf(stdout, "Serving the HTTP API on %s\n", opts.httpAddr)
+	}
+
+	go func() {
+		<-interrupt
+		if httpServer != nil {
+			httpServer.Close()
+		}
+		s.Close()
+	}()
+
+	fmt.Fprintf(stdout, "Serving the table %q with %d player seats on %s\n", opts.table, opts.users, opts.addr)
+
+	return s.ListenAndServe(opts.addr)
+}
+
+func runClient(opts *options, stdout io.Writer, stderr io.Writer) error {
+	c, err := server.Dial(opts.addr)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	join := c.Join
+	if opts.wait {
+		join = c.Wait
+	}
+
+	if err = join(opts.table, opts.cfg.Username); err != nil {
+		return err
+	}
+
+	cnsl, err := console.NewConsole()
+	if err != nil {
+		return err
+	}
+
+	return playRemote(c, cnsl, opts.cfg.CardStyle, stdout)
+}
+
+// Print the messages of the server and answer the prompts with the user input until the game is over
+func playRemote(c *server.Client, input blackjack.Input, style deck.Style, out io.Writer) error {
+	for {
+		message, err := c.Receive()
+		if errors.Is(err, io.EOF) {
+			return errors.New("the server has closed the connection")
+		}
+		if err != nil {
+			return err
+		}
+
+		switch message.Type {
+		case server.MessageJoined:
+			fmt.Fprintf(out, "You have taken the seat %d at the table %q. The game starts when all the seats are taken\n", message.Seat, message.Table)
+
+		case server.MessageWaiting:
+			fmt.Fprintf(out, "The table %q is full, you are number %d in the queue for a seat\n", message.Table, message.Position)
+
+		case server.MessageError:
+			fmt.Fprintf(out, "Error: %s\n", message.Error)
+
+		case server.MessageClosed:
+			fmt.Fprintln(out, "\nThe game is over")
+			return nil
+
+		case server.MessageEvent:
+			event := message.Event
+			if event.Type != blackjack.EventPrompt {
+				printRemoteEvent(out, style, event)
+				continue
+			}
+
+			left, err := answerPrompt(c, input, out, event)
+			if err != nil || left {
+				return err
+			}
+		}
+	}
+}
+
+// Ask the user for the answer to the prompt, true if he has left the table
+func answerPrompt(c *server.Client, input blackjack.Input, out io.Writer, event *blackjack.Event) (bool, error) {
+	for {
+		if event.Prompt == blackjack.PromptBet {
+			fmt.Fprintf(out, "\nMake your bet (you have %d c.). %s - Exit.\n>> ", event.Money, blackjack.ActionExit)
+		} else {
+			fmt.Fprintf(out, "\nPoints: %d. %s - Take card. %s - Save. %s - Exit.\n>> ", event.Points, blackjack.ActionTakeCard, blackjack.ActionPass, blackjack.ActionExit)
+		}
+
+		userInput := input.Input()
+
+		switch {
+		case userInput == string(blackjack.ActionExit):
+			return true, c.Leave()
+
+		case event.Prompt == blackjack.PromptBet:
+			bet, err := strconv.Atoi(userInput)
+			if err != nil || bet <= 0 || bet > event.Money {
+				fmt.Fprintln(out, "Incorrect input")
+				continue
+			}
+			return false, c.Bet(bet)
+
+		case userInput == string(blackjack.ActionTakeCard):
+			return false, c.Move(blackjack.MoveHit)
+
+		case userInput == string(blackjack.ActionPass):
+			return false, c.Move(blackjack.MoveStand)
+
+		default:
+			fmt.Fprintln(out, "Incorrect input")
+		}
+	}
+}
+
+func printRemoteEvent(out io.Writer, style deck.Style, event *blackjack.Event) {
+	switch event.Type {
+	case blackjack.EventRoundStart:
+		fmt.Fprintf(out, "\n--- Round %d ---\n", event.Round)
+	case blackjack.EventShuffle:
+		fmt.Fprintf(out, "The shoe of %d decks is shuffled\n", event.Decks)
+	case blackjack.EventBet:
+		fmt.Fprintf(out, "%s bets %d\n", event.Name, event.Bet)
+	case blackjack.EventDeal, blackjack.EventHit, blackjack.EventDealerHit:
+		fmt.Fprintf(out, "%s gets %s (%d points)\n", event.Name, formatRemoteCards(event.Cards, style), event.Points)
+	case blackjack.EventStand, blackjack.EventDealerStand:
+		fmt.Fprintf(out, "%s stands on %d\n", event.Name, event.Points)
+	case blackjack.EventDealerReveal:
+		// Without the hole card the dealer has nothing to reveal
+		if len(event.Cards) == 0 {
+			return
+		}
+		fmt.Fprintf(out, "%s reveals %s (%d points)\n", event.Name, formatRemoteCards(event.Cards, style), event.Points)
+	case blackjack.EventSettlement:
+		fmt.Fprintf(out, "%s: %s, %d c. paid, %d c. left\n", event.Name, event.Result, event.Payout, event.Money)
+	}
+}
+
+func formatRemoteCards(cards []*deck.Card, style deck.Style) string {
+	names := make([]string, 0, len(cards))
+	for _, card := range cards {
+		names = append(names, card.Format(style))
+	}
+
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"course/internal/blackjack"
+	"course/internal/console"
+	"course/internal/deck"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Commands of the replay mode
+const (
+	replayNext = "n"
+	replayBack = "b"
+	replayExit = "q"
+)
+
+// Step through the hand with the given number from the hand history file.
+// The first hand is replayed if the number is 0.
+func runReplay(path string, handNumber int, style deck.Style, out io.Writer) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	hands, err := blackjack.ParseHandHistory(file)
+	if err != nil {
+		return err
+	}
+
+	hand, err := findHand(hands, handNumber)
+	if err != nil {
+		return err
+	}
+
+	if err = blackjack.VerifyHand(hand); err != nil {
+		fmt.Fprintf(out, "Engine check failed: %v\n", err)
+	} else {
+		fmt.Fprintf(out, "Engine check passed: the recorded outcome is reproduced\n")
+	}
+
+	cnsl, err := console.NewConsole()
+	if err != nil {
+		return err
+	}
+
+	replay := blackjack.NewReplay(hand)
+	replay.SetCardStyle(style)
+
+	for {
+		if err = replay.Render(out); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(out, "\n%s or Enter - Next. %s - Back. %s - exit.\n>> ", replayNext, replayBack, replayExit)
+
+		input := cnsl.Input()
+		// The replay ends with the input
+		if input == "" && cnsl.EOF() {
+			return nil
+		}
+
+		switch input {
+		case replayNext, "":
+			if !replay.Next() {
+				fmt.Fprintln(out, "\nThe end of the hand")
+			}
+		case replayBack:
+			if !replay.Prev() {
+				fmt.Fprintln(out, "\nThe start of the hand")
+			}
+		case replayExit:
+			return nil
+		default:
+			fmt.Fprintln(out, "Incorrect input")
+		}
+	}
+}
+
+func findHand(hands []*blackjack.RecordedHand, handNumber int) (*blackjack.RecordedHand, error) {
+	if len(hands) == 0 {
+		return nil, fmt.Errorf("no hands in the history")
+	}
+
+	if handNumber == 0 {
+		return hands[0], nil
+	}
+
+	for _, hand := range hands {
+		if hand.Number == handNumber {
+			return hand, nil
+		}
+	}
+
+	return nil, fmt.Errorf("hand #%d is not found", handNumber)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"course/internal/blackjack"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Print the results of the players for every hand history file as a session report,
+// and for all the files together as a profile report.
+func runStats(opts *options, stdout io.Writer, stderr io.Writer) error {
+	profile := blackjack.NewStats()
+
+	for _, path := range opts.args {
+		session := blackjack.NewStats()
+
+		if err := collectHistoryStats(path, session, profile); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		fmt.Fprintf(stdout, "Session %s\n", path)
+		if err := session.Report(stdout); err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout)
+	}
+
+	if len(opts.args) == 1 {
+		return nil
+	}
+
+	fmt.Fprintf(stdout, "Profile of %d sessions\n", len(opts.args))
+	return profile.Report(stdout)
+}
+
+func collectHistoryStats(path string, observers ...blackjack.Observer) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	hands, err := blackjack.ParseHandHistory(file)
+	if err != nil {
+		return err
+	}
+
+	for _, hand := range hands {
+		for _, event := range hand.Events {
+			for _, observer := range observers {
+				observer.OnEvent(event)
+			}
+		}
+	}
+
+	return nil
+}
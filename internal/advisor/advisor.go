@@ -0,0 +1,214 @@
+/*
+  This package computes the basic strategy of blackjack for the table rules.
+*/
+
+package advisor
+
+import (
+	"course/internal/deck"
+	"course/internal/odds"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+type Move string
+
+// Moves of a player on his turn
+const (
+	MoveHit       Move = "hit"
+	MoveStand     Move = "stand"
+	MoveDouble    Move = "double"
+	MoveSplit     Move = "split"
+	MoveSurrender Move = "surrender"
+)
+
+const maxPoints = 21
+
+var (
+	ErrInvalidDecksNumber = errors.New("decks number less than 1")
+	ErrInvalidCard        = odds.ErrInvalidCard
+	ErrNoLegalMoves       = errors.New("no legal moves")
+)
+
+// Rules
+// Table rules that change the basic strategy.
+type Rules struct {
+	DecksNumber int
+	// The dealer takes a card on soft 17
+	DealerHitsSoft17 bool
+	// A hand can be doubled after a split
+	DoubleAfterSplit bool
+	// Late surrender of the starting hand
+	Surrender bool
+	// The dealer checks the hole card for a blackjack before the players play.
+	// Without the peek the doubled and split bets are lost to a dealer blackjack
+	DealerPeeks bool
+}
+
+// Advice
+// The best legal move for a hand.
+type Advice struct {
+	Move Move
+	// Expected values of the legal moves, in bets
+	EV map[Move]float64
+	// Explanation of the move
+	Reason string
+}
+
+// Advisor
+// Gives the basic strategy moves from the charts computed for the rules.
+type Advisor struct {
+	chart *Chart
+}
+
+var (
+	cacheMutex sync.Mutex
+	cache      = make(map[Rules]*Advisor)
+)
+
+// New
+// Compute the charts for the rules. The advisors are cached by the rules, so the charts are computed once.
+func New(rules Rules) (*Advisor, error) {
+	if rules.DecksNumber < 1 {
+		return nil, ErrInvalidDecksNumber
+	}
+
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+
+	if advisor, ok := cache[rules]; ok {
+		return advisor, nil
+	}
+
+	chart, err := computeChart(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	advisor := &Advisor{
+		chart: chart,
+	}
+	cache[rules] = advisor
+
+	return advisor, nil
+}
+
+// Chart
+// Charts of the rules of the advisor.
+func (a *Advisor) Chart() *Chart {
+	return a.chart
+}
+
+// Advise
+// The best of the legal moves for the hand against the opened dealer card.
+func (a *Advisor) Advise(cards []*deck.Card, dealerUpCard *deck.Card, legalMoves []Move) (Advice, error) {
+	if len(legalMoves) == 0 {
+		return Advice{}, ErrNoLegalMoves
+	}
+
+	values := make([]int, 0, len(cards))
+	for _, card := range cards {
+		value, err := odds.CardValue(card)
+		if err != nil {
+			return Advice{}, err
+		}
+		values = append(values, value)
+	}
+
+	upValue, err := odds.CardValue(dealerUpCard)
+	if err != nil {
+		return Advice{}, err
+	}
+
+	cell, name := a.chart.find(values, upValue, isLegal(legalMoves, MoveSplit))
+
+	advice := Advice{
+		EV: make(map[Move]float64),
+	}
+
+	for _, move := range legalMoves {
+		ev, ok := cell.EV[move]
+		if !ok {
+			continue
+		}
+
+		advice.EV[move] = ev
+		if advice.Move == "" || ev > advice.EV[advice.Move] {
+			advice.Move = move
+		}
+	}
+
+	if advice.Move == "" {
+		// The chart has no value for the legal moves, for example a busted hand
+		advice.Move = legalMoves[0]
+		if isLegal(legalMoves, MoveStand) {
+			advice.Move = MoveStand
+		}
+		advice.Reason = fmt.Sprintf("%s against %s: no better move", name, upCardName(upValue))
+
+		return advice, nil
+	}
+
+	advice.Reason = formatReason(name, upValue, advice)
+
+	return advice, nil
+}
+
+// Explanation like "hard 16 against 10: hit (EV -0.54) is better than stand (EV -0.57)"
+func formatReason(name string, upValue int, advice Advice) string {
+	reason := fmt.Sprintf("%s against %s: %s (EV %+.2f)", name, upCardName(upValue), advice.Move, advice.EV[advice.Move])
+
+	others := make([]Move, 0, len(advice.EV))
+	for move := range advice.EV {
+		if move != advice.Move {
+			others = append(others, move)
+		}
+	}
+
+	if len(others) == 0 {
+		return reason + " is the only move"
+	}
+
+	sort.Slice(others, func(i, j int) bool {
+		return advice.EV[others[i]] > advice.EV[others[j]]
+	})
+
+	return reason + fmt.Sprintf(" is better than %s (EV %+.2f)", others[0], advice.EV[others[0]])
+}
+
+func isLegal(legalMoves []Move, move Move) bool {
+	for _, legalMove := range legalMoves {
+		if legalMove == move {
+			return true
+		}
+	}
+
+	return false
+}
+
+func upCardName(value int) string {
+	if value == 1 {
+		return "ace"
+	}
+
+	return strconv.Itoa(value)
+}
+
+// Points of the card values and whether an ace is counted as 11
+func handPoints(values ...int) (int, bool) {
+	points, ace := 0, false
+
+	for _, value := range values {
+		points += value
+		ace = ace || value == 1
+	}
+
+	if ace && points+10 <= maxPoints {
+		return points + 10, true
+	}
+
+	return points, false
+}
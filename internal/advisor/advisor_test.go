@@ -0,0 +1,250 @@
+package advisor
+
+import (
+	"bytes"
+	"course/internal/deck"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+var allMoves = []Move{MoveHit, MoveStand, MoveDouble, MoveSplit, MoveSurrender}
+
+func getTestCards(values ...deck.Rank) []*deck.Card {
+	cards := make([]*deck.Card, 0, len(values))
+
+	for _, value := range values {
+		// The joker is the only card of the trump cards
+		suit := deck.Spade
+		if value == deck.Joker {
+			suit = deck.TrumpCard
+		}
+		cards = append(cards, deck.MustNewCard(suit, value))
+	}
+
+	return cards
+}
+
+func TestAdvisor_Advise(t *testing.T) {
+	shoe := Rules{DecksNumber: 6, DoubleAfterSplit: true, Surrender: true, DealerPeeks: true}
+	singleDeck := Rules{DecksNumber: 1, DealerHitsSoft17: true, DealerPeeks: true}
+	noPeek := Rules{DecksNumber: 6, DoubleAfterSplit: true}
+
+	testCases := []struct {
+		name   string
+		rules  Rules
+		hand   []deck.Rank
+		dealer deck.Rank
+		moves  []Move
+		check  func(advice Advice, err error)
+	}{
+		{
+			name:   "Stand 12 Against 4",
+			rules:  shoe,
+			hand:   []deck.Rank{deck.King, deck.Two},
+			dealer: deck.Four,
+			moves:  allMoves,
+			check: func(advice Advice, err error) {
+				require.NoError(t, err)
+				require.Equal(t, MoveStand, advice.Move)
+				require.Contains(t, advice.Reason, "hard 12 against 4: stand")
+			},
+		},
+		{
+			name:   "Surrender 16 Against Ten",
+			rules:  shoe,
+			hand:   []deck.Rank{deck.King, deck.Six},
+			dealer: deck.Queen,
+			moves:  allMoves,
+			check: func(advice Advice, err error) {
+				require.NoError(t, err)
+				require.Equal(t, MoveSurrender, advice.Move)
+				require.Equal(t, -0.5, advice.EV[MoveSurrender])
+			},
+		},
+		{
+			name:   "Hit 16 Against Ten Without Surrender",
+			rules:  shoe,
+			hand:   []deck.Rank{deck.King, deck.Six},
+			dealer: deck.Queen,
+			moves:  []Move{MoveHit, MoveStand},
+			check: func(advice Advice, err error) {
+				require.NoError(t, err)
+				require.Equal(t, MoveHit, advice.Move)
+				require.Len(t, advice.EV, 2)
+			},
+		},
+		{
+			name:   "Hit 11 Against Ace In The Shoe",
+			rules:  shoe,
+			hand:   []deck.Rank{deck.Five, deck.Six},
+			dealer: deck.Ace,
+			moves:  allMoves,
+			check: func(advice Advice, err error) {
+				require.NoError(t, err)
+				require.Equal(t, MoveHit, advice.Move)
+			},
+		},
+		{
+			name:   "Double 11 Against Ace In Single Deck",
+			rules:  singleDeck,
+			hand:   []deck.Rank{deck.Five, deck.Six},
+			dealer: deck.Ace,
+			moves:  allMoves,
+			check: func(advice Advice, err error) {
+				require.NoError(t, err)
+				require.Equal(t, MoveDouble, advice.Move)
+			},
+		},
+		{
+			name:   "Soft 19 Against 6 With H17",
+			rules:  singleDeck,
+			hand:   []deck.Rank{deck.Ace, deck.Eight},
+			dealer: deck.Six,
+			moves:  allMoves,
+			check: func(advice Advice, err error) {
+				require.NoError(t, err)
+				require.Equal(t, MoveDouble, advice.Move)
+			},
+		},
+		{
+			name:   "Soft 19 Against 6 With S17",
+			rules:  shoe,
+			hand:   []deck.Rank{deck.Ace, deck.Eight},
+			dealer: deck.Six,
+			moves:  allMoves,
+			check: func(advice Advice, err error) {
+				require.NoError(t, err)
+				require.Equal(t, MoveStand, advice.Move)
+			},
+		},
+		{
+			name:   "Split Eights Against Ten",
+			rules:  shoe,
+			hand:   []deck.Rank{deck.Eight, deck.Eight},
+			dealer: deck.Ten,
+			moves:  allMoves,
+			check: func(advice Advice, err error) {
+				require.NoError(t, err)
+				require.Equal(t, MoveSplit, advice.Move)
+				require.Contains(t, advice.Reason, "pair of 8s")
+			},
+		},
+		{
+			name:   "Hit Eights Against Ten Without Peek",
+			rules:  noPeek,
+			hand:   []deck.Rank{deck.Eight, deck.Eight},
+			dealer: deck.Ten,
+			moves:  allMoves,
+			check: func(advice Advice, err error) {
+				require.NoError(t, err)
+				require.Equal(t, MoveHit, advice.Move)
+			},
+		},
+		{
+			name:   "Pair Without Split",
+			rules:  shoe,
+			hand:   []deck.Rank{deck.Eight, deck.Eight},
+			dealer: deck.Six,
+			moves:  []Move{MoveHit, MoveStand},
+			check: func(advice Advice, err error) {
+				require.NoError(t, err)
+				require.Equal(t, MoveStand, advice.Move)
+				require.Contains(t, advice.Reason, "hard 16")
+			},
+		},
+		{
+			name:   "Three Cards",
+			rules:  shoe,
+			hand:   []deck.Rank{deck.Two, deck.Three, deck.Ace},
+			dealer: deck.Nine,
+			moves:  []Move{MoveHit, MoveStand},
+			check: func(advice Advice, err error) {
+				require.NoError(t, err)
+				require.Equal(t, MoveHit, advice.Move)
+				require.Contains(t, advice.Reason, "soft 16")
+			},
+		},
+		{
+			name:   "Hard 21",
+			rules:  shoe,
+			hand:   []deck.Rank{deck.Seven, deck.Seven, deck.Seven},
+			dealer: deck.Ace,
+			moves:  []Move{MoveHit, MoveStand},
+			check: func(advice Advice, err error) {
+				require.NoError(t, err)
+				require.Equal(t, MoveStand, advice.Move)
+			},
+		},
+		{
+			name:   "Busted",
+			rules:  shoe,
+			hand:   []deck.Rank{deck.King, deck.Queen, deck.Five},
+			dealer: deck.Five,
+			moves:  []Move{MoveHit, MoveStand},
+			check: func(advice Advice, err error) {
+				require.NoError(t, err)
+				require.Equal(t, MoveStand, advice.Move)
+			},
+		},
+		{
+			name:   "Invalid Card",
+			rules:  shoe,
+			hand:   []deck.Rank{deck.Joker, deck.Five},
+			dealer: deck.Five,
+			moves:  []Move{MoveHit, MoveStand},
+			check: func(advice Advice, err error) {
+				require.ErrorIs(t, err, ErrInvalidCard)
+			},
+		},
+		{
+			name:   "No Legal Moves",
+			rules:  shoe,
+			hand:   []deck.Rank{deck.Five, deck.Five},
+			dealer: deck.Five,
+			check: func(advice Advice, err error) {
+				require.ErrorIs(t, err, ErrNoLegalMoves)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			a, err := New(tc.rules)
+			require.NoError(t, err)
+
+			dealerUpCard := deck.MustNewCard(deck.Heart, tc.dealer)
+			tc.check(a.Advise(getTestCards(tc.hand...), dealerUpCard, tc.moves))
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	rules := Rules{DecksNumber: 2, DealerPeeks: true}
+
+	a, err := New(rules)
+	require.NoError(t, err)
+	require.Equal(t, rules, a.Chart().Rules)
+
+	cached, err := New(rules)
+	require.NoError(t, err)
+	require.Same(t, a, cached)
+
+	_, err = New(Rules{})
+	require.ErrorIs(t, err, ErrInvalidDecksNumber)
+}
+
+func TestChart_Write(t *testing.T) {
+	a, err := New(Rules{DecksNumber: 6, DoubleAfterSplit: true, Surrender: true, DealerPeeks: true})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, a.Chart().Write(&buf))
+
+	require.Contains(t, buf.String(), "Hard    2  3  4  5  6  7  8  9 10  A\n")
+	require.Contains(t, buf.String(), "11     Dh Dh Dh Dh Dh Dh Dh Dh Dh  H\n")
+	require.Contains(t, buf.String(), "16      S  S  S  S  S  H  H Rh Rh Rh\n")
+	require.Contains(t, buf.String(), "18      S Ds Ds Ds Ds  S  S  H  H  H\n")
+	require.Contains(t, buf.String(), "A,A     P  P  P  P  P  P  P  P  P  P\n")
+}
@@ -0,0 +1,299 @@
+package advisor
+
+import (
+	"course/internal/odds"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Rows of the charts
+const (
+	firstHardRow = 4
+	firstSoftRow = 12
+	lastRow      = maxPoints
+	// Pairs of aces are the row 11
+	aceRow = 11
+)
+
+// Cell
+// Moves of a hand against a dealer card.
+type Cell struct {
+	// The best move when all the moves of the rules are allowed
+	Move Move
+	// Expected values of the moves, in bets. Double and surrender are the values of the starting hand
+	EV map[Move]float64
+}
+
+// Chart
+// Basic strategy charts. Rows are the points of the hand, or the card of a pair,
+// the columns are the opened dealer card from 2 to ace.
+type Chart struct {
+	Rules Rules
+	Hard  map[int][]Cell
+	Soft  map[int][]Cell
+	// Rows from 2 to 10 and 11 for the aces
+	Pairs map[int][]Cell
+}
+
+// Column of the dealer card value, the ace is the last column
+func column(upValue int) int {
+	if upValue == 1 {
+		return odds.CardValues - 1
+	}
+
+	return upValue - 2
+}
+
+func computeChart(rules Rules) (*Chart, error) {
+	chart := &Chart{
+		Rules: rules,
+		Hard:  make(map[int][]Cell),
+		Soft:  make(map[int][]Cell),
+		Pairs: make(map[int][]Cell),
+	}
+
+	c := &chartComputer{
+		rules:    rules,
+		fullShoe: odds.NewShoe(rules.DecksNumber),
+		dealer:   newDealerCalculator(rules),
+	}
+
+	for points := firstHardRow; points <= lastRow; points++ {
+		row, err := c.row(hardCombos(points), false)
+		if err != nil {
+			return nil, err
+		}
+		chart.Hard[points] = row
+	}
+
+	for points := firstSoftRow; points <= lastRow; points++ {
+		row, err := c.row([][2]int{{1, points - 11}}, false)
+		if err != nil {
+			return nil, err
+		}
+		chart.Soft[points] = row
+	}
+
+	for value := 2; value <= aceRow; value++ {
+		cardValue := value
+		if value == aceRow {
+			cardValue = 1
+		}
+
+		row, err := c.row([][2]int{{cardValue, cardValue}}, true)
+		if err != nil {
+			return nil, err
+		}
+		chart.Pairs[value] = row
+	}
+
+	return chart, nil
+}
+
+type chartComputer struct {
+	rules    Rules
+	fullShoe odds.Shoe
+	dealer   *odds.Calculator
+}
+
+// Starting hands without an ace with the points. Pairs are used only when the points
+// can not be made otherwise, hard 21 is made of three cards
+func hardCombos(points int) [][2]int {
+	var combos, pairs [][2]int
+
+	for first := 2; first <= odds.CardValues; first++ {
+		second := points - first
+		if second < first || second > odds.CardValues {
+			continue
+		}
+
+		if first == second {
+			pairs = append(pairs, [2]int{first, second})
+		} else {
+			combos = append(combos, [2]int{first, second})
+		}
+	}
+
+	if len(combos) == 0 {
+		return pairs
+	}
+
+	return combos
+}
+
+// Cells of the dealer cards, the values of the hands are weighted by their probability
+func (c *chartComputer) row(combos [][2]int, split bool) ([]Cell, error) {
+	row := make([]Cell, odds.CardValues)
+
+	for upValue := 1; upValue <= odds.CardValues; upValue++ {
+		ev := make(map[Move]float64)
+		weights := 0.0
+
+		for _, combo := range combos {
+			weight := pairWeight(c.fullShoe, combo[0], combo[1])
+			if weight == 0 {
+				continue
+			}
+
+			s, err := c.fullShoe.Without(combo[0], combo[1], upValue)
+			if err != nil {
+				return nil, err
+			}
+
+			e, err := newEvaluator(c.rules, c.dealer, s, upValue)
+			if err != nil {
+				return nil, err
+			}
+
+			comboEV := e.startingHand(combo[0], combo[1], split)
+			for move, value := range comboEV {
+				ev[move] += value * weight
+			}
+			weights += weight
+		}
+
+		// Hard 21 can not be dealt, it is played from the shoe without the starting cards
+		if weights == 0 {
+			s, err := c.fullShoe.Without(upValue)
+			if err != nil {
+				return nil, err
+			}
+
+			e, err := newEvaluator(c.rules, c.dealer, s, upValue)
+			if err != nil {
+				return nil, err
+			}
+
+			ev = e.hitOrStand(21, false)
+			weights = 1
+		}
+
+		cell := Cell{
+			EV: make(map[Move]float64, len(ev)),
+		}
+
+		for move, value := range ev {
+			cell.EV[move] = value / weights
+
+			if cell.Move == "" || cell.EV[move] > cell.EV[cell.Move] {
+				cell.Move = move
+			}
+		}
+
+		row[column(upValue)] = cell
+	}
+
+	return row, nil
+}
+
+// Cell of the hand and the name of its row
+func (c *Chart) find(values []int, upValue int, splitLegal bool) (Cell, string) {
+	points, soft := handPoints(values...)
+	col := column(upValue)
+
+	if len(values) == 2 && values[0] == values[1] && splitLegal {
+		row := values[0]
+		if row == 1 {
+			row = aceRow
+			return c.Pairs[row][col], "pair of aces"
+		}
+
+		return c.Pairs[row][col], fmt.Sprintf("pair of %ds", row)
+	}
+
+	if points > maxPoints {
+		return Cell{}, fmt.Sprintf("hard %d", points)
+	}
+
+	if soft {
+		return c.Soft[clampRow(points, firstSoftRow)][col], fmt.Sprintf("soft %d", points)
+	}
+
+	return c.Hard[clampRow(points, firstHardRow)][col], fmt.Sprintf("hard %d", points)
+}
+
+func clampRow(points int, first int) int {
+	if points < first {
+		return first
+	}
+
+	return points
+}
+
+// Write
+// Print the charts in the usual form:
+// H - hit, S - stand, Dh - double or hit, Ds - double or stand, P - split, Rh - surrender or hit, Rs - surrender or stand.
+func (c *Chart) Write(w io.Writer) error {
+	sections := []struct {
+		title string
+		rows  map[int][]Cell
+		first int
+		last  int
+	}{
+		{title: "Hard", rows: c.Hard, first: 5, last: 20},
+		{title: "Soft", rows: c.Soft, first: 13, last: 20},
+		{title: "Pairs", rows: c.Pairs, first: 2, last: aceRow},
+	}
+
+	for i, section := range sections {
+		if i > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+
+		if _, err := fmt.Fprintf(w, "%-6s  2  3  4  5  6  7  8  9 10  A\n", section.title); err != nil {
+			return err
+		}
+
+		for row := section.first; row <= section.last; row++ {
+			name := strconv.Itoa(row)
+			if section.title == "Pairs" {
+				name = pairName(row)
+			}
+
+			line := fmt.Sprintf("%-6s", name)
+			for _, cell := range section.rows[row] {
+				line += fmt.Sprintf(" %2s", cell.code())
+			}
+
+			if _, err := fmt.Fprintln(w, line); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func pairName(row int) string {
+	if row == aceRow {
+		return "A,A"
+	}
+
+	return fmt.Sprintf("%d,%d", row, row)
+}
+
+// Chart code of the best move with the move to make when it is not allowed
+func (c Cell) code() string {
+	fallback := "h"
+	if c.EV[MoveStand] > c.EV[MoveHit] {
+		fallback = "s"
+	}
+
+	switch c.Move {
+	case MoveHit:
+		return "H"
+	case MoveStand:
+		return "S"
+	case MoveDouble:
+		return "D" + fallback
+	case MoveSplit:
+		return "P"
+	case MoveSurrender:
+		return "R" + fallback
+	default:
+		return "?"
+	}
+}
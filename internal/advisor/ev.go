@@ -0,0 +1,164 @@
+package advisor
+
+import "course/internal/odds"
+
+// Number of the ways to deal the two cards from the shoe
+func pairWeight(s odds.Shoe, first int, second int) float64 {
+	if first == second {
+		return float64(s.Count(first) * (s.Count(first) - 1))
+	}
+
+	return float64(2 * s.Count(first) * s.Count(second))
+}
+
+// Expected values of the player moves with the shoe after the starting cards.
+// The player draws from the same shoe composition, the dealer removes the cards he takes
+type evaluator struct {
+	rules Rules
+	shoe  odds.Shoe
+	// Dealer outcomes when he has no blackjack, with the probability of the blackjack
+	dealer  odds.Outcomes
+	stand   [maxPoints + 1]float64
+	hitMemo map[odds.Hand]float64
+}
+
+// Dealer outcomes are taken with the peek, the blackjack is added by withBlackjack
+func newDealerCalculator(rules Rules) *odds.Calculator {
+	return odds.NewCalculator(odds.Rules{
+		DealerHitsSoft17: rules.DealerHitsSoft17,
+		DealerPeeks:      true,
+	})
+}
+
+func newEvaluator(rules Rules, dealer *odds.Calculator, s odds.Shoe, upValue int) (*evaluator, error) {
+	outcomes, err := dealer.Outcomes(upValue, s)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &evaluator{
+		rules:   rules,
+		shoe:    s,
+		dealer:  outcomes,
+		hitMemo: make(map[odds.Hand]float64),
+	}
+
+	for points := 0; points <= maxPoints; points++ {
+		e.stand[points] = outcomes.StandEV(points)
+	}
+
+	return e, nil
+}
+
+func (e *evaluator) standPoints(points int) float64 {
+	if points > maxPoints {
+		return -1
+	}
+
+	return e.stand[points]
+}
+
+// Value of the hand played the best way with hit and stand
+func (e *evaluator) best(h odds.Hand) float64 {
+	points, _ := h.Points()
+	if points > maxPoints {
+		return -1
+	}
+
+	hit := e.hitEV(h)
+	if hit > e.standPoints(points) {
+		return hit
+	}
+
+	return e.standPoints(points)
+}
+
+func (e *evaluator) hitEV(h odds.Hand) float64 {
+	if ev, ok := e.hitMemo[h]; ok {
+		return ev
+	}
+
+	ev := 0.0
+	for value := 1; value <= odds.CardValues; value++ {
+		ev += e.shoe.Probability(value) * e.best(h.Add(value))
+	}
+
+	e.hitMemo[h] = ev
+
+	return ev
+}
+
+func (e *evaluator) doubleEV(h odds.Hand) float64 {
+	ev := 0.0
+
+	for value := 1; value <= odds.CardValues; value++ {
+		points, _ := h.Add(value).Points()
+		ev += e.shoe.Probability(value) * 2 * e.standPoints(points)
+	}
+
+	return ev
+}
+
+// Two hands of one card of the pair, the split aces get one card each
+func (e *evaluator) splitEV(value int) float64 {
+	ev := 0.0
+
+	for second := 1; second <= odds.CardValues; second++ {
+		h := odds.Hand{}.Add(value).Add(second)
+		points, _ := h.Points()
+
+		handEV := e.standPoints(points)
+		if value != 1 {
+			handEV = e.best(h)
+
+			if e.rules.DoubleAfterSplit {
+				if double := e.doubleEV(h); double > handEV {
+					handEV = double
+				}
+			}
+		}
+
+		ev += e.shoe.Probability(second) * 2 * handEV
+	}
+
+	return ev
+}
+
+// Values of the moves of the hand after the starting cards
+func (e *evaluator) startingHand(first int, second int, split bool) map[Move]float64 {
+	h := odds.Hand{}.Add(first).Add(second)
+	ev := e.hitOrStand(h.Sum, h.Ace)
+
+	ev[MoveDouble] = e.withBlackjack(e.doubleEV(h), 2)
+
+	if e.rules.Surrender {
+		ev[MoveSurrender] = e.withBlackjack(-0.5, 1)
+	}
+
+	if split {
+		ev[MoveSplit] = e.withBlackjack(e.splitEV(first), 2)
+	}
+
+	return ev
+}
+
+// Values of hit and stand of the hand
+func (e *evaluator) hitOrStand(sum int, ace bool) map[Move]float64 {
+	h := odds.Hand{Sum: sum, Ace: ace}
+	points, _ := h.Points()
+
+	return map[Move]float64{
+		MoveHit:   e.withBlackjack(e.hitEV(h), 1),
+		MoveStand: e.withBlackjack(e.standPoints(points), 1),
+	}
+}
+
+// Without the peek the dealer blackjack takes all the bets of the hand.
+// With the peek the hand is played only when the dealer has no blackjack
+func (e *evaluator) withBlackjack(ev float64, bets float64) float64 {
+	if e.rules.DealerPeeks {
+		return ev
+	}
+
+	return (1-e.dealer.Blackjack)*ev - e.dealer.Blackjack*bets
+}
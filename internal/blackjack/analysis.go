@@ -0,0 +1,241 @@
+package blackjack
+
+import (
+	"course/internal/deck"
+	"course/internal/odds"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Analysis
+// Odds of the decision of a player, computed from the cards he has not seen.
+type Analysis struct {
+	// Cards not seen at the table: the rest of the shoe and the closed dealer cards
+	UnseenCards int
+	// Probability to bust with one more card
+	BustProbability float64
+	// Probabilities of the final dealer points from 17 to 21
+	DealerPoints map[int]float64
+	DealerBust   float64
+	// Probability of a dealer natural blackjack, only if it beats 21 points (Rules.BlackjackPays3To2)
+	DealerBlackjack float64
+	// Expected values of the legal moves, in bets. After a hit the hand is played the best way
+	EV map[Move]float64
+}
+
+// AnalyzeHand
+// Exact odds of the hand against the opened dealer card. The unseen cards are the cards the player
+// has not seen: the dealer takes his closed and next cards from them, the player his next cards.
+func AnalyzeHand(rules Rules, cards []*deck.Card, dealerUpCard *deck.Card, unseen []*deck.Card, legalMoves []Move) (Analysis, error) {
+	shoe, err := odds.ShoeOf(unseen)
+	if err != nil {
+		return Analysis{}, err
+	}
+
+	upValue, err := odds.CardValue(dealerUpCard)
+	if err != nil {
+		return Analysis{}, err
+	}
+
+	h, err := odds.HandOf(cards)
+	if err != nil {
+		return Analysis{}, err
+	}
+
+	o := &handOdds{
+		upValue: upValue,
+		dealer:  odds.NewCalculator(rules.oddsRules()),
+		hitMemo: make(map[hitState]float64),
+	}
+
+	outcomes, err := o.dealer.Outcomes(upValue, shoe)
+	if err != nil {
+		return Analysis{}, err
+	}
+
+	analysis := Analysis{
+		UnseenCards:     shoe.Total(),
+		DealerPoints:    make(map[int]float64),
+		DealerBust:      outcomes.Bust,
+		DealerBlackjack: outcomes.Blackjack,
+		EV:              make(map[Move]float64),
+	}
+
+	for value := 1; value <= odds.CardValues; value++ {
+		if points, _ := h.Add(value).Points(); points > MaxPoints {
+			analysis.BustProbability += shoe.Probability(value)
+		}
+	}
+
+	for points := DealerPointsTakeCardLimit + 1; points <= MaxPoints; points++ {
+		analysis.DealerPoints[points] = outcomes.Probability(points)
+	}
+
+	for _, move := range legalMoves {
+		switch move {
+		case MoveHit:
+			analysis.EV[move], err = o.hit(shoe, h)
+		case MoveStand:
+			analysis.EV[move], err = o.stand(shoe, h)
+
+			// A natural pays 3 to 2 and pushes with the dealer natural
+			if rules.BlackjackPays3To2 && IsNatural(cards) {
+				analysis.EV[move] = (1 - outcomes.Blackjack) * 1.5
+			}
+		}
+
+		if err != nil {
+			return Analysis{}, err
+		}
+	}
+
+	return analysis, nil
+}
+
+// Dealer outcome rules of the table. The dealer does not peek, a natural beats 21 points
+// only when it pays 3 to 2
+func (r Rules) oddsRules() odds.Rules {
+	return odds.Rules{
+		DealerHitsSoft17: r.DealerHitsSoft17,
+		NaturalBeats21:   r.BlackjackPays3To2,
+	}
+}
+
+// Write
+// Print the analysis with the best move first.
+func (a Analysis) Write(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "Unseen cards: %d\nBust on a hit: %.1f%%\nDealer final points:\n", a.UnseenCards, a.BustProbability*100); err != nil {
+		return err
+	}
+
+	for points := DealerPointsTakeCardLimit + 1; points <= MaxPoints; points++ {
+		if _, err := fmt.Fprintf(w, "  %d: %.1f%%\n", points, a.DealerPoints[points]*100); err != nil {
+			return err
+		}
+	}
+
+	if a.DealerBlackjack > 0 {
+		if _, err := fmt.Fprintf(w, "  blackjack: %.1f%%\n", a.DealerBlackjack*100); err != nil {
+			return err
+		}
+	}
+
+	if _, err := fmt.Fprintf(w, "  bust: %.1f%%\nExpected values:\n", a.DealerBust*100); err != nil {
+		return err
+	}
+
+	moves := make([]Move, 0, len(a.EV))
+	for move := range a.EV {
+		moves = append(moves, move)
+	}
+	sort.Slice(moves, func(i, j int) bool {
+		return a.EV[moves[i]] > a.EV[moves[j]]
+	})
+
+	for _, move := range moves {
+		if _, err := fmt.Fprintf(w, "  %s: %+.3f\n", move, a.EV[move]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Odds of the current user hand. The closed dealer cards are unseen, as the rest of the shoe
+func (bj *Blackjack) analyzeUserHand() (Analysis, error) {
+	unseen := bj.deck.Rest()
+	unseen = append(unseen, bj.dealer.Cards[1:]...)
+
+	moves := legalMoves
+	if points, err := bj.currentUser.getPoints(); err != nil {
+		return Analysis{}, err
+	} else if points > MaxPoints {
+		moves = []Move{MoveStand}
+	}
+
+	return AnalyzeHand(bj.rules, bj.currentUser.Cards, bj.dealer.Cards[0], unseen, moves)
+}
+
+func (bj *Blackjack) printAnalysis() error {
+	analysis, err := bj.analyzeUserHand()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(bj.out, "\nAnalysis:")
+
+	return analysis.Write(bj.out)
+}
+
+type hitState struct {
+	shoe odds.Shoe
+	hand odds.Hand
+}
+
+// Values of the hit by the unseen cards and the hand
+type handOdds struct {
+	upValue int
+	dealer  *odds.Calculator
+	hitMemo map[hitState]float64
+}
+
+// Value of standing with the hand by the Rules.Settle results
+func (o *handOdds) stand(shoe odds.Shoe, h odds.Hand) (float64, error) {
+	outcomes, err := o.dealer.Outcomes(o.upValue, shoe)
+	if err != nil {
+		return 0, err
+	}
+
+	points, _ := h.Points()
+
+	return outcomes.StandEV(points), nil
+}
+
+// Value of taking a card and playing the hand the best way with hit and stand
+func (o *handOdds) hit(shoe odds.Shoe, h odds.Hand) (float64, error) {
+	state := hitState{shoe: shoe, hand: h}
+	if ev, ok := o.hitMemo[state]; ok {
+		return ev, nil
+	}
+
+	ev := 0.0
+
+	for value := 1; value <= odds.CardValues; value++ {
+		if shoe.Count(value) == 0 {
+			continue
+		}
+
+		next := h.Add(value)
+		rest, err := shoe.Without(value)
+		if err != nil {
+			return 0, err
+		}
+
+		best := -1.0
+		// The dealer needs a card left for his closed card
+		if points, _ := next.Points(); points <= MaxPoints && rest.Total() > 0 {
+			best, err = o.stand(rest, next)
+			if err != nil {
+				return 0, err
+			}
+
+			if rest.Total() > 1 {
+				hit, err := o.hit(rest, next)
+				if err != nil {
+					return 0, err
+				}
+
+				if hit > best {
+					best = hit
+				}
+			}
+		}
+
+		ev += shoe.Probability(value) * best
+	}
+
+	o.hitMemo[state] = ev
+
+	return ev, nil
+}
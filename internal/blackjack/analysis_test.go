@@ -0,0 +1,142 @@
+package blackjack
+
+import (
+	"bytes"
+	"course/internal/deck"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestAnalyzeHand(t *testing.T) {
+	testCases := []struct {
+		name   string
+		rules  Rules
+		hand   []deck.Rank
+		dealer deck.Rank
+		unseen []deck.Rank
+		check  func(analysis Analysis, err error)
+	}{
+		{
+			name:   "Hard 16 Against Ten",
+			hand:   []deck.Rank{deck.King, deck.Six},
+			dealer: deck.Queen,
+			unseen: []deck.Rank{deck.King, deck.Four, deck.Five},
+			check: func(analysis Analysis, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 3, analysis.UnseenCards)
+				require.InDelta(t, 1.0/3, analysis.BustProbability, 1e-9)
+				// The hole card king gives 20, the small cards give 19 or the bust with the next card
+				require.InDelta(t, 1.0/3, analysis.DealerPoints[20], 1e-9)
+				require.InDelta(t, 1.0/3, analysis.DealerPoints[19], 1e-9)
+				require.InDelta(t, 1.0/3, analysis.DealerBust, 1e-9)
+				require.Zero(t, analysis.DealerBlackjack)
+				require.InDelta(t, -1.0/3, analysis.EV[MoveStand], 1e-9)
+				// The king busts, the 4 gives 20 (+0.5), the 5 gives 21 (+1)
+				require.InDelta(t, 1.0/6, analysis.EV[MoveHit], 1e-9)
+			},
+		},
+		{
+			name:   "Dealer Natural Beats 21",
+			rules:  Rules{BlackjackPays3To2: true},
+			hand:   []deck.Rank{deck.King, deck.Queen},
+			dealer: deck.Ace,
+			unseen: []deck.Rank{deck.King, deck.Nine},
+			check: func(analysis Analysis, err error) {
+				require.NoError(t, err)
+				require.InDelta(t, 0.5, analysis.DealerBlackjack, 1e-9)
+				require.InDelta(t, 0.5, analysis.DealerPoints[20], 1e-9)
+				require.InDelta(t, -0.5, analysis.EV[MoveStand], 1e-9)
+				require.Equal(t, 1.0, analysis.BustProbability)
+			},
+		},
+		{
+			name:   "Dealer Natural Is 21",
+			hand:   []deck.Rank{deck.Nine, deck.Two},
+			dealer: deck.Ace,
+			unseen: []deck.Rank{deck.King, deck.Nine},
+			check: func(analysis Analysis, err error) {
+				require.NoError(t, err)
+				require.Zero(t, analysis.DealerBlackjack)
+				require.InDelta(t, 0.5, analysis.DealerPoints[21], 1e-9)
+				require.Zero(t, analysis.BustProbability)
+			},
+		},
+		{
+			name:   "Dealer Hits Soft 17",
+			rules:  Rules{DealerHitsSoft17: true},
+			hand:   []deck.Rank{deck.King, deck.Eight},
+			dealer: deck.Ace,
+			unseen: []deck.Rank{deck.Six, deck.Four, deck.Four},
+			check: func(analysis Analysis, err error) {
+				require.NoError(t, err)
+				// Soft 17 takes a 4, soft 15 takes the 6 or the other 4
+				require.Zero(t, analysis.DealerPoints[17])
+				require.InDelta(t, 2.0/3, analysis.DealerPoints[21], 1e-9)
+				require.InDelta(t, 1.0/3, analysis.DealerPoints[19], 1e-9)
+				require.InDelta(t, -1.0, analysis.EV[MoveStand], 1e-9)
+			},
+		},
+		{
+			name:   "No Unseen Cards",
+			hand:   []deck.Rank{deck.King, deck.Eight},
+			dealer: deck.Ace,
+			check: func(analysis Analysis, err error) {
+				require.Error(t, err)
+			},
+		},
+		{
+			name:   "Invalid Card",
+			hand:   []deck.Rank{deck.King, deck.Eight},
+			dealer: deck.Ace,
+			unseen: []deck.Rank{deck.Joker},
+			check: func(analysis Analysis, err error) {
+				require.Error(t, err)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			analysis, err := AnalyzeHand(tc.rules, getTestCards(tc.hand...), deck.MustNewCard(deck.Spade, tc.dealer),
+				getTestCards(tc.unseen...), legalMoves)
+			tc.check(analysis, err)
+		})
+	}
+}
+
+func TestAnalyzeHand_Shoe(t *testing.T) {
+	shoe, err := deck.NewDeck(deck.NewDeckOptions{DecksNumber: 6})
+	require.NoError(t, err)
+
+	hand := shoe[:2]
+	dealerUpCard := shoe[2]
+
+	analysis, err := AnalyzeHand(Rules{}, hand, dealerUpCard, shoe[3:], legalMoves)
+	require.NoError(t, err)
+
+	total := analysis.DealerBust + analysis.DealerBlackjack
+	for _, probability := range analysis.DealerPoints {
+		total += probability
+	}
+	require.InDelta(t, 1.0, total, 1e-9)
+	require.Len(t, analysis.EV, 2)
+
+	var buf bytes.Buffer
+	require.NoError(t, analysis.Write(&buf))
+	require.Contains(t, buf.String(), "Unseen cards: 309\n")
+}
+
+func TestBlackjack_onUserInputAnalyze(t *testing.T) {
+	b, out := getTestTrainerGame(t, false)
+
+	res, err := b.onUserInput(string(ActionAnalyze))
+	require.NoError(t, err)
+	require.False(t, res)
+
+	// The 5 and the closed dealer 9 are unseen
+	require.Contains(t, out.String(), "Unseen cards: 2\nBust on a hit: 50.0%\n")
+	require.Contains(t, out.String(), "  19: 50.0%\n  20: 0.0%\n  21: 0.0%\n  bust: 50.0%\n")
+	require.Contains(t, out.String(), "  hit: +0.000\n")
+}
@@ -0,0 +1,56 @@
+package blackjack
+
+import (
+	"course/internal/advisor"
+	"course/internal/deck"
+)
+
+// BasicStrategy
+// Plays the moves of the basic strategy charts computed by the advisor for the table rules
+// and bets a tenth of the money.
+type BasicStrategy struct{}
+
+func (s *BasicStrategy) Name() string {
+	return StrategyBasic
+}
+
+func (s *BasicStrategy) Bet(state BetState) int {
+	return tenthBet(state.Money)
+}
+
+func (s *BasicStrategy) Decide(hand HandState, dealerUpCard *deck.Card, legalMoves []Move) Move {
+	advice, err := Advise(hand, dealerUpCard, legalMoves)
+	if err != nil {
+		return chooseMove(legalMoves, MoveStand)
+	}
+
+	return advice.Move
+}
+
+// Advise
+// The basic strategy move of the hand with the table rules of the hand state.
+func Advise(hand HandState, dealerUpCard *deck.Card, legalMoves []Move) (advisor.Advice, error) {
+	a, err := advisor.New(hand.Rules.advisorRules(hand.DecksNumber))
+	if err != nil {
+		return advisor.Advice{}, err
+	}
+
+	return a.Advise(hand.Cards, dealerUpCard, legalMoves)
+}
+
+// Advisor rules of the table. The game has no double, split and surrender,
+// so the charts give the common rules for them
+func (r Rules) advisorRules(decksNumber int) advisor.Rules {
+	if decksNumber == 0 {
+		decksNumber = MinDecksNumber
+	}
+
+	return advisor.Rules{
+		DecksNumber:      decksNumber,
+		DealerHitsSoft17: r.DealerHitsSoft17,
+		DoubleAfterSplit: true,
+		// With the hole card the dealer blackjack is known before the players play and only takes the bet.
+		// Without it the dealer takes his second card after them, so a blackjack takes the doubled and split bets too
+		DealerPeeks: !r.NoHoleCard,
+	}
+}
@@ -6,46 +6,164 @@ package blackjack
 
 import (
 	"course/internal/console"
+	"course/internal/counting"
 	"course/internal/deck"
 	"course/pkg/random"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"time"
 )
 
 type Blackjack struct {
-	// Deck
-	deck []*deck.Card
-	// Index of the map to be taken next
-	nextDeckCardIndex int
+	// Shoe the cards are drawn from, the played cards go to its discard pile
+	deck *deck.Deck
 	// Deck Settings
 	deckOptions deck.NewDeckOptions
+	// Source of the shoes
+	decks DeckProvider
+	// Part of the shoe dealt before it is reshuffled. 0 if a new shoe is dealt every round
+	penetration float64
+	// The shoe was shuffled and no round was dealt from it yet
+	shuffled bool
+	// Table rules
+	rules Rules
 	// Player
 	players []*Player
 	// Number of bots
 	botsNumber int
+	// Strategies of the bot seats in order
+	botStrategies []Strategy
+	// Inputs of the user seats in order
+	userInputs []Input
 	// Dealer
 	dealer *Dealer
 	// Have the starting cards been distributed
 	isStartingCardsDistributed bool
 	// Index of the current move
 	currentTurnIndex int
-	// Current user player id
+	// User whose turn it is, the first user before the first turn. nil if every seat is played by a bot
 	currentUser *Player
 	// All the players were saved
 	isAllPlayersSaved bool
 	// All saved
 	isAllSaved bool
-	// Console
-	console *console.Console
+	// Source of the user input
+	input Input
+	// Where the game is printed
+	out io.Writer
+	// How the cards are printed
+	cardStyle deck.Style
+	// Pause between the moves of the bots and the dealer
+	delay time.Duration
+	// File for the save and load commands
+	saveFile string
+	// Number of the current round
+	round int
+	// Score of the user decisions. nil if the game is not in the trainer mode
+	trainer *Trainer
+	// Score of the count quiz. nil if the quiz is off
+	countQuiz *CountQuiz
+	// Game event observers
+	observers []Observer
+	// Files opened by the game
+	closers []io.Closer
+	// Source of the shuffles, the bot names and the bets of the naive bots. Every game has its own one,
+	// so that the games of several goroutines do not share it
+	random *random.Random
+}
+
+// Rules
+// Table rule variants. The zero value is the classic rules of the game.
+type Rules struct {
+	// The dealer takes a card on soft 17 (an ace is counted as 11 points)
+	DealerHitsSoft17 bool
+	// A natural blackjack beats any other 21 points and pays 3 to 2
+	BlackjackPays3To2 bool
+	// The dealer gets only one card on the deal and takes the second one on his turn (European rules)
+	NoHoleCard bool
+}
+
+// SeatConfig
+// A seat at the table, played by a user or a bot.
+type SeatConfig struct {
+	// Name of the player, a bot gets a free default name if empty
+	Name string
+	Bot  bool
+	// Input of the user of the seat, Config.Input is used if nil
+	Input Input
+}
+
+// Input
+// Source of the user input lines. The users of a hot-seat game share it and answer in turn.
+type Input interface {
+	Input() string
+}
+
+// An input that tells when its lines are over, like the console. Input returns empty lines after that
+type eofInput interface {
+	EOF() bool
+}
+
+// The empty line is the end of the input
+func isInputOver(input Input, line string) bool {
+	eof, ok := input.(eofInput)
+	return ok && line == "" && eof.EOF()
 }
 
 type Config struct {
 	PlayersStartingMoney int
 	BotsNumber           int
-	Username             string
+	// Name of the user. Not used if BotsOnly
+	Username string
+	// Every seat is played by a bot, the game is run with Simulate
+	BotsOnly bool
+	// Seats of the table in the dealing order. Several users play at one console in turn (hot-seat).
+	// If set, Username, BotsNumber and BotsOnly are not used.
+	// Otherwise the user takes the first seat and BotsNumber bots the next ones
+	Seats []SeatConfig
+	// Source of the user input and of the pauses between the rounds. The console is used if nil
+	Input Input
+	// Strategies of the bot seats in order. NaiveStrategy plays the seats without a strategy
+	BotStrategies []Strategy
+	// Names of the built-in strategies of the bot seats in order, see StrategyNames. Used if BotStrategies is empty.
+	// The strategies take the random numbers from the source of the game, so the seed repeats their bets and moves
+	BotStrategyNames []string
+	// Every move of the user is compared with the basic strategy, the mistakes are shown at once
+	Trainer bool
+	// The user is asked for the running count of CountSystem between the rounds
+	CountQuiz bool
+	// Name of the counting system of the count quiz, see counting.SystemByName. Hi-Lo is used if empty
+	CountSystem string
+	// Number of decks in the game. 1 deck is used if 0
+	DecksNumber int
+	// Part of the shoe dealt before it is reshuffled, from 0 to MaxPenetration.
+	// The shoe is kept between the rounds if greater than 0, otherwise every round is dealt from a new shoe
+	Penetration float64
+	Rules       Rules
+	// Source of the shoes, for example the stacked decks of a scenario. RandomShoe is used if nil
+	Decks DeckProvider
+	// Seed of the random source of the game, used if greater than 0. Every game has its own source.
+	// The same seed gives the same decks, bets and player names
+	Seed int64
+	// Pause between the moves of the bots and the dealer. Delay is used if 0
+	Delay time.Duration
+	// Play without pauses
+	NoDelay bool
+	// Where the game is printed. os.Stdout is used if nil
+	Output io.Writer
+	// How the cards are printed. deck.StyleShort is used if empty
+	CardStyle deck.Style
+	// File for the save and load commands. DefaultSaveFile is used if empty
+	SaveFile string
+	// File to which the hand history is appended. The history is not written if empty
+	HandHistoryFile string
+	// Writer for the JSON Lines event log. The log is not written if nil
+	EventLog io.Writer
+	// Additional game event observers
+	Observers []Observer
 }
 
 var (
@@ -53,110 +171,499 @@ var (
 	MaxBotsNumber = 9
 )
 
+var (
+	MinDecksNumber = 1
+	MaxDecksNumber = 8
+)
+
+var MaxPenetration = 0.9
+
 var (
 	ErrInvalidPlayersStartingMoney = errors.New("player starting money is negative or equal to 0")
 	ErrBotsNumberLessThan          = errors.New("bots number less then 0")
 	ErrBotsNumberGreaterThan       = errors.New("bots number greater than 9")
 	ErrEmptyUsername               = errors.New("username is required")
+	ErrInvalidDecksNumber          = errors.New("decks number less than 1 or greater than 8")
+	ErrNegativeDelay               = errors.New("delay is negative")
+	ErrInvalidPenetration          = errors.New("penetration less than 0 or greater than 0.9")
+	ErrTooManyBotStrategies        = errors.New("more bot strategies than bots")
+	ErrTooManySeats                = errors.New("more than 10 seats")
+	ErrDuplicateSeatName           = errors.New("seat names are not unique")
+	ErrBotsOnlyGame                = errors.New("the game has no user seat")
+	ErrUserGame                    = errors.New("the game has a user seat")
+	ErrReservedName                = errors.New("the name is reserved for the dealer")
 )
 
-func NewBlackjack(cfg Config) (*Blackjack, error) {
+// The user has left the game
+var errExit = errors.New("exit")
+
+// Validate
+// Check the config with the same rules as NewBlackjack.
+func (cfg Config) Validate() error {
 	if cfg.PlayersStartingMoney <= 0 {
-		return nil, ErrInvalidPlayersStartingMoney
+		return ErrInvalidPlayersStartingMoney
+	}
+
+	if len(cfg.Seats) > 0 {
+		if err := validateSeats(cfg.Seats); err != nil {
+			return err
+		}
+	} else if cfg.BotsNumber < MinBotsNumber {
+		return ErrBotsNumberLessThan
+	} else if cfg.BotsNumber > MaxBotsNumber {
+		return ErrBotsNumberGreaterThan
+	} else if cfg.Username == "" && !cfg.BotsOnly {
+		return ErrEmptyUsername
+	} else if IsReservedName(cfg.Username) && !cfg.BotsOnly {
+		return ErrReservedName
 	}
 
-	if cfg.BotsNumber < MinBotsNumber {
-		return nil, ErrBotsNumberLessThan
+	if cfg.DecksNumber != 0 && (cfg.DecksNumber < MinDecksNumber || cfg.DecksNumber > MaxDecksNumber) {
+		return ErrInvalidDecksNumber
 	}
 
-	if cfg.BotsNumber > MaxBotsNumber {
-		return nil, ErrBotsNumberGreaterThan
+	if cfg.Delay < 0 {
+		return ErrNegativeDelay
 	}
 
-	if cfg.Username == "" {
-		return nil, ErrEmptyUsername
+	if cfg.Penetration < 0 || cfg.Penetration > MaxPenetration {
+		return ErrInvalidPenetration
 	}
 
-	// 1 is user
-	playersNumber := 1 + cfg.BotsNumber
-	players := make([]*Player, 0, playersNumber)
+	if _, err := deck.ParseStyle(string(cfg.CardStyle)); err != nil {
+		return err
+	}
 
-	playerUser, err := newPlayer(cfg.Username, cfg.PlayersStartingMoney, false)
-	if err != nil {
-		return nil, err
+	if len(cfg.BotStrategies) > countBots(cfg.tableSeats()) || len(cfg.BotStrategyNames) > countBots(cfg.tableSeats()) {
+		return ErrTooManyBotStrategies
+	}
+
+	for _, name := range cfg.BotStrategyNames {
+		if _, ok := strategies[name]; !ok {
+			return fmt.Errorf("%w: %q, available strategies: %v", ErrUnknownStrategy, name, StrategyNames())
+		}
+	}
+
+	if cfg.CountSystem != "" {
+		if _, err := counting.SystemByName(cfg.CountSystem); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateSeats(seats []SeatConfig) error {
+	if len(seats) > MaxPlayers {
+		return ErrTooManySeats
+	}
+
+	names := make(map[string]bool, len(seats))
+
+	for _, seat := range seats {
+		if seat.Name == "" {
+			if !seat.Bot {
+				return ErrEmptyUsername
+			}
+			continue
+		}
+
+		if IsReservedName(seat.Name) {
+			return ErrReservedName
+		}
+
+		if names[seat.Name] {
+			return ErrDuplicateSeatName
+		}
+		names[seat.Name] = true
+	}
+
+	return nil
+}
+
+// Seats of the table in the dealing order
+func (cfg Config) tableSeats() []SeatConfig {
+	if len(cfg.Seats) > 0 {
+		return cfg.Seats
 	}
 
-	players = append(players, playerUser)
+	seats := make([]SeatConfig, 0, 1+cfg.BotsNumber)
+
+	if !cfg.BotsOnly {
+		seats = append(seats, SeatConfig{Name: cfg.Username})
+	}
 
 	for i := 0; i < cfg.BotsNumber; i++ {
-		bot, err := newPlayer(DefaultPlayerNames[random.RandInt(0, len(DefaultPlayerNames))], cfg.PlayersStartingMoney, true)
+		seats = append(seats, SeatConfig{Bot: true})
+	}
+
+	return seats
+}
+
+func countBots(seats []SeatConfig) int {
+	bots := 0
+
+	for _, seat := range seats {
+		if seat.Bot {
+			bots++
+		}
+	}
+
+	return bots
+}
+
+func NewBlackjack(cfg Config) (*Blackjack, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	decksNumber := cfg.DecksNumber
+	if decksNumber == 0 {
+		decksNumber = MinDecksNumber
+	}
+
+	delay := cfg.Delay
+	if delay == 0 {
+		delay = Delay
+	}
+
+	if cfg.NoDelay {
+		delay = 0
+	}
+
+	out := cfg.Output
+	if out == nil {
+		out = os.Stdout
+	}
+
+	// The source is seeded with the time if the seed is 0
+	rnd := random.New(random.Config{Seed: cfg.Seed})
+
+	// The style is checked by Validate
+	cardStyle, _ := deck.ParseStyle(string(cfg.CardStyle))
+
+	seats := cfg.tableSeats()
+	players := make([]*Player, 0, len(seats))
+
+	var takenNames []string
+	for _, seat := range seats {
+		if seat.Name != "" {
+			takenNames = append(takenNames, seat.Name)
+		}
+	}
+
+	botsNumber := countBots(seats)
+	botNames := getBotNames(rnd, takenNames, botsNumber)
+
+	var playerUser *Player
+	var userInputs []Input
+
+	for _, seat := range seats {
+		name := seat.Name
+		if name == "" {
+			name, botNames = botNames[0], botNames[1:]
+		}
+
+		player, err := newPlayer(rnd, name, cfg.PlayersStartingMoney, seat.Bot)
 		if err != nil {
 			return nil, err
 		}
-		players = append(players, bot)
+
+		if !seat.Bot {
+			if playerUser == nil {
+				playerUser = player
+			}
+			userInputs = append(userInputs, seat.Input)
+		}
+		players = append(players, player)
 	}
 
-	dealer, err := newDealer()
+	dealer, err := newDealer(rnd)
 	if err != nil {
 		return nil, err
 	}
 
-	deckOptions := deck.NewDeckOptions{}
-	bjDeck, err := deck.NewDeck(deckOptions)
-	if err != nil {
-		return nil, err
+	deckOptions := deck.NewDeckOptions{
+		DecksNumber: decksNumber,
+		Random:      rnd,
+	}
+	decks := cfg.Decks
+	if decks == nil {
+		decks = RandomShoe{}
 	}
 
-	cnsl, err := console.NewConsole()
+	bjDeck, err := nextShoe(decks, deckOptions)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Blackjack{
+	input := cfg.Input
+	if input == nil {
+		cnsl, err := console.NewConsole()
+		if err != nil {
+			return nil, err
+		}
+		input = cnsl
+	}
+
+	saveFile := cfg.SaveFile
+	if saveFile == "" {
+		saveFile = DefaultSaveFile
+	}
+
+	observers := append([]Observer{}, cfg.Observers...)
+	var closers []io.Closer
+
+	if cfg.EventLog != nil {
+		observers = append(observers, NewJSONLog(cfg.EventLog))
+	}
+
+	if cfg.HandHistoryFile != "" {
+		file, err := os.OpenFile(cfg.HandHistoryFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, err
+		}
+		observers = append(observers, NewHandHistory(file, cfg))
+		closers = append(closers, file)
+	}
+
+	var trainer *Trainer
+	if cfg.Trainer {
+		trainer = &Trainer{}
+	}
+
+	var countQuiz *CountQuiz
+	if cfg.CountQuiz {
+		system := counting.HiLo
+		// The system is checked by Validate
+		if cfg.CountSystem != "" {
+			system, _ = counting.SystemByName(cfg.CountSystem)
+		}
+
+		countQuiz = newCountQuiz(system)
+		observers = append(observers, countQuiz.tracker)
+	}
+
+	botStrategies := cfg.BotStrategies
+	if len(botStrategies) == 0 {
+		for _, name := range cfg.BotStrategyNames {
+			// The names are checked by Validate
+			strategy, _ := NewStrategy(name, rnd)
+			botStrategies = append(botStrategies, strategy)
+		}
+	}
+
+	// Strategies that count the cards watch the game
+	for _, strategy := range botStrategies {
+		if observer, ok := strategy.(Observer); ok {
+			observers = append(observers, observer)
+		}
+	}
+
+	bj := &Blackjack{
 		deck:                       bjDeck,
-		nextDeckCardIndex:          0,
 		deckOptions:                deckOptions,
+		decks:                      decks,
+		penetration:                cfg.Penetration,
+		shuffled:                   true,
+		rules:                      cfg.Rules,
 		players:                    players,
-		botsNumber:                 cfg.BotsNumber,
+		botsNumber:                 botsNumber,
+		botStrategies:              botStrategies,
+		userInputs:                 userInputs,
 		dealer:                     dealer,
 		isStartingCardsDistributed: false,
 		currentTurnIndex:           1,
 		currentUser:                playerUser,
 		isAllPlayersSaved:          false,
 		isAllSaved:                 false,
-		console:                    cnsl,
-	}, nil
+		input:                      input,
+		out:                        out,
+		cardStyle:                  cardStyle,
+		delay:                      delay,
+		saveFile:                   saveFile,
+		round:                      0,
+		trainer:                    trainer,
+		countQuiz:                  countQuiz,
+		observers:                  observers,
+		closers:                    closers,
+		random:                     rnd,
+	}
+	bj.assignBotStrategies()
+	bj.assignUserInputs()
+
+	return bj, nil
+}
+
+// Give the bots their strategies in the seat order
+func (bj *Blackjack) assignBotStrategies() {
+	i := 0
+
+	for _, player := range bj.players {
+		if !player.Bot {
+			continue
+		}
+
+		player.strategy = nil
+		if i < len(bj.botStrategies) {
+			player.strategy = bj.botStrategies[i]
+		}
+
+		if player.strategy == nil {
+			player.strategy = &NaiveStrategy{random: bj.random}
+		}
+
+		i++
+	}
+}
+
+// Give the users the inputs of their seats in the seat order
+func (bj *Blackjack) assignUserInputs() {
+	i := 0
+
+	for _, player := range bj.players {
+		if player.Bot {
+			continue
+		}
+
+		player.input = nil
+		if i < len(bj.userInputs) {
+			player.input = bj.userInputs[i]
+		}
+
+		if player.input == nil {
+			player.input = bj.input
+		}
+
+		i++
+	}
 }
 
 func (bj *Blackjack) Run() error {
+	if bj.currentUser == nil {
+		return ErrBotsOnlyGame
+	}
+
 	bj.printWelcome()
-	if err := bj.gameLoop(); err != nil {
-		return err
+	err := bj.gameLoop()
+	if errors.Is(err, errExit) {
+		bj.printSummary()
+		return nil
 	}
 
-	return nil
+	return err
 }
 
-func (bj *Blackjack) printWelcome() {
-	fmt.Println(" ___   ___   ___   ___   ___ \n |A  | |K  | |Q  | |J  | |10 |\n |(`)| |(`)| |(`)| |(`)| |(`)|\n |_\\_| |_\\_| |_\\_| |_\\_| |_\\_|")
-	fmt.Println("--- Welcome in the Blackjack Game ---")
-	fmt.Println("-------------------------------------")
+// Trainer
+// Score of the user decisions, nil if the game is not in the trainer mode.
+func (bj *Blackjack) Trainer() *Trainer {
+	return bj.trainer
 }
 
-func (bj *Blackjack) getParticipantCardsPoints(player *Player) (int, error) {
-	points := 0
+func (bj *Blackjack) printSummary() {
+	fmt.Fprintln(bj.out, "\n\n--- Session summary ---")
+	fmt.Fprintf(bj.out, "Rounds: %d\n", bj.round)
+
+	if bj.isHotSeat() {
+		fmt.Fprintln(bj.out, "Money:")
 
-	for i := range player.Cards {
-		playerCard := player.Cards[i]
-		pts, err := getCardCost(playerCard)
+		for _, user := range bj.users() {
+			fmt.Fprintf(bj.out, "  %s: %d\n", user.Name, user.Money)
+		}
+	} else {
+		fmt.Fprintf(bj.out, "Money: %d\n", bj.currentUser.Money)
+	}
+
+	if bj.trainer != nil {
+		fmt.Fprintf(bj.out, "Trainer: %s\n", bj.trainer.Session())
+	}
+
+	if bj.countQuiz != nil {
+		fmt.Fprintf(bj.out, "Count quiz: %s\n", bj.countQuiz)
+	}
 
+	fmt.Fprintln(bj.out, "\nWe are waiting for you again!")
+}
+
+// Simulate
+// Play the given number of rounds without the user input, only in the games with BotsOnly.
+// Stops earlier when all the bots have lost their money.
+func (bj *Blackjack) Simulate(rounds int) error {
+	if bj.currentUser != nil {
+		return ErrUserGame
+	}
+
+	for i := 0; i < rounds && !bj.isAllPlayersLost(); i++ {
+		if err := bj.playBotsRound(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (bj *Blackjack) playBotsRound() error {
+	bj.startRound()
+	if err := bj.betMakerAll(); err != nil {
+		return err
+	}
+
+	err := bj.giveCardsToAll(2)
+	if err != nil {
+		return err
+	}
+	bj.isStartingCardsDistributed = true
+
+	err = bj.printStartingCards()
+	if err != nil {
+		return err
+	}
+
+	for !bj.isAllPlayersSaved {
+		err = bj.stageBots()
 		if err != nil {
-			return -1, err
-		} else {
-			points += pts
+			return err
+		}
+
+		bj.checkAllPlayersSaved()
+	}
+
+	err = bj.stageDealer()
+	if err != nil {
+		return err
+	}
+
+	err = bj.printRoundResults()
+	if err != nil {
+		return err
+	}
+
+	return bj.resetRound()
+}
+
+// Close
+// Close the files opened by the game.
+func (bj *Blackjack) Close() error {
+	var closeErr error
+
+	for _, closer := range bj.closers {
+		if err := closer.Close(); err != nil && closeErr == nil {
+			closeErr = err
 		}
 	}
-	return points, nil
+	bj.closers = nil
+
+	return closeErr
+}
+
+func (bj *Blackjack) printWelcome() {
+	fmt.Fprintln(bj.out, " ___   ___   ___   ___   ___ \n |A  | |K  | |Q  | |J  | |10 |\n |(`)| |(`)| |(`)| |(`)| |(`)|\n |_\\_| |_\\_| |_\\_| |_\\_| |_\\_|")
+	fmt.Fprintln(bj.out, "--- Welcome in the Blackjack Game ---")
+	fmt.Fprintln(bj.out, "-------------------------------------")
+}
+
+func (bj *Blackjack) getParticipantCardsPoints(player *Player) (int, error) {
+	return player.getPoints()
 }
 
 func (bj *Blackjack) printRoundResults() error {
@@ -165,16 +672,14 @@ func (bj *Blackjack) printRoundResults() error {
 		return err
 	}
 
-	fmt.Println("\n\n\n--- Round results: ---")
-	fmt.Printf("\n%s (%d points)", "dealer", dealerPoints)
-	fmt.Println("\nDealer cards:")
-	err = bj.dealer.printAllCards()
+	fmt.Fprintln(bj.out, "\n\n\n--- Round results: ---")
+	fmt.Fprintf(bj.out, "\n%s (%d points)", "dealer", dealerPoints)
+	fmt.Fprintln(bj.out, "\nDealer cards:")
+	err = bj.dealer.printAllCards(bj.out, bj.cardStyle)
 	if err != nil {
 		return err
 	}
-	fmt.Printf("\n\n\n")
-
-	dealerLost := dealerPoints > 21
+	fmt.Fprintf(bj.out, "\n\n\n")
 
 	for _, player := range bj.players {
 		playerPoints, err := player.getPoints()
@@ -184,21 +689,40 @@ func (bj *Blackjack) printRoundResults() error {
 
 		playerName := player.Name
 
-		if player.Id == bj.currentUser.Id {
+		if bj.isSoleUser(player) {
 			playerName = "You"
 		}
 
-		fmt.Printf("%s (%d points): ", playerName, playerPoints)
+		fmt.Fprintf(bj.out, "%s (%d points): ", playerName, playerPoints)
 
-		if (playerPoints > dealerPoints || dealerLost) && playerPoints <= MaxPoints {
-			fmt.Printf("Win!\n")
-			player.Money += player.Bet + player.Bet
-		} else if playerPoints == dealerPoints && playerPoints <= MaxPoints {
-			fmt.Printf("Draw\n")
-			player.Money += player.Bet
-		} else {
-			fmt.Printf("Defeat\n")
+		result, payout, err := bj.rules.Settle(player.Cards, bj.dealer.Cards, player.Bet)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case result == ResultWin && bj.rules.BlackjackPays3To2 && IsNatural(player.Cards):
+			fmt.Fprintf(bj.out, "Blackjack!\n")
+		case result == ResultWin:
+			fmt.Fprintf(bj.out, "Win!\n")
+		case result == ResultDraw:
+			fmt.Fprintf(bj.out, "Draw\n")
+		default:
+			fmt.Fprintf(bj.out, "Defeat\n")
 		}
+
+		player.Money += payout
+
+		bj.emit(Event{
+			Type:          EventSettlement,
+			ParticipantId: player.Id,
+			Name:          player.Name,
+			Points:        playerPoints,
+			Bet:           player.Bet,
+			Payout:        payout,
+			Money:         player.Money,
+			Result:        result,
+		})
 	}
 
 	return nil
@@ -206,21 +730,31 @@ func (bj *Blackjack) printRoundResults() error {
 
 func (bj *Blackjack) resetRound() error {
 	for _, player := range bj.players {
+		bj.deck.Discard(player.Cards...)
 		player.resetRound()
 	}
 
+	bj.deck.Discard(bj.dealer.Cards...)
 	bj.dealer.resetRound()
 
-	bjDeck, err := deck.NewDeck(bj.deckOptions)
-	if err != nil {
-		return err
+	if bj.shouldReshuffle() {
+		bjDeck, err := nextShoe(bj.decks, bj.deckOptions)
+		if err != nil {
+			return err
+		}
+		bj.deck = bjDeck
+		bj.shuffled = true
 	}
-	bj.deck = bjDeck
-	bj.nextDeckCardIndex = 0
 
 	bj.isStartingCardsDistributed = false
+	bj.isAllPlayersSaved = false
+	bj.isAllSaved = false
 	bj.currentTurnIndex = 0
 
+	if bj.trainer != nil {
+		bj.trainer.resetRound()
+	}
+
 	for _, player := range bj.players {
 		player.checkIsLost()
 	}
@@ -228,10 +762,23 @@ func (bj *Blackjack) resetRound() error {
 	return nil
 }
 
+// The shoe is reshuffled at the cut card, or earlier if the cards left may not be enough for a round
+func (bj *Blackjack) shouldReshuffle() bool {
+	if bj.penetration == 0 {
+		return true
+	}
+
+	cutCard := int(bj.penetration * float64(bj.deck.Len()))
+	roundCards := (len(bj.players) + 1) * roundCardsPerSeat
+
+	return bj.deck.Drawn() >= cutCard || bj.deck.Remaining() < roundCards
+}
+
 func (bj *Blackjack) checkAllPlayersSaved() {
 	if !bj.isAllPlayersSaved {
 		for i, player := range bj.players {
-			if !player.IsSaved {
+			// Lost players do not play the round
+			if !player.IsSaved && !player.IsLost {
 				break
 			} else if i == len(bj.players)-1 {
 				bj.isAllPlayersSaved = true
@@ -240,6 +787,57 @@ func (bj *Blackjack) checkAllPlayersSaved() {
 	}
 }
 
+func (bj *Blackjack) isAllPlayersLost() bool {
+	for _, player := range bj.players {
+		if !player.IsLost {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (bj *Blackjack) isAllUsersLost() bool {
+	for _, user := range bj.users() {
+		if !user.IsLost {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Players of the user seats in the dealing order
+func (bj *Blackjack) users() []*Player {
+	var users []*Player
+
+	for _, player := range bj.players {
+		if !player.Bot {
+			users = append(users, player)
+		}
+	}
+
+	return users
+}
+
+// Several users play at one console in turn
+func (bj *Blackjack) isHotSeat() bool {
+	return len(bj.users()) > 1
+}
+
+// The player is addressed as "you" only if he is the one user at the table.
+// In a hot-seat game every user is called by name
+func (bj *Blackjack) isSoleUser(player *Player) bool {
+	return !player.Bot && !bj.isHotSeat()
+}
+
+// Tell the users of a hot-seat game whose input is expected
+func (bj *Blackjack) announceUser(user *Player) {
+	if bj.isHotSeat() {
+		fmt.Fprintf(bj.out, "\n\n%s, it is your turn.", user.Name)
+	}
+}
+
 func (bj *Blackjack) checkAllSaved() {
 	if bj.dealer.IsSaved && bj.isAllPlayersSaved {
 		bj.isAllSaved = true
@@ -256,20 +854,26 @@ func (bj *Blackjack) checkRound() error {
 			return err
 		}
 
+		bj.printTrainerRound()
+
+		if err := bj.askCountQuiz(); err != nil {
+			return err
+		}
+
 		err = bj.resetRound()
 		if err != nil {
 			return err
 		}
 
-		fmt.Println("\n\nPress enter to continue...")
+		fmt.Fprintln(bj.out, "\n\nPress enter to continue...")
 
-		bj.console.Input()
+		bj.input.Input()
 
 		lines := "--------------------------"
-		fmt.Printf("\n\n\n")
-		fmt.Println(lines)
-		fmt.Println("  New Round  ")
-		fmt.Println(lines)
+		fmt.Fprintf(bj.out, "\n\n\n")
+		fmt.Fprintln(bj.out, lines)
+		fmt.Fprintln(bj.out, "  New Round  ")
+		fmt.Fprintln(bj.out, lines)
 	}
 
 	return nil
@@ -280,67 +884,93 @@ func (bj *Blackjack) betMakerBot(bot *Player) {
 		return
 	}
 
-	bet := random.RandInt(0, bot.Money)
+	bet := bot.strategy.Bet(BetState{
+		Round:       bj.round,
+		Money:       bot.Money,
+		Rules:       bj.rules,
+		DecksNumber: bj.deckOptions.DecksNumber,
+	})
+
+	if bet < 0 {
+		bet = 0
+	} else if bet > bot.Money {
+		bet = bot.Money
+	}
 
-	fmt.Printf("\n\nBot %s makes a bet...\n", bot.Name)
-	time.Sleep(Delay)
+	fmt.Fprintf(bj.out, "\n\nBot %s makes a bet...\n", bot.Name)
+	time.Sleep(bj.delay)
 
-	fmt.Printf("An insert of %d coins was made", bet)
+	fmt.Fprintf(bj.out, "An insert of %d coins was made", bet)
 
-	bot.Bet = bet
-	bot.Money -= bet
+	bj.placeBet(bot, bet)
 }
 
-func (bj *Blackjack) betMakerPlayer(player *Player) {
-	fmt.Printf("\nMake your bet (you have %d c.). %s - Exit.", player.Money, ActionExit)
+func (bj *Blackjack) betMakerPlayer(player *Player) error {
+	if player.IsLost {
+		return nil
+	}
+
+	bj.announceUser(player)
+	fmt.Fprintf(bj.out, "\nMake your bet (you have %d c.). %s - Exit.", player.Money, ActionExit)
 	userInput := ""
 
 	for userInput == "" {
-		fmt.Printf("\n>> ")
-		userInput = bj.console.Input()
+		if err := bj.emitPrompt(player, PromptBet); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(bj.out, "\n>> ")
+		userInput = player.input.Input()
 
 		if userInput == string(ActionExit) {
-			fmt.Printf("\nWe are waiting for you again!")
-			os.Exit(0)
+			return errExit
 		}
 
 		bet, err := strconv.Atoi(userInput)
 
 		if bet > player.Money {
-			fmt.Println("You don't have that many coins")
+			fmt.Fprintln(bj.out, "You don't have that many coins")
 			userInput = ""
 		} else if err != nil || bet <= 0 {
-			fmt.Println("Incorrect input")
+			fmt.Fprintln(bj.out, "Incorrect input")
 			userInput = ""
 		} else {
-			player.Bet = bet
-			player.Money -= bet
+			bj.placeBet(player, bet)
 		}
 	}
+
+	return nil
 }
 
-func (bj *Blackjack) betMakerAll() {
+func (bj *Blackjack) placeBet(player *Player, bet int) {
+	player.Bet = bet
+	player.Money -= bet
+
+	bj.emit(Event{
+		Type:          EventBet,
+		ParticipantId: player.Id,
+		Name:          player.Name,
+		Bet:           player.Bet,
+		Money:         player.Money,
+	})
+}
+
+func (bj *Blackjack) betMakerAll() error {
 	for _, player := range bj.players {
 		if player.Bot {
 			bj.betMakerBot(player)
-		} else {
-			bj.betMakerPlayer(player)
+		} else if err := bj.betMakerPlayer(player); err != nil {
+			return err
 		}
 	}
 
-	fmt.Printf("\n\nBets are made!\n\n")
-}
+	fmt.Fprintf(bj.out, "\n\nBets are made!\n\n")
 
-func (bj *Blackjack) increaseNextDeckCardIndex() {
-	bj.nextDeckCardIndex++
-}
-
-func (bj *Blackjack) getActualDeckCardsCount() int {
-	return len(bj.deck) - bj.nextDeckCardIndex
+	return nil
 }
 
 func (bj *Blackjack) isValidCardsNumber(cardsNumber int) bool {
-	return cardsNumber > 0 && cardsNumber <= bj.getActualDeckCardsCount()
+	return cardsNumber > 0 && cardsNumber <= bj.deck.Remaining()
 }
 
 func (bj *Blackjack) giveCardToPlayer(player *Player, cardsNumber int) (*deck.Card, error) {
@@ -352,15 +982,13 @@ func (bj *Blackjack) giveCardToPlayer(player *Player, cardsNumber int) (*deck.Ca
 		return nil, fmt.Errorf("invalid cards number")
 	}
 
-	playerCardsNumber := len(player.Cards)
-
-	for i := playerCardsNumber; i < cardsNumber+playerCardsNumber; i++ {
-		player.Cards = append(player.Cards, bj.deck[bj.nextDeckCardIndex])
-		bj.increaseNextDeckCardIndex()
+	cards, err := bj.deck.Draw(cardsNumber)
+	if err != nil {
+		return nil, err
 	}
+	player.Cards = append(player.Cards, cards...)
 
-	receivedCard := player.Cards[len(player.Cards)-cardsNumber:]
-	return receivedCard[0], nil
+	return cards[0], nil
 }
 
 func (bj *Blackjack) giveCardToDealer(cardsNumber int) (*deck.Card, error) {
@@ -368,15 +996,13 @@ func (bj *Blackjack) giveCardToDealer(cardsNumber int) (*deck.Card, error) {
 		return nil, fmt.Errorf("invalid cards number")
 	}
 
-	dealerCardsNumber := len(bj.dealer.Cards)
-
-	for i := dealerCardsNumber; i < cardsNumber+dealerCardsNumber; i++ {
-		bj.dealer.Cards = append(bj.dealer.Cards, bj.deck[bj.nextDeckCardIndex])
-		bj.increaseNextDeckCardIndex()
+	cards, err := bj.deck.Draw(cardsNumber)
+	if err != nil {
+		return nil, err
 	}
+	bj.dealer.Cards = append(bj.dealer.Cards, cards...)
 
-	receivedCard := bj.dealer.Cards[len(bj.dealer.Cards)-cardsNumber:]
-	return receivedCard[0], nil
+	return cards[0], nil
 }
 
 func (bj *Blackjack) giveCardsToAll(cardsNumber int) error {
@@ -389,51 +1015,60 @@ func (bj *Blackjack) giveCardsToAll(cardsNumber int) error {
 		if err != nil {
 			return err
 		}
+
+		err = bj.emitPlayerEvent(EventDeal, player, player.Cards[len(player.Cards)-cardsNumber:]...)
+		if err != nil {
+			return err
+		}
 	}
 
-	_, err := bj.giveCardToDealer(cardsNumber)
+	dealerCardsNumber := cardsNumber
+	if bj.rules.NoHoleCard && dealerCardsNumber > 1 {
+		dealerCardsNumber--
+	}
+
+	_, err := bj.giveCardToDealer(dealerCardsNumber)
 	if err != nil {
 		return err
 	}
-	return nil
+
+	// The rest of the dealer cards are hidden until his turn
+	return bj.emitDealerEvent(EventDeal, bj.dealer.Cards[0])
 }
 
 func (bj *Blackjack) printStartingCards() error {
-	fmt.Printf("\nThe following cards were dealt:")
+	fmt.Fprintf(bj.out, "\nThe following cards were dealt:")
 
 	for _, player := range bj.players {
 		playerName := player.Name
 
-		cardsPoints := 0
-
-		if player.Id == bj.currentUser.Id {
+		if bj.isSoleUser(player) {
 			playerName = "Your cards"
 		}
 
-		printPlayerName(playerName)
+		printPlayerName(bj.out, playerName)
 
 		for _, card := range player.Cards {
-			cost, err := getCardCost(card)
-			if err != nil {
-				return err
-			} else {
-				cardsPoints += cost
-			}
-			err = printCard(card)
+			err := printCard(bj.out, bj.cardStyle, card)
 			if err != nil {
 				return err
 			}
 		}
 
-		printTotalPoints(cardsPoints)
-		time.Sleep(Delay)
+		cardsPoints, err := player.getPoints()
+		if err != nil {
+			return err
+		}
+
+		printTotalPoints(bj.out, cardsPoints)
+		time.Sleep(bj.delay)
 	}
 
 	// --- We print only the first card at the dealer
 
-	printPlayerName("dealer")
+	printPlayerName(bj.out, "dealer")
 	openedDealerCard := bj.dealer.Cards[0]
-	err := printCard(openedDealerCard)
+	err := printCard(bj.out, bj.cardStyle, openedDealerCard)
 	if err != nil {
 		return err
 	}
@@ -441,21 +1076,21 @@ func (bj *Blackjack) printStartingCards() error {
 	if err != nil {
 		return err
 	}
-	printTotalPoints(cardCost)
+	printTotalPoints(bj.out, cardCost)
 
-	time.Sleep(Delay)
+	time.Sleep(bj.delay)
 
 	return nil
 }
 
 func (bj *Blackjack) printNewTurn() error {
-	fmt.Println("\n\n-----------------------------")
+	fmt.Fprintln(bj.out, "\n\n-----------------------------")
 	points, err := bj.getParticipantCardsPoints(bj.currentUser)
 	if err != nil {
 		return err
 	}
 	if !bj.currentUser.IsSaved {
-		fmt.Printf("Turn: %d. Points: %d", bj.currentTurnIndex, points)
+		fmt.Fprintf(bj.out, "Turn: %d. Points: %d", bj.currentTurnIndex, points)
 	}
 
 	return nil
@@ -470,19 +1105,21 @@ func (bj *Blackjack) dealerSaved() {
 }
 
 func (bj *Blackjack) printPlayerCards(player *Player) error {
-	totalPoints := 0
-
 	for _, card := range player.Cards {
 		cost, err := getCardCost(card)
 		if err != nil {
 			return err
 		} else {
-			totalPoints += cost
-			fmt.Printf("%s %s. Give %d pts\n", card.Suit, card.Value, cost)
+			fmt.Fprintf(bj.out, "%s. Give %d pts\n", card.Format(bj.cardStyle), cost)
 		}
 	}
 
-	fmt.Printf("Total: %d\n", totalPoints)
+	totalPoints, err := player.getPoints()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(bj.out, "Total: %d\n", totalPoints)
 	return nil
 }
 
@@ -498,88 +1135,178 @@ func (bj *Blackjack) onUserInput(userInput string) (bool, error) {
 	switch userInput {
 	case string(ActionExit):
 		{
-			fmt.Printf("\nWaiting you again!")
-			os.Exit(0)
+			return false, errExit
 		}
 
 	case string(ActionTakeCard):
 		{
+			if err := bj.trainMove(MoveHit); err != nil {
+				return false, err
+			}
+
 			receivedCard, err := bj.giveCardToPlayer(bj.currentUser, 1)
 			if err != nil {
 				return false, err
 			}
 			points, err := getCardCost(receivedCard)
 			if err != nil {
-				fmt.Println("error when getting card points: %w", err)
+				return false, fmt.Errorf("error when getting card points: %w", err)
 			}
-			fmt.Printf("\nYou took the card %s %s. Give %d pts\n", receivedCard.Suit, receivedCard.Value, points)
-			return true, nil
+			fmt.Fprintf(bj.out, "\nYou took the card %s. Give %d pts\n", receivedCard.Format(bj.cardStyle), points)
+			err = bj.emitPlayerEvent(EventHit, bj.currentUser, receivedCard)
+			return true, err
 		}
 
 	case string(ActionPass):
 		{
-			fmt.Printf("\nYou saved\n\n")
+			if err := bj.trainMove(MoveStand); err != nil {
+				return false, err
+			}
+
+			fmt.Fprintf(bj.out, "\nYou saved\n\n")
 			bj.playerSaved(bj.currentUser)
-			return true, nil
+			err := bj.emitPlayerEvent(EventStand, bj.currentUser)
+			return true, err
 		}
 
 	case string(ActionViewMyCards):
 		{
-			fmt.Println("\nYour cards:")
+			fmt.Fprintln(bj.out, "\nYour cards:")
 			err := bj.printPlayerCards(bj.currentUser)
 			return false, err
 		}
 
+	case string(ActionHint):
+		{
+			err := bj.printHint()
+			return false, err
+		}
+
+	case string(ActionAnalyze):
+		{
+			err := bj.printAnalysis()
+			return false, err
+		}
+
+	case string(ActionSave):
+		{
+			if err := bj.SaveFile(bj.saveFile); err != nil {
+				fmt.Fprintf(bj.out, "\nCould not save the game: %v\n", err)
+				return false, nil
+			}
+			fmt.Fprintf(bj.out, "\nThe game is saved to %s\n", bj.saveFile)
+			return false, nil
+		}
+
+	case string(ActionLoad):
+		{
+			if err := bj.LoadFile(bj.saveFile); err != nil {
+				fmt.Fprintf(bj.out, "\nCould not load the game: %v\n", err)
+				return false, nil
+			}
+			fmt.Fprintf(bj.out, "\nThe game is loaded from %s\n", bj.saveFile)
+			err := bj.printNewTurn()
+			return false, err
+		}
+
 	default:
 		{
-			fmt.Println("Incorrect input")
+			fmt.Fprintln(bj.out, "Incorrect input")
 			return false, nil
 		}
 	}
-
-	return true, nil
 }
 
 func (bj *Blackjack) botTurn(bot *Player) error {
-	botPoints, err := bj.getParticipantCardsPoints(bot)
+	botPoints, soft, err := CardsPoints(bot.Cards)
 	if err != nil {
 		return err
 	}
 
-	if botPoints < 15 {
-		fmt.Println("\nTake card...")
-		time.Sleep(Delay)
+	// A busted hand can only stand
+	moves := legalMoves
+	if botPoints > MaxPoints {
+		moves = []Move{MoveStand}
+	}
+
+	move := bot.strategy.Decide(HandState{
+		Cards:       bot.Cards,
+		Points:      botPoints,
+		Soft:        soft,
+		Bet:         bot.Bet,
+		Money:       bot.Money,
+		Rules:       bj.rules,
+		DecksNumber: bj.deckOptions.DecksNumber,
+	}, bj.dealer.Cards[0], moves)
+
+	if move == MoveHit {
+		fmt.Fprintln(bj.out, "\nTake card...")
+		time.Sleep(bj.delay)
 		card, err := bj.giveCardToPlayer(bot, 1)
 		if err != nil {
 			return err
 		}
 
-		err = printCard(card)
+		err = printCard(bj.out, bj.cardStyle, card)
 		if err != nil {
 			return err
 		}
-		fmt.Printf("\n\n\n")
+		fmt.Fprintf(bj.out, "\n\n\n")
+
+		return bj.emitPlayerEvent(EventHit, bot, card)
 	} else {
-		fmt.Println("\nSaved")
+		fmt.Fprintln(bj.out, "\nSaved")
 		bj.playerSaved(bot)
-	}
 
-	return nil
+		return bj.emitPlayerEvent(EventStand, bot)
+	}
 }
 
-func (bj *Blackjack) stageBots() error {
-	// Отсекаем первого игрока, поскольку это пользователь
-	bots := bj.players[1:]
+// Every player who has not saved makes a move in the dealing order, the users with their input
+func (bj *Blackjack) stagePlayers() error {
+	// Loading a game replaces the players, so they are taken by the index
+	for i := 0; i < len(bj.players); i++ {
+		player := bj.players[i]
 
-	for _, bot := range bots {
-		if bot.IsSaved == true || bot.IsLost {
+		if player.IsSaved || player.IsLost {
 			continue
 		}
 
-		fmt.Printf("\nBot`s turn: %s...", bot.Name)
+		if player.Bot {
+			if err := bj.stageBot(player); err != nil {
+				return err
+			}
+			continue
+		}
+
+		bj.currentUser = player
+		if err := bj.userTurn(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (bj *Blackjack) userTurn() error {
+	bj.announceUser(bj.currentUser)
 
-		time.Sleep(Delay)
-		err := bj.botTurn(bot)
+	err := bj.printNewTurn()
+	if err != nil {
+		return err
+	}
+
+	inputRes := false
+
+	for !inputRes && !bj.currentUser.IsSaved {
+		fmt.Fprintf(bj.out, "\nMoves:\n%s - Take card. %s - Save. %s - Your card. %s - Hint. %s - Odds. %s - Save game. %s - Load game. %s - exit.", ActionTakeCard, ActionPass, ActionViewMyCards, ActionHint, ActionAnalyze, ActionSave, ActionLoad, ActionExit)
+		if err := bj.emitPrompt(bj.currentUser, PromptMove); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(bj.out, "\n>> ")
+		userInput := bj.currentUser.input.Input()
+		inputRes, err = bj.onUserInput(userInput)
 		if err != nil {
 			return err
 		}
@@ -588,6 +1315,28 @@ func (bj *Blackjack) stageBots() error {
 	return nil
 }
 
+func (bj *Blackjack) stageBot(bot *Player) error {
+	fmt.Fprintf(bj.out, "\nBot`s turn: %s...", bot.Name)
+
+	time.Sleep(bj.delay)
+
+	return bj.botTurn(bot)
+}
+
+func (bj *Blackjack) stageBots() error {
+	for _, bot := range bj.players {
+		if !bot.Bot || bot.IsSaved == true || bot.IsLost {
+			continue
+		}
+
+		if err := bj.stageBot(bot); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (bj *Blackjack) stageDealer() error {
 	if bj.dealer.IsSaved {
 		return fmt.Errorf("dealer is saved")
@@ -597,36 +1346,50 @@ func (bj *Blackjack) stageDealer() error {
 		return nil
 	}
 
-	fmt.Println("\n\nDealer`s turn...")
+	fmt.Fprintln(bj.out, "\n\nDealer`s turn...")
+
+	err := bj.emitDealerEvent(EventDealerReveal, bj.dealer.Cards[1:]...)
+	if err != nil {
+		return err
+	}
 
 	for {
-		time.Sleep(Delay)
+		time.Sleep(bj.delay)
 
-		dealerPoints, err := bj.dealer.getPoints()
+		takeCard, err := bj.dealerShouldTakeCard()
 		if err != nil {
 			return err
 		}
 
-		if dealerPoints <= DealerPointsTakeCardLimit {
-			fmt.Println("Dealer takes a card")
+		if takeCard {
+			fmt.Fprintln(bj.out, "Dealer takes a card")
 			card, err := bj.giveCardToDealer(1)
 			if err != nil {
 				return err
 			}
 
-			err = printCard(card)
+			err = printCard(bj.out, bj.cardStyle, card)
+			if err != nil {
+				return err
+			}
+
+			err = bj.emitDealerEvent(EventDealerHit, card)
 			if err != nil {
 				return err
 			}
 		} else {
-			fmt.Printf("Dealer not takes a card")
+			fmt.Fprintf(bj.out, "Dealer not takes a card")
 			bj.dealerSaved()
 
-			return nil
+			return bj.emitDealerEvent(EventDealerStand)
 		}
 	}
 }
 
+func (bj *Blackjack) dealerShouldTakeCard() (bool, error) {
+	return bj.rules.DealerTakesCard(bj.dealer.Cards)
+}
+
 func (bj *Blackjack) gameLoop() error {
 	for {
 		err := bj.checkRound()
@@ -635,7 +1398,15 @@ func (bj *Blackjack) gameLoop() error {
 		}
 
 		if !bj.isStartingCardsDistributed {
-			bj.betMakerAll()
+			if bj.isAllUsersLost() {
+				fmt.Fprintln(bj.out, "\nNo money is left to play")
+				return errExit
+			}
+
+			bj.startRound()
+			if err := bj.betMakerAll(); err != nil {
+				return err
+			}
 			err := bj.giveCardsToAll(2)
 			if err != nil {
 				return err
@@ -649,24 +1420,7 @@ func (bj *Blackjack) gameLoop() error {
 			}
 		}
 
-		err = bj.printNewTurn()
-		if err != nil {
-			return err
-		}
-
-		inputRes := false
-
-		for !inputRes && !bj.currentUser.IsSaved {
-			fmt.Printf("\nMoves:\n%s - Take card. %s - Save. %s - Your card. %s - exit.", ActionTakeCard, ActionPass, ActionViewMyCards, ActionExit)
-			fmt.Printf("\n>> ")
-			userInput := bj.console.Input()
-			inputRes, err = bj.onUserInput(userInput)
-			if err != nil {
-				return err
-			}
-		}
-
-		err = bj.stageBots()
+		err = bj.stagePlayers()
 		if err != nil {
 			return err
 		}
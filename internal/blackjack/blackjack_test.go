@@ -1,9 +1,14 @@
 package blackjack
 
 import (
+	"bytes"
+	"course/internal/counting"
 	"course/internal/deck"
+	"course/internal/odds"
 	"course/pkg/random"
 	"github.com/stretchr/testify/require"
+	"io"
+	"strings"
 	"testing"
 )
 
@@ -134,6 +139,60 @@ func TestNewBlackjack(t *testing.T) {
 				require.Nil(t, b)
 			},
 		},
+		{
+			name: "Invalid Decks Number",
+			config: Config{
+				PlayersStartingMoney: cfg.PlayersStartingMoney,
+				BotsNumber:           cfg.BotsNumber,
+				Username:             cfg.Username,
+				DecksNumber:          MaxDecksNumber + 1,
+			},
+			check: func(b *Blackjack, err error, c Config) {
+				require.EqualError(t, err, ErrInvalidDecksNumber.Error())
+				require.Nil(t, b)
+			},
+		},
+		{
+			name: "Max Decks Number",
+			config: Config{
+				PlayersStartingMoney: cfg.PlayersStartingMoney,
+				BotsNumber:           cfg.BotsNumber,
+				Username:             cfg.Username,
+				DecksNumber:          MaxDecksNumber,
+			},
+			check: func(b *Blackjack, err error, c Config) {
+				require.NoError(t, err)
+				require.Equal(t, 52*MaxDecksNumber, b.deck.Len())
+				require.NoError(t, b.deck.Validate(b.deckOptions))
+			},
+		},
+		{
+			name: "Negative Delay",
+			config: Config{
+				PlayersStartingMoney: cfg.PlayersStartingMoney,
+				BotsNumber:           cfg.BotsNumber,
+				Username:             cfg.Username,
+				Delay:                -1,
+			},
+			check: func(b *Blackjack, err error, c Config) {
+				require.EqualError(t, err, ErrNegativeDelay.Error())
+				require.Nil(t, b)
+			},
+		},
+		{
+			name: "No Delay",
+			config: Config{
+				PlayersStartingMoney: cfg.PlayersStartingMoney,
+				BotsNumber:           cfg.BotsNumber,
+				Username:             cfg.Username,
+				Delay:                LongDelay,
+				NoDelay:              true,
+			},
+			check: func(b *Blackjack, err error, c Config) {
+				require.NoError(t, err)
+				require.Zero(t, b.delay)
+			},
+		},
 		{
 			name:   "Full Invalid Config",
 			config: Config{},
@@ -331,7 +390,7 @@ func TestBlackjack_giveCardsToAll(t *testing.T) {
 			number: 1,
 			check: func(b *Blackjack, err error, number int) {
 				require.NoError(t, err)
-				require.Equal(t, 48, b.getActualDeckCardsCount())
+				require.Equal(t, 48, b.deck.Remaining())
 			},
 		},
 		{
@@ -344,7 +403,7 @@ func TestBlackjack_giveCardsToAll(t *testing.T) {
 			number: 1,
 			check: func(b *Blackjack, err error, number int) {
 				require.NoError(t, err)
-				require.Equal(t, 41, b.getActualDeckCardsCount())
+				require.Equal(t, 41, b.deck.Remaining())
 			},
 		},
 		{
@@ -357,7 +416,7 @@ func TestBlackjack_giveCardsToAll(t *testing.T) {
 			number: 1,
 			check: func(b *Blackjack, err error, number int) {
 				require.NoError(t, err)
-				require.Equal(t, 49, b.getActualDeckCardsCount())
+				require.Equal(t, 49, b.deck.Remaining())
 			},
 		},
 		{
@@ -370,7 +429,7 @@ func TestBlackjack_giveCardsToAll(t *testing.T) {
 			number: 13,
 			check: func(b *Blackjack, err error, number int) {
 				require.NoError(t, err)
-				require.Equal(t, 0, b.getActualDeckCardsCount())
+				require.Equal(t, 0, b.deck.Remaining())
 			},
 		},
 		{
@@ -379,7 +438,7 @@ func TestBlackjack_giveCardsToAll(t *testing.T) {
 			number: 100000,
 			check: func(b *Blackjack, err error, number int) {
 				require.Error(t, err)
-				require.Equal(t, 52, b.getActualDeckCardsCount())
+				require.Equal(t, 52, b.deck.Remaining())
 			},
 		},
 		{
@@ -388,7 +447,7 @@ func TestBlackjack_giveCardsToAll(t *testing.T) {
 			number: -100000,
 			check: func(b *Blackjack, err error, number int) {
 				require.Error(t, err)
-				require.Equal(t, 52, b.getActualDeckCardsCount())
+				require.Equal(t, 52, b.deck.Remaining())
 			},
 		},
 	}
@@ -411,3 +470,625 @@ func TestBlackjack_giveCardsToAll(t *testing.T) {
 		})
 	}
 }
+
+func TestBlackjack_dealerShouldTakeCard(t *testing.T) {
+	cfg := getValidTestCfg()
+
+	testCases := []struct {
+		name  string
+		rules Rules
+		cards []*deck.Card
+		check func(takeCard bool, err error)
+	}{
+		{
+			name:  "Sixteen",
+			cards: deck.MustParseCards("Kh 6s"),
+			check: func(takeCard bool, err error) {
+				require.NoError(t, err)
+				require.True(t, takeCard)
+			},
+		},
+		{
+			name:  "Hard Seventeen",
+			rules: Rules{DealerHitsSoft17: true},
+			cards: deck.MustParseCards("Kh 7s"),
+			check: func(takeCard bool, err error) {
+				require.NoError(t, err)
+				require.False(t, takeCard)
+			},
+		},
+		{
+			name:  "Soft Seventeen Stands",
+			cards: deck.MustParseCards("Ah 6s"),
+			check: func(takeCard bool, err error) {
+				require.NoError(t, err)
+				require.False(t, takeCard)
+			},
+		},
+		{
+			name:  "Soft Seventeen Hits",
+			rules: Rules{DealerHitsSoft17: true},
+			cards: deck.MustParseCards("Ah 6s"),
+			check: func(takeCard bool, err error) {
+				require.NoError(t, err)
+				require.True(t, takeCard)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			cfg.Rules = tc.rules
+
+			b, err := NewBlackjack(cfg)
+			require.NoError(t, err)
+			b.dealer.Cards = tc.cards
+
+			tc.check(b.dealerShouldTakeCard())
+		})
+	}
+}
+
+// All the orders of the cards
+func getTestPermutations(cards []*deck.Card) [][]*deck.Card {
+	if len(cards) <= 1 {
+		return [][]*deck.Card{cards}
+	}
+
+	var permutations [][]*deck.Card
+
+	for i := range cards {
+		rest := append(append([]*deck.Card{}, cards[:i]...), cards[i+1:]...)
+
+		for _, permutation := range getTestPermutations(rest) {
+			permutations = append(permutations, append([]*deck.Card{cards[i]}, permutation...))
+		}
+	}
+
+	return permutations
+}
+
+func TestBlackjack_stageDealerOutcomes(t *testing.T) {
+	testCases := []struct {
+		name   string
+		rules  Rules
+		upCard deck.Rank
+		unseen []deck.Rank
+	}{
+		{
+			name:   "Six",
+			upCard: deck.Six,
+			unseen: []deck.Rank{deck.King, deck.Five, deck.Four, deck.Ace, deck.Two},
+		},
+		{
+			name:   "Ace Stands Soft 17",
+			upCard: deck.Ace,
+			unseen: []deck.Rank{deck.Six, deck.Four, deck.Four, deck.King, deck.Two},
+		},
+		{
+			name:   "Ace Hits Soft 17",
+			rules:  Rules{DealerHitsSoft17: true},
+			upCard: deck.Ace,
+			unseen: []deck.Rank{deck.Six, deck.Four, deck.Four, deck.King, deck.Two},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := getValidTestCfg()
+			cfg.Rules = tc.rules
+			cfg.NoDelay = true
+			cfg.Output = io.Discard
+
+			b, err := NewBlackjack(cfg)
+			require.NoError(t, err)
+
+			upCard := deck.MustNewCard(deck.Spade, tc.upCard)
+			unseen := getTestCards(tc.unseen...)
+			permutations := getTestPermutations(unseen)
+			finals := make(map[int]int)
+
+			// Every order of the unseen cards is equally likely, the first one is the hole card
+			for _, permutation := range permutations {
+				b.dealer.resetRound()
+				b.dealer.Cards = []*deck.Card{upCard, permutation[0]}
+				b.deck = deck.FromCards(permutation[1:])
+				b.isAllPlayersSaved = true
+
+				require.NoError(t, b.stageDealer())
+
+				points, _, err := CardsPoints(b.dealer.Cards)
+				require.NoError(t, err)
+				finals[points]++
+			}
+
+			shoe, err := odds.ShoeOf(unseen)
+			require.NoError(t, err)
+
+			outcomes, err := odds.NewCalculator(tc.rules.oddsRules()).OutcomesOf(upCard, shoe)
+			require.NoError(t, err)
+
+			busts := 0
+			for points, count := range finals {
+				if points > MaxPoints {
+					busts += count
+					continue
+				}
+				require.InDelta(t, outcomes.Probability(points), float64(count)/float64(len(permutations)), 1e-9, "points %d", points)
+			}
+			require.InDelta(t, outcomes.Bust, float64(busts)/float64(len(permutations)), 1e-9)
+		})
+	}
+}
+
+func TestBlackjack_printRoundResults(t *testing.T) {
+	cfg := getValidTestCfg()
+	cfg.BotsNumber = MinBotsNumber
+
+	natural := deck.MustParseCards("Ah Ks")
+	twentyOne := deck.MustParseCards("7h 7s 7c")
+	twenty := deck.MustParseCards("Qh Ks")
+
+	testCases := []struct {
+		name        string
+		rules       Rules
+		playerCards []*deck.Card
+		dealerCards []*deck.Card
+		money       int
+	}{
+		{
+			name:        "Win",
+			playerCards: twentyOne,
+			dealerCards: twenty,
+			money:       1010,
+		},
+		{
+			name:        "Draw",
+			playerCards: twenty,
+			dealerCards: twenty,
+			money:       1000,
+		},
+		{
+			name:        "Defeat",
+			playerCards: twenty,
+			dealerCards: twentyOne,
+			money:       990,
+		},
+		{
+			name:        "Natural Pays 1 To 1",
+			playerCards: natural,
+			dealerCards: twenty,
+			money:       1010,
+		},
+		{
+			name:        "Natural Pays 3 To 2",
+			rules:       Rules{BlackjackPays3To2: true},
+			playerCards: natural,
+			dealerCards: twenty,
+			money:       1015,
+		},
+		{
+			name:        "Natural Beats 21",
+			rules:       Rules{BlackjackPays3To2: true},
+			playerCards: natural,
+			dealerCards: twentyOne,
+			money:       1015,
+		},
+		{
+			name:        "Dealer Natural Beats 21",
+			rules:       Rules{BlackjackPays3To2: true},
+			playerCards: twentyOne,
+			dealerCards: natural,
+			money:       990,
+		},
+		{
+			name:        "Both Naturals",
+			rules:       Rules{BlackjackPays3To2: true},
+			playerCards: natural,
+			dealerCards: natural,
+			money:       1000,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			cfg.Rules = tc.rules
+
+			b, err := NewBlackjack(cfg)
+			require.NoError(t, err)
+			b.out = io.Discard
+
+			b.placeBet(b.currentUser, 10)
+			b.currentUser.Cards = tc.playerCards
+			b.dealer.Cards = tc.dealerCards
+
+			require.NoError(t, b.printRoundResults())
+			require.Equal(t, tc.money, b.currentUser.Money)
+		})
+	}
+}
+
+func TestBlackjack_giveCardsToAllNoHoleCard(t *testing.T) {
+	cfg := getValidTestCfg()
+	cfg.Rules.NoHoleCard = true
+
+	b, err := NewBlackjack(cfg)
+	require.NoError(t, err)
+
+	require.NoError(t, b.giveCardsToAll(2))
+	require.Len(t, b.dealer.Cards, 1)
+	require.Len(t, b.currentUser.Cards, 2)
+	require.Equal(t, 52-2*len(b.players)-1, b.deck.Remaining())
+}
+
+func TestConfig_Validate(t *testing.T) {
+	cfg := getValidTestCfg()
+
+	testCases := []struct {
+		name   string
+		config func() Config
+		err    error
+	}{
+		{
+			name:   "Ok",
+			config: func() Config { return cfg },
+			err:    nil,
+		},
+		{
+			name: "Default Decks Number",
+			config: func() Config {
+				c := cfg
+				c.DecksNumber = 0
+				return c
+			},
+			err: nil,
+		},
+		{
+			name: "Negative Decks Number",
+			config: func() Config {
+				c := cfg
+				c.DecksNumber = -1
+				return c
+			},
+			err: ErrInvalidDecksNumber,
+		},
+		{
+			name: "Empty Username",
+			config: func() Config {
+				c := cfg
+				c.Username = ""
+				return c
+			},
+			err: ErrEmptyUsername,
+		},
+		{
+			name: "Unknown Count System",
+			config: func() Config {
+				c := cfg
+				c.CountSystem = "zen"
+				return c
+			},
+			err: counting.ErrUnknownSystem,
+		},
+		{
+			name: "Dealer Username",
+			config: func() Config {
+				c := cfg
+				c.Username = "dealer"
+				return c
+			},
+			err: ErrReservedName,
+		},
+		{
+			name: "Dealer Seat Name",
+			config: func() Config {
+				c := cfg
+				c.Seats = []SeatConfig{{Name: "Alex"}, {Name: " Dealer", Bot: true}}
+				return c
+			},
+			err: ErrReservedName,
+		},
+		{
+			name: "Bots Only Without Username",
+			config: func() Config {
+				c := cfg
+				c.Username = ""
+				c.BotsOnly = true
+				return c
+			},
+			err: nil,
+		},
+		{
+			name: "Hot-Seat Without Bots",
+			config: func() Config {
+				c := cfg
+				c.Seats = []SeatConfig{{Name: "Alex"}, {Name: "Sam"}}
+				return c
+			},
+			err: nil,
+		},
+		{
+			name: "Seat Without Name",
+			config: func() Config {
+				c := cfg
+				c.Seats = []SeatConfig{{Name: "Alex"}, {Bot: true}, {}}
+				return c
+			},
+			err: ErrEmptyUsername,
+		},
+		{
+			name: "Duplicate Seat Names",
+			config: func() Config {
+				c := cfg
+				c.Seats = []SeatConfig{{Name: "Alex"}, {Name: "Alex", Bot: true}}
+				return c
+			},
+			err: ErrDuplicateSeatName,
+		},
+		{
+			name: "Too Many Seats",
+			config: func() Config {
+				c := cfg
+				c.Seats = make([]SeatConfig, MaxPlayers+1)
+				for i := range c.Seats {
+					c.Seats[i].Bot = true
+				}
+				return c
+			},
+			err: ErrTooManySeats,
+		},
+		{
+			name: "More Bot Strategies Than Bot Seats",
+			config: func() Config {
+				c := cfg
+				c.Seats = []SeatConfig{{Name: "Alex"}, {Bot: true}}
+				c.BotStrategies = []Strategy{&BasicStrategy{}, &BasicStrategy{}}
+				return c
+			},
+			err: ErrTooManyBotStrategies,
+		},
+		{
+			name: "Unknown Card Style",
+			config: func() Config {
+				c := cfg
+				c.CardStyle = "braille"
+				return c
+			},
+			err: deck.ErrInvalidStyle,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config().Validate()
+			if tc.err == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tc.err)
+			}
+		})
+	}
+}
+
+func TestBlackjack_Simulate(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config func() Config
+		rounds int
+		check  func(b *Blackjack, history string, err error)
+	}{
+		{
+			name: "Bots Only",
+			config: func() Config {
+				cfg := getValidTestCfg()
+				cfg.Username = ""
+				cfg.BotsOnly = true
+				cfg.BotsNumber = 4
+				return cfg
+			},
+			rounds: 20,
+			check: func(b *Blackjack, history string, err error) {
+				require.NoError(t, err)
+				require.Len(t, b.players, 4)
+				require.Equal(t, 20, b.round)
+				require.Equal(t, 20, strings.Count(history, "*** SETTLEMENT ***"))
+				require.False(t, b.isAllPlayersSaved)
+				require.Empty(t, b.dealer.Cards)
+
+				hands, err := ParseHandHistory(strings.NewReader(history))
+				require.NoError(t, err)
+
+				for _, hand := range hands {
+					require.NoError(t, VerifyHand(hand))
+				}
+			},
+		},
+		{
+			name: "User Seat",
+			config: func() Config {
+				return getValidTestCfg()
+			},
+			rounds: 1,
+			check: func(b *Blackjack, history string, err error) {
+				require.ErrorIs(t, err, ErrUserGame)
+				require.Empty(t, history)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := tc.config()
+			cfg.NoDelay = true
+			cfg.Output = io.Discard
+
+			var buf bytes.Buffer
+			cfg.Observers = []Observer{NewHandHistory(&buf, cfg)}
+
+			b, err := NewBlackjack(cfg)
+			require.NoError(t, err)
+
+			err = b.Simulate(tc.rounds)
+			tc.check(b, buf.String(), err)
+		})
+	}
+}
+
+func TestBlackjack_SimulateConcurrentSeeded(t *testing.T) {
+	const games = 4
+
+	outputs := make([]bytes.Buffer, games)
+	errs := make(chan error, games)
+
+	// Every game has its own random source, the games of the same seed are the same
+	for i := 0; i < games; i++ {
+		go func(out *bytes.Buffer) {
+			cfg := getValidTestCfg()
+			cfg.BotsOnly = true
+			cfg.NoDelay = true
+			cfg.Seed = 7
+			cfg.Output = out
+
+			b, err := NewBlackjack(cfg)
+			if err == nil {
+				err = b.Simulate(20)
+			}
+			errs <- err
+		}(&outputs[i])
+	}
+
+	for i := 0; i < games; i++ {
+		require.NoError(t, <-errs)
+	}
+
+	for i := 1; i < games; i++ {
+		require.Equal(t, outputs[0].String(), outputs[i].String())
+	}
+}
+
+func TestNewBlackjack_SeededIds(t *testing.T) {
+	cfg := getValidTestCfg()
+	cfg.BotsOnly = true
+	cfg.Seed = 7
+
+	first, err := NewBlackjack(cfg)
+	require.NoError(t, err)
+	second, err := NewBlackjack(cfg)
+	require.NoError(t, err)
+
+	// The Ids are taken from the random source of the game
+	require.Equal(t, first.dealer.Id, second.dealer.Id)
+	require.Equal(t, len(first.players), len(second.players))
+	for i, player := range first.players {
+		require.Equal(t, player.Id, second.players[i].Id)
+	}
+}
+
+func TestBlackjack_RunBotsOnly(t *testing.T) {
+	cfg := getValidTestCfg()
+	cfg.BotsOnly = true
+
+	b, err := NewBlackjack(cfg)
+	require.NoError(t, err)
+	require.ErrorIs(t, b.Run(), ErrBotsOnlyGame)
+}
+
+// Input lines of the users, ActionExit when the lines are over
+type scriptInput struct {
+	lines []string
+}
+
+func (s *scriptInput) Input() string {
+	if len(s.lines) == 0 {
+		return string(ActionExit)
+	}
+
+	line := s.lines[0]
+	s.lines = s.lines[1:]
+
+	return line
+}
+
+func TestBlackjack_RunHotSeat(t *testing.T) {
+	var out bytes.Buffer
+	recorder := &eventRecorder{}
+
+	cfg := getValidTestCfg()
+	cfg.Seats = []SeatConfig{{Name: "Alex"}, {Name: "Bender", Bot: true}, {Name: "Sam"}}
+	cfg.NoDelay = true
+	cfg.Output = &out
+	cfg.Observers = []Observer{recorder}
+	cfg.Input = &scriptInput{lines: []string{
+		// Bets of Alex and Sam
+		"10", "20",
+		// Alex hits to 21, the bot stands on 18, Sam stands on 19
+		string(ActionTakeCard), string(ActionPass),
+		// Alex stands on the next turn
+		string(ActionPass),
+		// Next round
+		"",
+	}}
+
+	b, err := NewBlackjack(cfg)
+	require.NoError(t, err)
+	require.Len(t, b.players, 3)
+	require.Equal(t, []*Player{b.players[0], b.players[2]}, b.users())
+	require.True(t, b.players[1].Bot)
+	require.Equal(t, "Bender", b.players[1].Name)
+
+	b.deck = deck.FromCards(deck.MustParseCards("Ks 6h Kc 8c Qd 9h 10d 7s 5d"))
+
+	require.NoError(t, b.Run())
+
+	var moves, prompts []string
+	for _, event := range recorder.events {
+		switch event.Type {
+		case EventHit, EventStand:
+			moves = append(moves, event.Name+" "+string(event.Type))
+		case EventPrompt:
+			prompts = append(prompts, event.Name+" "+string(event.Prompt))
+		}
+	}
+	require.Equal(t, []string{"Alex hit", "Bender stand", "Sam stand", "Alex stand"}, moves)
+	require.Equal(t, []string{"Alex bet", "Sam bet", "Alex move", "Sam move", "Alex move", "Alex bet"}, prompts)
+
+	require.Equal(t, 1010, b.players[0].Money)
+	require.Equal(t, 1020, b.players[2].Money)
+
+	text := out.String()
+	require.Contains(t, text, "Alex, it is your turn.")
+	require.Contains(t, text, "Sam, it is your turn.")
+	require.Contains(t, text, "Alex (21 points): Win!")
+	require.Contains(t, text, "Sam (19 points): Win!")
+	require.Contains(t, text, "Money:\n  Alex: 1010\n  Sam: 1020\n")
+	require.NotContains(t, text, "Your cards")
+}
+
+func TestBlackjack_RunAllUsersLost(t *testing.T) {
+	var out bytes.Buffer
+
+	cfg := getValidTestCfg()
+	cfg.NoDelay = true
+	cfg.Output = &out
+	cfg.Input = &scriptInput{}
+
+	b, err := NewBlackjack(cfg)
+	require.NoError(t, err)
+
+	b.currentUser.Money = 0
+	b.currentUser.checkIsLost()
+
+	require.NoError(t, b.Run())
+	require.Contains(t, out.String(), "No money is left to play")
+	require.Equal(t, 0, b.round)
+}
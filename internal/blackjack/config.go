@@ -1,6 +1,9 @@
 package blackjack
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 type CardCost int
 
@@ -17,6 +20,10 @@ const (
 	ActionPass        Action = "p"
 	ActionExit        Action = "q"
 	ActionViewMyCards Action = "c"
+	ActionHint        Action = "h"
+	ActionAnalyze     Action = "a"
+	ActionSave        Action = "save"
+	ActionLoad        Action = "load"
 )
 
 const (
@@ -25,6 +32,17 @@ const (
 	MaxPoints                 = 21
 	Delay                     = 1 * time.Second
 	LongDelay                 = 2 * time.Second
+	// Cards a seat may need in a round, used to reshuffle the shoe before it runs out
+	roundCardsPerSeat = 5
 )
 
+// Name of the dealer in the game events
+const dealerName = "dealer"
+
+// IsReservedName
+// The name of the dealer is not given to a player, the events, the hand history and the stats tell the dealer by it.
+func IsReservedName(name string) bool {
+	return strings.EqualFold(strings.TrimSpace(name), dealerName)
+}
+
 var DefaultPlayerNames = [...]string{"Anton", "Egor", "Karina", "Danil", "Kostya", "Masha", "Roma", "Sasha", "Oleg", "Zhenya", "Nastya", "Lisa", "Maks", "Dima", "Stas", "Anya", "Natasha", "Igor"}
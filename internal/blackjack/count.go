@@ -0,0 +1,229 @@
+package blackjack
+
+import (
+	"course/internal/counting"
+	"course/internal/deck"
+	"fmt"
+	"strconv"
+)
+
+// Bet spread of the CounterStrategy
+const (
+	// Parts of the money in a betting unit
+	counterUnitsInMoney = 50
+	// The bet grows by a unit for every true count point above 1, up to the max units
+	counterMaxUnits = 8
+)
+
+// CountTracker
+// Keeps the counts of the systems from the cards opened in the game events.
+// The counts start over when the shoe is shuffled.
+type CountTracker struct {
+	counters []*counting.Counter
+	err      error
+}
+
+func NewCountTracker(systems ...counting.System) *CountTracker {
+	tracker := &CountTracker{}
+
+	for _, system := range systems {
+		// The decks number of the shoe comes with the shuffle event
+		counter, err := counting.NewCounter(system, MinDecksNumber)
+		if err != nil {
+			tracker.err = err
+			continue
+		}
+		tracker.counters = append(tracker.counters, counter)
+	}
+
+	return tracker
+}
+
+// Counters
+// Counters of the systems in the order they were given.
+func (t *CountTracker) Counters() []*counting.Counter {
+	return t.counters
+}
+
+// Err
+// Returns the first counting error.
+func (t *CountTracker) Err() error {
+	return t.err
+}
+
+// Counts of the counters in order, for the save
+func (t *CountTracker) states() []counting.State {
+	states := make([]counting.State, 0, len(t.counters))
+	for _, counter := range t.counters {
+		states = append(states, counter.State())
+	}
+
+	return states
+}
+
+// The saved counts fit the counters
+func (t *CountTracker) checkStates(states []counting.State) error {
+	if len(states) != len(t.counters) {
+		return fmt.Errorf("%d counts for %d counters", len(states), len(t.counters))
+	}
+
+	for _, state := range states {
+		if err := state.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Continue the saved counts, they are checked with checkStates first
+func (t *CountTracker) restore(states []counting.State) {
+	for i, counter := range t.counters {
+		_ = counter.Restore(states[i])
+	}
+}
+
+func (t *CountTracker) OnEvent(event Event) {
+	switch event.Type {
+	case EventShuffle:
+		for _, counter := range t.counters {
+			counter.Reset(event.Decks)
+		}
+
+	case EventDeal, EventHit, EventDealerReveal, EventDealerHit:
+		for _, card := range event.Cards {
+			t.count(card)
+		}
+	}
+}
+
+func (t *CountTracker) count(card *deck.Card) {
+	for _, counter := range t.counters {
+		if err := counter.Count(card); err != nil && t.err == nil {
+			t.err = err
+		}
+	}
+}
+
+// CounterStrategy
+// Counts the cards with Hi-Lo, spreads the bets by the true count and plays the basic strategy.
+// The engine passes it the game events, so it counts only at a table.
+type CounterStrategy struct {
+	tracker *CountTracker
+}
+
+func NewCounterStrategy() *CounterStrategy {
+	return &CounterStrategy{
+		tracker: NewCountTracker(counting.HiLo),
+	}
+}
+
+func (s *CounterStrategy) Name() string {
+	return StrategyCounter
+}
+
+// TrueCount
+// Hi-Lo true count of the cards seen in the shoe.
+func (s *CounterStrategy) TrueCount() float64 {
+	return s.tracker.Counters()[0].TrueCount()
+}
+
+func (s *CounterStrategy) OnEvent(event Event) {
+	s.tracker.OnEvent(event)
+}
+
+func (s *CounterStrategy) Bet(state BetState) int {
+	if state.Money <= 0 {
+		return 0
+	}
+
+	unit := state.Money / counterUnitsInMoney
+	if unit < 1 {
+		unit = 1
+	}
+
+	units := int(s.TrueCount()) - 1
+	if units < 1 {
+		units = 1
+	} else if units > counterMaxUnits {
+		units = counterMaxUnits
+	}
+
+	bet := unit * units
+	if bet > state.Money {
+		return state.Money
+	}
+
+	return bet
+}
+
+func (s *CounterStrategy) Decide(hand HandState, dealerUpCard *deck.Card, legalMoves []Move) Move {
+	return (&BasicStrategy{}).Decide(hand, dealerUpCard, legalMoves)
+}
+
+// CountQuiz
+// Asks the user for the running count of the counting system between the rounds.
+type CountQuiz struct {
+	tracker *CountTracker
+	Answers int
+	Correct int
+}
+
+func newCountQuiz(system counting.System) *CountQuiz {
+	return &CountQuiz{
+		tracker: NewCountTracker(system),
+	}
+}
+
+func (q *CountQuiz) String() string {
+	return fmt.Sprintf("%d of %d answers correct", q.Correct, q.Answers)
+}
+
+// CountQuiz
+// Score of the count quiz, nil if the quiz is off.
+func (bj *Blackjack) CountQuiz() *CountQuiz {
+	return bj.countQuiz
+}
+
+func (bj *Blackjack) askCountQuiz() error {
+	if bj.countQuiz == nil {
+		return nil
+	}
+
+	system := bj.countQuiz.tracker.Counters()[0].System()
+	fmt.Fprintf(bj.out, "\nCount quiz: what is the %s running count of the shoe? %s - Exit.", system.Name, ActionExit)
+
+	for {
+		fmt.Fprintf(bj.out, "\n>> ")
+		userInput := bj.input.Input()
+
+		// The quiz is not answered any more, the user leaves the game like with the exit command
+		if userInput == string(ActionExit) || isInputOver(bj.input, userInput) {
+			return errExit
+		}
+
+		answer, err := strconv.Atoi(userInput)
+		if err != nil {
+			fmt.Fprintln(bj.out, "Incorrect input")
+			continue
+		}
+
+		bj.answerCountQuiz(answer)
+
+		return nil
+	}
+}
+
+func (bj *Blackjack) answerCountQuiz(answer int) {
+	counter := bj.countQuiz.tracker.Counters()[0]
+	bj.countQuiz.Answers++
+
+	if answer == counter.RunningCount() {
+		bj.countQuiz.Correct++
+		fmt.Fprintf(bj.out, "Correct! The true count is %.1f with %.1f decks left\n", counter.TrueCount(), counter.RemainingDecks())
+		return
+	}
+
+	fmt.Fprintf(bj.out, "Wrong, the running count is %d, the true count is %.1f with %.1f decks left\n",
+		counter.RunningCount(), counter.TrueCount(), counter.RemainingDecks())
+}
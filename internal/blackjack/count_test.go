@@ -0,0 +1,307 @@
+package blackjack
+
+import (
+	"bytes"
+	"course/internal/counting"
+	"course/internal/deck"
+	"github.com/stretchr/testify/require"
+	"io"
+	"testing"
+)
+
+func getTestCards(values ...deck.Rank) []*deck.Card {
+	cards := make([]*deck.Card, 0, len(values))
+
+	for _, value := range values {
+		// The joker is the only card of the trump cards
+		suit := deck.Clover
+		if value == deck.Joker {
+			suit = deck.TrumpCard
+		}
+		cards = append(cards, deck.MustNewCard(suit, value))
+	}
+
+	return cards
+}
+
+// Observer that keeps the events of the game
+type eventRecorder struct {
+	events []Event
+}
+
+func (r *eventRecorder) OnEvent(event Event) {
+	r.events = append(r.events, event)
+}
+
+func (r *eventRecorder) count(eventType EventType) int {
+	count := 0
+
+	for _, event := range r.events {
+		if event.Type == eventType {
+			count++
+		}
+	}
+
+	return count
+}
+
+func TestCountTracker_OnEvent(t *testing.T) {
+	tracker := NewCountTracker(counting.Systems()...)
+	cards := getTestCards(deck.King, deck.Five)
+
+	tracker.OnEvent(Event{Type: EventShuffle, Decks: 2})
+	tracker.OnEvent(Event{Type: EventDeal, Cards: cards})
+	tracker.OnEvent(Event{Type: EventHit, Cards: getTestCards(deck.Four)})
+	tracker.OnEvent(Event{Type: EventDealerReveal, Cards: getTestCards(deck.Ace)})
+	tracker.OnEvent(Event{Type: EventDealerHit, Cards: getTestCards(deck.Seven)})
+	// The settlement does not open new cards
+	tracker.OnEvent(Event{Type: EventSettlement, Cards: cards})
+
+	require.NoError(t, tracker.Err())
+
+	counters := tracker.Counters()
+	require.Len(t, counters, 3)
+	require.Equal(t, 5, counters[0].Seen())
+	// Hi-Lo: -1 +1 +1 -1 0
+	require.Equal(t, 0, counters[0].RunningCount())
+	// KO starts at -4 with 2 decks: -1 +1 +1 -1 +1
+	require.Equal(t, -3, counters[1].RunningCount())
+	// Omega II: -2 +2 +2 0 +1
+	require.Equal(t, 3, counters[2].RunningCount())
+
+	tracker.OnEvent(Event{Type: EventShuffle, Decks: 2})
+	require.Equal(t, 0, counters[0].Seen())
+	require.Equal(t, -4, counters[1].RunningCount())
+
+	tracker.OnEvent(Event{Type: EventHit, Cards: []*deck.Card{deck.MustNewCard(deck.TrumpCard, deck.Joker)}})
+	require.ErrorIs(t, tracker.Err(), counting.ErrInvalidCard)
+}
+
+func TestCounterStrategy_Bet(t *testing.T) {
+	testCases := []struct {
+		name  string
+		cards []deck.Rank
+		money int
+		bet   int
+	}{
+		{
+			name:  "Neutral Count",
+			money: 1000,
+			bet:   20,
+		},
+		{
+			name:  "Negative Count",
+			cards: []deck.Rank{deck.King, deck.Ace, deck.Queen},
+			money: 1000,
+			bet:   20,
+		},
+		{
+			name: "True Count 4",
+			// 4 / (48 / 52) = 4.3
+			cards: []deck.Rank{deck.Two, deck.Three, deck.Four, deck.Five},
+			money: 1000,
+			bet:   60,
+		},
+		{
+			name:  "Max Spread",
+			cards: []deck.Rank{deck.Two, deck.Three, deck.Four, deck.Five, deck.Six, deck.Two, deck.Three, deck.Four, deck.Five, deck.Six, deck.Two, deck.Three, deck.Four, deck.Five, deck.Six},
+			money: 1000,
+			bet:   160,
+		},
+		{
+			name:  "Small Money",
+			money: 30,
+			bet:   1,
+		},
+		{
+			name:  "Spread Above Money",
+			cards: []deck.Rank{deck.Two, deck.Three, deck.Four, deck.Five, deck.Six, deck.Two, deck.Three, deck.Four, deck.Five, deck.Six, deck.Two, deck.Three, deck.Four, deck.Five, deck.Six},
+			money: 5,
+			bet:   5,
+		},
+		{
+			name:  "No Money",
+			money: 0,
+			bet:   0,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewCounterStrategy()
+			s.OnEvent(Event{Type: EventShuffle, Decks: 1})
+			s.OnEvent(Event{Type: EventDeal, Cards: getTestCards(tc.cards...)})
+
+			require.Equal(t, tc.bet, s.Bet(BetState{Money: tc.money}))
+		})
+	}
+}
+
+func TestBlackjack_Penetration(t *testing.T) {
+	testCases := []struct {
+		name        string
+		penetration float64
+		check       func(recorder *eventRecorder, strategy *CounterStrategy)
+	}{
+		{
+			name: "New Shoe Every Round",
+			check: func(recorder *eventRecorder, strategy *CounterStrategy) {
+				require.Equal(t, 30, recorder.count(EventShuffle))
+			},
+		},
+		{
+			name:        "Persistent Shoe",
+			penetration: 0.75,
+			check: func(recorder *eventRecorder, strategy *CounterStrategy) {
+				shuffles := recorder.count(EventShuffle)
+				require.Greater(t, shuffles, 1)
+				require.Less(t, shuffles, 30)
+				require.Equal(t, EventShuffle, recorder.events[1].Type)
+				require.Equal(t, 2, recorder.events[1].Decks)
+				require.NoError(t, strategy.tracker.Err())
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			strategy := NewCounterStrategy()
+			recorder := &eventRecorder{}
+
+			cfg := getValidTestCfg()
+			cfg.BotsOnly = true
+			cfg.BotsNumber = 3
+			cfg.DecksNumber = 2
+			cfg.Penetration = tc.penetration
+			cfg.NoDelay = true
+			cfg.Output = io.Discard
+			cfg.PlayersStartingMoney = 100000
+			cfg.BotStrategies = []Strategy{strategy, &BasicStrategy{}}
+			cfg.Observers = []Observer{recorder}
+
+			b, err := NewBlackjack(cfg)
+			require.NoError(t, err)
+			require.NoError(t, b.Simulate(30))
+			require.Equal(t, 30, b.round)
+
+			tc.check(recorder, strategy)
+		})
+	}
+}
+
+func TestBlackjack_answerCountQuiz(t *testing.T) {
+	var out bytes.Buffer
+
+	cfg := getValidTestCfg()
+	cfg.CountQuiz = true
+	cfg.Output = &out
+
+	b, err := NewBlackjack(cfg)
+	require.NoError(t, err)
+
+	b.deck = deck.FromCards(getTestCards(deck.King, deck.Six, deck.Five, deck.Two, deck.Three, deck.Nine, deck.Ace, deck.Four))
+	b.startRound()
+	require.NoError(t, b.giveCardsToAll(2))
+
+	// The dealer hole card 4 is not opened yet: -1 +1 +1 +1 +1 0 -1
+	b.answerCountQuiz(1)
+	require.Contains(t, out.String(), "Wrong, the running count is 2")
+
+	b.answerCountQuiz(2)
+	require.Contains(t, out.String(), "Correct!")
+	require.Equal(t, "1 of 2 answers correct", b.CountQuiz().String())
+}
+
+// Input lines that end like the console: empty lines and EOF after them
+type eofScriptInput struct {
+	lines []string
+	eof   bool
+}
+
+func (s *eofScriptInput) Input() string {
+	if len(s.lines) == 0 {
+		s.eof = true
+		return ""
+	}
+
+	line := s.lines[0]
+	s.lines = s.lines[1:]
+
+	return line
+}
+
+func (s *eofScriptInput) EOF() bool {
+	return s.eof
+}
+
+func TestBlackjack_askCountQuiz(t *testing.T) {
+	testCases := []struct {
+		name   string
+		system string
+		lines  []string
+		err    error
+		output string
+	}{
+		{
+			name:   "Hi-Lo",
+			lines:  []string{"2"},
+			output: "what is the hi-lo running count",
+		},
+		{
+			name:   "KO Counts Sevens",
+			system: counting.KO.Name,
+			lines:  []string{"3"},
+			output: "what is the ko running count",
+		},
+		{
+			name:   "Incorrect Input",
+			lines:  []string{"two", "2"},
+			output: "Incorrect input",
+		},
+		{
+			name:  "End Of Input",
+			lines: []string{"two"},
+			err:   errExit,
+		},
+		{
+			name:  "Exit",
+			lines: []string{string(ActionExit)},
+			err:   errExit,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+
+			cfg := getValidTestCfg()
+			cfg.CountQuiz = true
+			cfg.CountSystem = tc.system
+			cfg.Output = &out
+			cfg.Input = &eofScriptInput{lines: tc.lines}
+
+			b, err := NewBlackjack(cfg)
+			require.NoError(t, err)
+
+			b.deck = deck.FromCards(getTestCards(deck.King, deck.Six, deck.Five, deck.Two, deck.Three, deck.Seven, deck.Ace, deck.Four))
+			b.startRound()
+			require.NoError(t, b.giveCardsToAll(2))
+
+			err = b.askCountQuiz()
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Contains(t, out.String(), tc.output)
+			require.Contains(t, out.String(), "Correct!")
+		})
+	}
+}
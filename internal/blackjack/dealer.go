@@ -3,6 +3,7 @@ package blackjack
 import (
 	"course/internal/deck"
 	"course/pkg/random"
+	"io"
 )
 
 type Dealer struct {
@@ -11,26 +12,17 @@ type Dealer struct {
 	IsSaved bool
 }
 
-func newDealer() (*Dealer, error) {
+func newDealer(rnd *random.Random) (*Dealer, error) {
 	return &Dealer{
-		Id:      random.RandString(10),
+		Id:      rnd.RandString(10),
 		Cards:   nil,
 		IsSaved: false,
 	}, nil
 }
 
 func (d *Dealer) getPoints() (int, error) {
-	points := 0
-
-	for _, card := range d.Cards {
-		cost, err := getCardCost(card)
-		if err != nil {
-			return -1, err
-		}
-		points += cost
-	}
-
-	return points, nil
+	points, _, err := CardsPoints(d.Cards)
+	return points, err
 }
 
 func (d *Dealer) resetRound() {
@@ -38,9 +30,9 @@ func (d *Dealer) resetRound() {
 	d.Cards = []*deck.Card{}
 }
 
-func (d *Dealer) printAllCards() error {
+func (d *Dealer) printAllCards(w io.Writer, style deck.Style) error {
 	for _, card := range d.Cards {
-		err := printCard(card)
+		err := printCard(w, style, card)
 		if err != nil {
 			return err
 		}
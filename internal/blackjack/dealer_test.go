@@ -2,6 +2,7 @@ package blackjack
 
 import (
 	"course/internal/deck"
+	"course/pkg/random"
 	"github.com/stretchr/testify/require"
 	"testing"
 )
@@ -24,7 +25,7 @@ func Test_NewDealer(t *testing.T) {
 		tc := testCases[i]
 
 		t.Run(tc.name, func(t *testing.T) {
-			d, err := newDealer()
+			d, err := newDealer(random.New(random.Config{}))
 			tc.check(d, err)
 		})
 	}
@@ -48,14 +49,8 @@ func TestDealer_getPoints(t *testing.T) {
 			name: "Some Points",
 			buildStubs: func(d *Dealer) {
 				d.Cards = []*deck.Card{
-					{
-						Suit:  deck.Heart,
-						Value: deck.Queen,
-					},
-					{
-						Suit:  deck.Spade,
-						Value: deck.King,
-					},
+					deck.MustNewCard(deck.Heart, deck.Queen),
+					deck.MustNewCard(deck.Spade, deck.King),
 				}
 			},
 			check: func(d *Dealer, p int, err error) {
@@ -69,7 +64,7 @@ func TestDealer_getPoints(t *testing.T) {
 		tc := testCases[i]
 
 		t.Run(tc.name, func(t *testing.T) {
-			d, err := newDealer()
+			d, err := newDealer(random.New(random.Config{}))
 			require.NoError(t, err)
 
 			tc.buildStubs(d)
@@ -0,0 +1,164 @@
+package blackjack
+
+import (
+	"bufio"
+	"course/internal/deck"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ErrNoStackedCard
+// A stacked card is not in the shoe, for example a joker or more copies of a card than the decks have.
+var ErrNoStackedCard = errors.New("the stacked card is not in the shoe")
+
+// DeckProvider
+// Source of the shoes of the game. The first shoe is taken when the game is created,
+// the next ones every time the shoe is reshuffled. The options have the number of decks and the random source of the game.
+type DeckProvider interface {
+	NextDeck(options deck.NewDeckOptions) ([]*deck.Card, error)
+}
+
+// RandomShoe
+// Newly shuffled shoe every time. The shoes of the game if Config.Decks is nil.
+type RandomShoe struct{}
+
+func (RandomShoe) NextDeck(options deck.NewDeckOptions) ([]*deck.Card, error) {
+	return deck.NewDeck(options)
+}
+
+// StackedDecks
+// Shoes with the given cards on top, in order. The stacked cards are moved to the top of a shuffled shoe,
+// so the shoe keeps its composition and a round does not run out of cards. NextDeck returns ErrNoStackedCard
+// if a stacked card can not be taken out of the shoe.
+// After the last stacked cards the shoes are taken from Then.
+type StackedDecks struct {
+	stacks [][]*deck.Card
+	next   int
+	// Source of the shoes after the stacked ones. RandomShoe is used if nil
+	Then DeckProvider
+}
+
+// NewStackedDecks
+// Shoes with the cards of every stack on top, one stack a shoe.
+func NewStackedDecks(stacks ...[]*deck.Card) *StackedDecks {
+	return &StackedDecks{stacks: stacks}
+}
+
+// Remaining
+// Number of the stacks not dealt yet.
+func (s *StackedDecks) Remaining() int {
+	return len(s.stacks) - s.next
+}
+
+func (s *StackedDecks) NextDeck(options deck.NewDeckOptions) ([]*deck.Card, error) {
+	if s.next >= len(s.stacks) {
+		if s.Then == nil {
+			return RandomShoe{}.NextDeck(options)
+		}
+		return s.Then.NextDeck(options)
+	}
+
+	stack := s.stacks[s.next]
+	s.next++
+
+	shoe, err := deck.NewDeck(options)
+	if err != nil {
+		return nil, err
+	}
+
+	return stackCards(stack, shoe)
+}
+
+// Put the stacked cards on top of the shoe, each one is taken out of the rest of the shoe
+func stackCards(stack []*deck.Card, shoe []*deck.Card) ([]*deck.Card, error) {
+	rest := append([]*deck.Card{}, shoe...)
+
+	for _, card := range stack {
+		found := false
+
+		for i, shoeCard := range rest {
+			if *shoeCard == *card {
+				rest = append(rest[:i], rest[i+1:]...)
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("%w: %s", ErrNoStackedCard, card.Format(deck.StyleASCII))
+		}
+	}
+
+	stacked := make([]*deck.Card, 0, len(stack)+len(rest))
+	for _, card := range stack {
+		c := *card
+		stacked = append(stacked, &c)
+	}
+
+	return append(stacked, rest...), nil
+}
+
+// Deck of the next shoe of the provider. The cards of a provider may be built without deck.NewCard, so they are checked
+func nextShoe(decks DeckProvider, options deck.NewDeckOptions) (*deck.Deck, error) {
+	cards, err := decks.NextDeck(options)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := deck.ValidateCards(cards); err != nil {
+		return nil, fmt.Errorf("shoe of the deck provider: %w", err)
+	}
+
+	return deck.FromCards(cards), nil
+}
+
+// ParseScenario
+// Stacked shoes of a scenario: every line has the cards on top of a shoe in the notation of deck.ParseCards,
+// in the dealing order. Empty lines and the lines starting with "#" are skipped.
+func ParseScenario(r io.Reader) (*StackedDecks, error) {
+	var stacks [][]*deck.Card
+
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		cards, err := deck.ParseCards(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		stacks = append(stacks, cards)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return NewStackedDecks(stacks...), nil
+}
+
+// LoadScenario
+// Stacked shoes of the scenario file, see ParseScenario.
+func LoadScenario(path string) (*StackedDecks, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	decks, err := ParseScenario(file)
+	if err != nil {
+		return nil, fmt.Errorf("scenario %s: %w", path, err)
+	}
+
+	return decks, nil
+}
@@ -0,0 +1,235 @@
+package blackjack
+
+import (
+	"bytes"
+	"course/internal/deck"
+	"github.com/stretchr/testify/require"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStackedDecks_NextDeck(t *testing.T) {
+	stacks := NewStackedDecks(deck.MustParseCards("As Ah"), deck.MustParseCards("JK 7c"), deck.MustParseCards("7c 7c 7c"))
+	options := deck.NewDeckOptions{DecksNumber: 2}
+
+	shoe, err := stacks.NextDeck(options)
+	require.NoError(t, err)
+	require.Len(t, shoe, 104)
+	require.Equal(t, "As Ah", deck.FormatCards(shoe[:2], deck.StyleASCII))
+	require.Equal(t, 2, stacks.Remaining())
+
+	// The stacked cards are taken out of the rest of the shoe
+	aces := 0
+	for _, card := range shoe {
+		if card.Rank() == deck.Ace {
+			aces++
+		}
+	}
+	require.Equal(t, 8, aces)
+
+	// The shoe has no jokers
+	_, err = stacks.NextDeck(options)
+	require.ErrorIs(t, err, ErrNoStackedCard)
+	require.EqualError(t, err, "the stacked card is not in the shoe: JK")
+
+	// Two decks have only two cards of a kind
+	_, err = stacks.NextDeck(options)
+	require.ErrorIs(t, err, ErrNoStackedCard)
+	require.Equal(t, 0, stacks.Remaining())
+
+	shoe, err = stacks.NextDeck(options)
+	require.NoError(t, err)
+	require.Len(t, shoe, 104)
+
+	stacks = NewStackedDecks()
+	stacks.Then = NewStackedDecks(deck.MustParseCards("2d"))
+
+	shoe, err = stacks.NextDeck(deck.NewDeckOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "2d", shoe[0].Format(deck.StyleASCII))
+}
+
+// Shoe of the cards as they are, without checking them for nil and zero cards
+type fixedShoe []*deck.Card
+
+func (s fixedShoe) NextDeck(deck.NewDeckOptions) ([]*deck.Card, error) {
+	return s, nil
+}
+
+func TestNewBlackjack_InvalidShoe(t *testing.T) {
+	cfg := getValidTestCfg()
+	cfg.Decks = fixedShoe{deck.MustNewCard(deck.Spade, deck.Ace), {}}
+
+	_, err := NewBlackjack(cfg)
+	require.ErrorIs(t, err, deck.ErrInvalidCard)
+}
+
+func TestParseScenario(t *testing.T) {
+	testCases := []struct {
+		name   string
+		text   string
+		stacks int
+		err    string
+	}{
+		{
+			name:   "Stacks",
+			text:   "# Dealer bust\n10s 6h 10d 6c 9h\n\n  # Natural\nAs Kh 10d 7c\n",
+			stacks: 2,
+		},
+		{
+			name:   "Empty",
+			text:   "# Nothing is stacked\n",
+			stacks: 0,
+		},
+		{
+			name: "Invalid Card",
+			text: "10s 6h\n\n10d 6x\n",
+			err:  "line 3: invalid card \"6x\"",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			stacks, err := ParseScenario(strings.NewReader(tc.text))
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.stacks, stacks.Remaining())
+		})
+	}
+}
+
+func TestLoadScenario(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scenario.txt")
+	require.NoError(t, os.WriteFile(path, []byte("10s 6h 10d 6c 9h\n"), 0644))
+
+	stacks, err := LoadScenario(path)
+	require.NoError(t, err)
+	require.Equal(t, 1, stacks.Remaining())
+
+	_, err = LoadScenario(filepath.Join(t.TempDir(), "missing.txt"))
+	require.Error(t, err)
+}
+
+func TestBlackjack_RunScenario(t *testing.T) {
+	testCases := []struct {
+		name   string
+		cards  string
+		rules  Rules
+		moves  []Action
+		result RoundResult
+		money  int
+		output string
+	}{
+		{
+			name:   "Dealer Bust",
+			cards:  "10s 6h 10d 6c 9h",
+			moves:  []Action{ActionPass},
+			result: ResultWin,
+			money:  1010,
+			output: "dealer (25 points)",
+		},
+		{
+			name:   "Player Bust",
+			cards:  "10s 6h 10d 7c 9h",
+			moves:  []Action{ActionTakeCard, ActionPass},
+			result: ResultDefeat,
+			money:  990,
+			output: "You (25 points): Defeat",
+		},
+		{
+			name:   "Push",
+			cards:  "10s 8h 10d 8c",
+			moves:  []Action{ActionPass},
+			result: ResultDraw,
+			money:  1000,
+			output: "You (18 points): Draw",
+		},
+		{
+			name:   "Natural Pays 3 to 2",
+			cards:  "As Kh 10d 7c",
+			rules:  Rules{BlackjackPays3To2: true},
+			moves:  []Action{ActionPass},
+			result: ResultWin,
+			money:  1015,
+			output: "You (21 points): Blackjack!",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			recorder := &eventRecorder{}
+
+			lines := []string{"10"}
+			for _, move := range tc.moves {
+				lines = append(lines, string(move))
+			}
+			// Next round
+			lines = append(lines, "")
+
+			cfg := getValidTestCfg()
+			cfg.Seats = []SeatConfig{{Name: "Alex"}}
+			cfg.Rules = tc.rules
+			cfg.NoDelay = true
+			cfg.Output = &out
+			cfg.Observers = []Observer{recorder}
+			cfg.Input = &scriptInput{lines: lines}
+			cfg.Decks = NewStackedDecks(deck.MustParseCards(tc.cards))
+
+			b, err := NewBlackjack(cfg)
+			require.NoError(t, err)
+			require.NoError(t, b.Run())
+
+			var settlements []Event
+			for _, event := range recorder.events {
+				if event.Type == EventSettlement {
+					settlements = append(settlements, event)
+				}
+			}
+			require.Len(t, settlements, 1)
+			require.Equal(t, tc.result, settlements[0].Result)
+			require.Equal(t, tc.money, settlements[0].Money)
+			require.Equal(t, tc.money, b.players[0].Money)
+			require.Contains(t, out.String(), tc.output)
+		})
+	}
+}
+
+func TestBlackjack_DiscardPile(t *testing.T) {
+	cfg := getValidTestCfg()
+	cfg.Seats = []SeatConfig{{Name: "Alex"}}
+	cfg.Penetration = 0.75
+	cfg.NoDelay = true
+	cfg.Output = io.Discard
+	cfg.Input = &scriptInput{lines: []string{"10", string(ActionPass), ""}}
+	cfg.Decks = NewStackedDecks(deck.MustParseCards("10s 6h 10d 6c 9h"))
+
+	b, err := NewBlackjack(cfg)
+	require.NoError(t, err)
+	require.NoError(t, b.Run())
+
+	// The played cards are on the discard pile, the shoe is not reshuffled before the cut card
+	require.Equal(t, "10s 6h 10d 6c 9h", deck.FormatCards(b.deck.Discarded(), deck.StyleASCII))
+	require.Equal(t, 5, b.deck.Drawn())
+	require.Equal(t, 47, b.deck.Remaining())
+	require.NoError(t, b.deck.Validate(b.deckOptions))
+
+	var save bytes.Buffer
+	require.NoError(t, b.Save(&save))
+
+	resumed, err := NewBlackjack(cfg)
+	require.NoError(t, err)
+	require.NoError(t, resumed.Load(&save))
+	require.Equal(t, b.deck, resumed.deck)
+}
@@ -0,0 +1,195 @@
+package blackjack
+
+import (
+	"course/internal/deck"
+	"time"
+)
+
+type EventType string
+
+// Game events
+const (
+	// A new round has started, Seats contains the players and their money
+	EventRoundStart EventType = "roundStart"
+	// The round is dealt from a newly shuffled shoe of Decks decks
+	EventShuffle EventType = "shuffle"
+	// The player has made a bet
+	EventBet EventType = "bet"
+	// Starting cards were dealt. For the dealer only the opened card is passed
+	EventDeal EventType = "deal"
+	// The player took a card
+	EventHit EventType = "hit"
+	// The player saved
+	EventStand EventType = "stand"
+	// The dealer opened the hidden card
+	EventDealerReveal EventType = "dealerReveal"
+	// The dealer took a card
+	EventDealerHit EventType = "dealerHit"
+	// The dealer saved
+	EventDealerStand EventType = "dealerStand"
+	// The bet of the player was paid out
+	EventSettlement EventType = "settlement"
+	// The user is asked for a bet or a move, the next input of the user answers the prompt.
+	// Prompts are not a part of the game record
+	EventPrompt EventType = "prompt"
+)
+
+type PromptKind string
+
+// What the user is asked for
+const (
+	PromptBet  PromptKind = "bet"
+	PromptMove PromptKind = "move"
+)
+
+type RoundResult string
+
+// Round results of the player
+const (
+	ResultWin    RoundResult = "win"
+	ResultDraw   RoundResult = "draw"
+	ResultDefeat RoundResult = "defeat"
+)
+
+type Seat struct {
+	Id    string `json:"id"`
+	Name  string `json:"name"`
+	Bot   bool   `json:"bot"`
+	Money int    `json:"money"`
+}
+
+type Event struct {
+	Type EventType `json:"type"`
+	// Number of the round, starting from 1
+	Round int       `json:"round"`
+	Time  time.Time `json:"time"`
+	// Player or dealer id
+	ParticipantId string `json:"participantId,omitempty"`
+	// Player name or "dealer"
+	Name string `json:"name,omitempty"`
+	// Players at the table, only for EventRoundStart
+	Seats []Seat `json:"seats,omitempty"`
+	// Number of decks in the shoe, only for EventShuffle
+	Decks int `json:"decks,omitempty"`
+	// Cards received with the event
+	Cards []*deck.Card `json:"cards,omitempty"`
+	// Points of the participant after the event
+	Points int `json:"points,omitempty"`
+	Bet    int `json:"bet,omitempty"`
+	// Coins returned to the player on settlement, including the bet
+	Payout int `json:"payout,omitempty"`
+	// Money of the player after the event, only encoded for the events that change it
+	Money  int         `json:"money,omitempty"`
+	Result RoundResult `json:"result,omitempty"`
+	// What the user is asked for, only for EventPrompt
+	Prompt PromptKind `json:"prompt,omitempty"`
+}
+
+// Observer
+// Receives the game events. OnEvent is called synchronously from the game loop.
+type Observer interface {
+	OnEvent(event Event)
+}
+
+func (bj *Blackjack) emit(event Event) {
+	if len(bj.observers) == 0 {
+		return
+	}
+
+	event.Round = bj.round
+	event.Time = time.Now()
+
+	for _, observer := range bj.observers {
+		observer.OnEvent(event)
+	}
+}
+
+func (bj *Blackjack) emitPlayerEvent(eventType EventType, player *Player, cards ...*deck.Card) error {
+	points, err := player.getPoints()
+	if err != nil {
+		return err
+	}
+
+	bj.emit(Event{
+		Type:          eventType,
+		ParticipantId: player.Id,
+		Name:          player.Name,
+		Cards:         cards,
+		Points:        points,
+		Bet:           player.Bet,
+		Money:         player.Money,
+	})
+
+	return nil
+}
+
+func (bj *Blackjack) emitPrompt(user *Player, prompt PromptKind) error {
+	points, err := user.getPoints()
+	if err != nil {
+		return err
+	}
+
+	bj.emit(Event{
+		Type:          EventPrompt,
+		ParticipantId: user.Id,
+		Name:          user.Name,
+		Points:        points,
+		Bet:           user.Bet,
+		Money:         user.Money,
+		Prompt:        prompt,
+	})
+
+	return nil
+}
+
+func (bj *Blackjack) emitDealerEvent(eventType EventType, cards ...*deck.Card) error {
+	countedCards := bj.dealer.Cards
+
+	// Only the opened card is counted on the deal
+	if eventType == EventDeal {
+		countedCards = countedCards[:1]
+	}
+
+	points, _, err := CardsPoints(countedCards)
+	if err != nil {
+		return err
+	}
+
+	bj.emit(Event{
+		Type:          eventType,
+		ParticipantId: bj.dealer.Id,
+		Name:          dealerName,
+		Cards:         cards,
+		Points:        points,
+	})
+
+	return nil
+}
+
+func (bj *Blackjack) startRound() {
+	bj.round++
+
+	seats := make([]Seat, 0, len(bj.players))
+
+	for _, player := range bj.players {
+		seats = append(seats, Seat{
+			Id:    player.Id,
+			Name:  player.Name,
+			Bot:   player.Bot,
+			Money: player.Money,
+		})
+	}
+
+	bj.emit(Event{
+		Type:  EventRoundStart,
+		Seats: seats,
+	})
+
+	if bj.shuffled {
+		bj.shuffled = false
+		bj.emit(Event{
+			Type:  EventShuffle,
+			Decks: bj.deckOptions.DecksNumber,
+		})
+	}
+}
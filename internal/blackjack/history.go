@@ -0,0 +1,193 @@
+package blackjack
+
+import (
+	"course/internal/deck"
+	"fmt"
+	"io"
+)
+
+// Sections of a round in the hand history
+const (
+	historySectionBets       = "*** BETS ***"
+	historySectionDeal       = "*** DEAL ***"
+	historySectionPlay       = "*** PLAY ***"
+	historySectionDealer     = "*** DEALER ***"
+	historySectionSettlement = "*** SETTLEMENT ***"
+)
+
+const historyTimeLayout = "2006-01-02 15:04:05"
+
+// Table rules in the hand history
+const (
+	historyDealerStandsSoft17 = "dealer stands on soft 17"
+	historyDealerHitsSoft17   = "dealer hits soft 17"
+	historyBlackjackPays1To1  = "blackjack pays 1:1"
+	historyBlackjackPays3To2  = "blackjack pays 3:2"
+	historyNoHoleCard         = "no hole card"
+)
+
+// HandHistory
+// Writes every round in a human-readable format inspired by poker hand histories:
+//
+//	Blackjack Hand #1 - 2026-10-19 11:43:12
+//	Table: starting money 100, bots 3, decks 1, dealer stands on soft 17, blackjack pays 1:1
+//	Seat 1: Arasaki (100 c.)
+//	Seat 2: Anton (100 c.) [bot]
+//	*** BETS ***
+//	Arasaki: bets 10
+//	*** DEAL ***
+//	Dealt to Arasaki [7s 2h] (total 9)
+//	Dealer shows [Kc] (total 10)
+//	*** PLAY ***
+//	Arasaki: hits [9d] (total 18)
+//	Arasaki: stands (total 18)
+//	*** DEALER ***
+//	Dealer reveals [7h] (total 17)
+//	Dealer stands (total 17)
+//	*** SETTLEMENT ***
+//	Arasaki: win, bet 10, payout 20 (total 18, money 110)
+type HandHistory struct {
+	w   io.Writer
+	cfg Config
+	// Header of the last written section
+	section string
+	// Whether at least one round was written
+	started bool
+	err     error
+}
+
+func NewHandHistory(w io.Writer, cfg Config) *HandHistory {
+	return &HandHistory{
+		w:   w,
+		cfg: cfg,
+	}
+}
+
+// Err
+// Returns the first write error.
+func (h *HandHistory) Err() error {
+	return h.err
+}
+
+func (h *HandHistory) OnEvent(event Event) {
+	// The hand history records the dealt cards and the moves, not the shoe and the prompts
+	if event.Type == EventShuffle || event.Type == EventPrompt {
+		return
+	}
+
+	if event.Type != EventRoundStart {
+		h.startSection(historySection(event.Type))
+		h.printf("%s\n", historyLine(event))
+		return
+	}
+
+	if h.started {
+		h.printf("\n")
+	}
+	h.started = true
+	h.section = ""
+
+	h.printf("Blackjack Hand #%d - %s\n", event.Round, event.Time.Format(historyTimeLayout))
+	h.printf("Table: starting money %d, bots %d, %s\n", h.cfg.PlayersStartingMoney, countBots(h.cfg.tableSeats()), formatHistoryRules(h.cfg))
+
+	for i, seat := range event.Seats {
+		bot := ""
+		if seat.Bot {
+			bot = " [bot]"
+		}
+		h.printf("Seat %d: %s (%d c.)%s\n", i+1, seat.Name, seat.Money, bot)
+	}
+}
+
+func (h *HandHistory) startSection(section string) {
+	if h.section == section {
+		return
+	}
+
+	h.section = section
+	h.printf("%s\n", section)
+}
+
+func (h *HandHistory) printf(format string, a ...any) {
+	if h.err != nil {
+		return
+	}
+
+	_, h.err = fmt.Fprintf(h.w, format, a...)
+}
+
+// Deck and rules part of the table line
+func formatHistoryRules(cfg Config) string {
+	decksNumber := cfg.DecksNumber
+	if decksNumber == 0 {
+		decksNumber = MinDecksNumber
+	}
+
+	dealerRule := historyDealerStandsSoft17
+	if cfg.Rules.DealerHitsSoft17 {
+		dealerRule = historyDealerHitsSoft17
+	}
+
+	blackjackRule := historyBlackjackPays1To1
+	if cfg.Rules.BlackjackPays3To2 {
+		blackjackRule = historyBlackjackPays3To2
+	}
+
+	rules := fmt.Sprintf("decks %d, %s, %s", decksNumber, dealerRule, blackjackRule)
+	if cfg.Rules.NoHoleCard {
+		rules += ", " + historyNoHoleCard
+	}
+
+	return rules
+}
+
+// Section of the hand history to which the event belongs
+func historySection(eventType EventType) string {
+	switch eventType {
+	case EventBet:
+		return historySectionBets
+	case EventDeal:
+		return historySectionDeal
+	case EventHit, EventStand:
+		return historySectionPlay
+	case EventDealerReveal, EventDealerHit, EventDealerStand:
+		return historySectionDealer
+	case EventSettlement:
+		return historySectionSettlement
+	default:
+		return ""
+	}
+}
+
+// Hand history line of the event, without the line break
+func historyLine(event Event) string {
+	switch event.Type {
+	case EventBet:
+		return fmt.Sprintf("%s: bets %d", event.Name, event.Bet)
+	case EventDeal:
+		if event.Name == dealerName {
+			return fmt.Sprintf("Dealer shows %s (total %d)", formatHistoryCards(event.Cards), event.Points)
+		}
+		return fmt.Sprintf("Dealt to %s %s (total %d)", event.Name, formatHistoryCards(event.Cards), event.Points)
+	case EventHit:
+		return fmt.Sprintf("%s: hits %s (total %d)", event.Name, formatHistoryCards(event.Cards), event.Points)
+	case EventStand:
+		return fmt.Sprintf("%s: stands (total %d)", event.Name, event.Points)
+	case EventDealerReveal:
+		return fmt.Sprintf("Dealer reveals %s (total %d)", formatHistoryCards(event.Cards), event.Points)
+	case EventDealerHit:
+		return fmt.Sprintf("Dealer hits %s (total %d)", formatHistoryCards(event.Cards), event.Points)
+	case EventDealerStand:
+		return fmt.Sprintf("Dealer stands (total %d)", event.Points)
+	case EventSettlement:
+		return fmt.Sprintf("%s: %s, bet %d, payout %d (total %d, money %d)",
+			event.Name, event.Result, event.Bet, event.Payout, event.Points, event.Money)
+	default:
+		return fmt.Sprintf("Blackjack Hand #%d", event.Round)
+	}
+}
+
+// Cards in the ASCII notation: [As 10h]
+func formatHistoryCards(cards []*deck.Card) string {
+	return "[" + deck.FormatCards(cards, deck.StyleASCII) + "]"
+}
@@ -0,0 +1,132 @@
+package blackjack
+
+import (
+	"bytes"
+	"course/internal/deck"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHandHistory_OnEvent(t *testing.T) {
+	cfg := getValidTestCfg()
+
+	testCases := []struct {
+		name   string
+		events []Event
+		check  func(history string)
+	}{
+		{
+			name: "Round Start",
+			events: []Event{
+				{
+					Type:  EventRoundStart,
+					Round: 3,
+					Seats: []Seat{
+						{Name: "Alex", Money: 1000},
+						{Name: "Anton", Money: 900, Bot: true},
+					},
+				},
+			},
+			check: func(history string) {
+				require.Contains(t, history, "Blackjack Hand #3 - ")
+				require.Contains(t, history, "Table: starting money 1000, bots 2, decks 1, dealer stands on soft 17, blackjack pays 1:1\n")
+				require.Contains(t, history, "Seat 1: Alex (1000 c.)\nSeat 2: Anton (900 c.) [bot]\n")
+			},
+		},
+		{
+			name: "Dealer Play",
+			events: []Event{
+				{
+					Type:   EventDealerReveal,
+					Name:   dealerName,
+					Cards:  deck.MustParseCards("6h"),
+					Points: 16,
+				},
+				{
+					Type:   EventDealerHit,
+					Name:   dealerName,
+					Cards:  deck.MustParseCards("5s"),
+					Points: 21,
+				},
+				{
+					Type:   EventDealerStand,
+					Name:   dealerName,
+					Points: 21,
+				},
+			},
+			check: func(history string) {
+				require.Equal(t, "*** DEALER ***\nDealer reveals [6h] (total 16)\nDealer hits [5s] (total 21)\nDealer stands (total 21)\n", history)
+			},
+		},
+		{
+			name: "Rounds Separated",
+			events: []Event{
+				{Type: EventRoundStart, Round: 1},
+				{Type: EventRoundStart, Round: 2},
+			},
+			check: func(history string) {
+				require.Contains(t, history, "\n\nBlackjack Hand #2")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			h := NewHandHistory(&buf, cfg)
+
+			for _, event := range tc.events {
+				h.OnEvent(event)
+			}
+
+			require.NoError(t, h.Err())
+			tc.check(buf.String())
+		})
+	}
+}
+
+func TestBlackjack_HandHistory(t *testing.T) {
+	cfg := getValidTestCfg()
+	cfg.BotsNumber = MinBotsNumber
+	cfg.HandHistoryFile = filepath.Join(t.TempDir(), "history.txt")
+
+	var buf bytes.Buffer
+	cfg.Observers = []Observer{NewHandHistory(&buf, cfg)}
+
+	b, err := NewBlackjack(cfg)
+	require.NoError(t, err)
+	defer b.Close()
+
+	b.players[1].Name = "Anton"
+	b.deck = deck.FromCards(deck.MustParseCards("7s 2h Kc 8c Qd 9h 9d"))
+
+	b.startRound()
+	b.currentUser.Bet = 10
+	b.currentUser.Money -= 10
+	require.NoError(t, b.giveCardsToAll(2))
+
+	_, err = b.onUserInput(string(ActionTakeCard))
+	require.NoError(t, err)
+	_, err = b.onUserInput(string(ActionPass))
+	require.NoError(t, err)
+	require.NoError(t, b.botTurn(b.players[1]))
+
+	b.dealerSaved()
+	require.NoError(t, b.printRoundResults())
+
+	history := buf.String()
+	require.Contains(t, history, "Seat 1: Alex (1000 c.)\nSeat 2: Anton (1000 c.) [bot]\n")
+	require.Contains(t, history, "*** DEAL ***\nDealt to Alex [7s 2h] (total 9)\nDealt to Anton [Kc 8c] (total 18)\nDealer shows [Qd] (total 10)\n")
+	require.Contains(t, history, "*** PLAY ***\nAlex: hits [9d] (total 18)\nAlex: stands (total 18)\nAnton: stands (total 18)\n")
+	require.Contains(t, history, "*** SETTLEMENT ***\nAlex: defeat, bet 10, payout 0 (total 18, money 990)\nAnton: defeat, bet 0, payout 0 (total 18, money 1000)\n")
+
+	require.NoError(t, b.Close())
+
+	content, err := os.ReadFile(cfg.HandHistoryFile)
+	require.NoError(t, err)
+	require.Equal(t, history, string(content))
+}
@@ -0,0 +1,158 @@
+package blackjack
+
+import (
+	"course/internal/deck"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// EventSchemaVersion
+// Version of the JSON event schema. Increase it when a field is changed or removed.
+const EventSchemaVersion = 1
+
+type jsonCard struct {
+	Suit deck.CardSuit `json:"suit"`
+	Rank deck.Rank     `json:"value"`
+}
+
+type jsonEvent struct {
+	SchemaVersion int         `json:"schemaVersion"`
+	Type          EventType   `json:"type"`
+	Round         int         `json:"round"`
+	Time          time.Time   `json:"time"`
+	ParticipantId string      `json:"participantId,omitempty"`
+	Name          string      `json:"name,omitempty"`
+	Seats         []Seat      `json:"seats,omitempty"`
+	Decks         int         `json:"decks,omitempty"`
+	Cards         []jsonCard  `json:"cards,omitempty"`
+	Points        int         `json:"points,omitempty"`
+	Bet           int         `json:"bet,omitempty"`
+	Payout        int         `json:"payout,omitempty"`
+	Money         *int        `json:"money,omitempty"`
+	MoneyChange   int         `json:"moneyChange,omitempty"`
+	Result        RoundResult `json:"result,omitempty"`
+	Prompt        PromptKind  `json:"prompt,omitempty"`
+}
+
+// JSONLog
+// Writes every game event as a JSON object on a separate line (JSON Lines).
+// Players and the dealer are identified by Player.Id and Dealer.Id.
+type JSONLog struct {
+	encoder *json.Encoder
+	err     error
+}
+
+func NewJSONLog(w io.Writer) *JSONLog {
+	return &JSONLog{
+		encoder: json.NewEncoder(w),
+	}
+}
+
+// Err
+// Returns the first write error.
+func (l *JSONLog) Err() error {
+	return l.err
+}
+
+func (l *JSONLog) OnEvent(event Event) {
+	if l.err != nil || event.Type == EventPrompt {
+		return
+	}
+
+	l.err = l.encoder.Encode(newJSONEvent(event))
+}
+
+func newJSONEvent(event Event) jsonEvent {
+	record := jsonEvent{
+		SchemaVersion: EventSchemaVersion,
+		Type:          event.Type,
+		Round:         event.Round,
+		Time:          event.Time,
+		ParticipantId: event.ParticipantId,
+		Name:          event.Name,
+		Decks:         event.Decks,
+		Points:        event.Points,
+		Bet:           event.Bet,
+		Payout:        event.Payout,
+		Result:        event.Result,
+		Prompt:        event.Prompt,
+		Seats:         event.Seats,
+	}
+
+	for _, card := range event.Cards {
+		record.Cards = append(record.Cards, jsonCard{
+			Suit: card.Suit(),
+			Rank: card.Rank(),
+		})
+	}
+
+	// Money is only known for the events that change it
+	switch event.Type {
+	case EventBet:
+		{
+			money := event.Money
+			record.Money = &money
+			record.MoneyChange = -event.Bet
+		}
+
+	case EventSettlement:
+		{
+			money := event.Money
+			record.Money = &money
+			record.MoneyChange = event.Payout
+		}
+
+	case EventPrompt:
+		{
+			money := event.Money
+			record.Money = &money
+		}
+	}
+
+	return record
+}
+
+// MarshalJSON
+// Encode the event with the schema of the JSON event log.
+func (e Event) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newJSONEvent(e))
+}
+
+// UnmarshalJSON
+// Decode the event written with MarshalJSON or by the JSON event log.
+func (e *Event) UnmarshalJSON(data []byte) error {
+	var record jsonEvent
+	if err := json.Unmarshal(data, &record); err != nil {
+		return err
+	}
+
+	*e = Event{
+		Type:          record.Type,
+		Round:         record.Round,
+		Time:          record.Time,
+		ParticipantId: record.ParticipantId,
+		Name:          record.Name,
+		Decks:         record.Decks,
+		Points:        record.Points,
+		Bet:           record.Bet,
+		Payout:        record.Payout,
+		Result:        record.Result,
+		Prompt:        record.Prompt,
+		Seats:         record.Seats,
+	}
+
+	if record.Money != nil {
+		e.Money = *record.Money
+	}
+
+	for _, card := range record.Cards {
+		decoded, err := deck.NewCard(card.Suit, card.Rank)
+		if err != nil {
+			return err
+		}
+		e.Cards = append(e.Cards, decoded)
+	}
+
+	return nil
+}
@@ -0,0 +1,221 @@
+package blackjack
+
+import (
+	"bufio"
+	"bytes"
+	"course/internal/deck"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestJSONLog_OnEvent(t *testing.T) {
+	testCases := []struct {
+		name  string
+		event Event
+		check func(record map[string]any)
+	}{
+		{
+			name: "Round Start",
+			event: Event{
+				Type:  EventRoundStart,
+				Round: 2,
+				Seats: []Seat{{Id: "a", Name: "Alex", Money: 100}},
+			},
+			check: func(record map[string]any) {
+				require.Equal(t, float64(EventSchemaVersion), record["schemaVersion"])
+				require.Equal(t, "roundStart", record["type"])
+				require.Equal(t, float64(2), record["round"])
+				require.Equal(t, []any{map[string]any{"id": "a", "name": "Alex", "bot": false, "money": float64(100)}}, record["seats"])
+			},
+		},
+		{
+			name: "Bet",
+			event: Event{
+				Type:          EventBet,
+				ParticipantId: "a",
+				Name:          "Alex",
+				Bet:           10,
+				Money:         0,
+			},
+			check: func(record map[string]any) {
+				require.Equal(t, "a", record["participantId"])
+				require.Equal(t, float64(10), record["bet"])
+				require.Equal(t, float64(0), record["money"])
+				require.Equal(t, float64(-10), record["moneyChange"])
+			},
+		},
+		{
+			name: "Hit",
+			event: Event{
+				Type:          EventHit,
+				ParticipantId: "a",
+				Cards:         deck.MustParseCards("Ah"),
+				Points:        21,
+				Money:         90,
+			},
+			check: func(record map[string]any) {
+				require.Equal(t, []any{map[string]any{"suit": "heart", "value": "ace"}}, record["cards"])
+				require.Equal(t, float64(21), record["points"])
+				require.NotContains(t, record, "money")
+			},
+		},
+		{
+			name: "Settlement",
+			event: Event{
+				Type:   EventSettlement,
+				Bet:    10,
+				Payout: 20,
+				Money:  110,
+				Result: ResultWin,
+			},
+			check: func(record map[string]any) {
+				require.Equal(t, "win", record["result"])
+				require.Equal(t, float64(110), record["money"])
+				require.Equal(t, float64(20), record["moneyChange"])
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := NewJSONLog(&buf)
+			l.OnEvent(tc.event)
+			require.NoError(t, l.Err())
+
+			var record map[string]any
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+			tc.check(record)
+		})
+	}
+}
+
+func TestBlackjack_EventLog(t *testing.T) {
+	cfg := getValidTestCfg()
+
+	var buf bytes.Buffer
+	cfg.EventLog = &buf
+
+	b, err := NewBlackjack(cfg)
+	require.NoError(t, err)
+
+	b.startRound()
+	b.placeBet(b.currentUser, 10)
+	require.NoError(t, b.giveCardsToAll(2))
+
+	var types []string
+	scanner := bufio.NewScanner(&buf)
+
+	for scanner.Scan() {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		types = append(types, record["type"].(string))
+
+		if record["type"] == string(EventDeal) && record["participantId"] == b.dealer.Id {
+			require.Len(t, record["cards"], 1)
+		}
+
+		if record["type"] == string(EventShuffle) {
+			require.Equal(t, float64(1), record["decks"])
+		}
+	}
+
+	require.Equal(t, []string{"roundStart", "shuffle", "bet", "deal", "deal", "deal", "deal"}, types)
+}
+
+func TestEvent_JSON(t *testing.T) {
+	testCases := []struct {
+		name  string
+		event Event
+	}{
+		{
+			name: "Deal",
+			event: Event{
+				Type:          EventDeal,
+				Round:         3,
+				ParticipantId: "a",
+				Name:          "Alex",
+				Cards:         deck.MustParseCards("As 7h"),
+				Points:        18,
+			},
+		},
+		{
+			name: "Settlement",
+			event: Event{
+				Type:          EventSettlement,
+				ParticipantId: "a",
+				Name:          "Alex",
+				Points:        20,
+				Bet:           10,
+				Payout:        20,
+				Money:         110,
+				Result:        ResultWin,
+			},
+		},
+		{
+			name: "Prompt",
+			event: Event{
+				Type:          EventPrompt,
+				ParticipantId: "a",
+				Name:          "Alex",
+				Money:         90,
+				Prompt:        PromptBet,
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := json.Marshal(tc.event)
+			require.NoError(t, err)
+
+			var event Event
+			require.NoError(t, json.Unmarshal(data, &event))
+			require.Equal(t, tc.event, event)
+		})
+	}
+}
+
+func TestEvent_JSONFields(t *testing.T) {
+	data, err := json.Marshal(Event{
+		Type:  EventRoundStart,
+		Round: 1,
+		Seats: []Seat{{Id: "a", Name: "Alex", Money: 100}},
+	})
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"schemaVersion": 1,
+		"type": "roundStart",
+		"round": 1,
+		"time": "0001-01-01T00:00:00Z",
+		"seats": [{"id": "a", "name": "Alex", "bot": false, "money": 100}]
+	}`, string(data))
+
+	data, err = json.Marshal(Event{Type: EventHit, ParticipantId: "a", Name: "Alex", Cards: deck.MustParseCards("7h"), Points: 17, Bet: 5, Money: 95})
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"schemaVersion": 1,
+		"type": "hit",
+		"round": 0,
+		"time": "0001-01-01T00:00:00Z",
+		"participantId": "a",
+		"name": "Alex",
+		"cards": [{"suit": "heart", "value": "7"}],
+		"points": 17,
+		"bet": 5
+	}`, string(data))
+}
+
+func TestJSONLog_SkipsPrompts(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewJSONLog(&buf)
+
+	log.OnEvent(Event{Type: EventPrompt, Prompt: PromptMove})
+	require.NoError(t, log.Err())
+	require.Empty(t, buf.String())
+}
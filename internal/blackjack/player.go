@@ -15,9 +15,14 @@ type Player struct {
 	Bot     bool
 	IsSaved bool
 	IsLost  bool
+	// Decisions of a bot, not saved with the game
+	strategy Strategy
+	// Input of a user, not saved with the game
+	input Input
 }
 
-func newPlayer(username string, money int, bot bool) (*Player, error) {
+// The Id is taken from the random source of the game, so it is the same for the same seed
+func newPlayer(rnd *random.Random, username string, money int, bot bool) (*Player, error) {
 	if username == "" {
 		return nil, fmt.Errorf("username cannot be empty")
 	}
@@ -27,7 +32,7 @@ func newPlayer(username string, money int, bot bool) (*Player, error) {
 	}
 
 	return &Player{
-		Id:      random.RandString(10),
+		Id:      rnd.RandString(10),
 		Cards:   nil,
 		Money:   money,
 		Bet:     0,
@@ -39,17 +44,8 @@ func newPlayer(username string, money int, bot bool) (*Player, error) {
 }
 
 func (p *Player) getPoints() (int, error) {
-	points := 0
-
-	for _, card := range p.Cards {
-		cost, err := getCardCost(card)
-		if err != nil {
-			return -1, err
-		}
-		points += cost
-	}
-
-	return points, nil
+	points, _, err := CardsPoints(p.Cards)
+	return points, err
 }
 
 func (p *Player) resetRound() {
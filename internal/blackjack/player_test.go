@@ -2,6 +2,7 @@ package blackjack
 
 import (
 	"course/internal/deck"
+	"course/pkg/random"
 	"github.com/stretchr/testify/require"
 	"testing"
 )
@@ -78,7 +79,7 @@ func Test_NewPlayer(t *testing.T) {
 		tc := testCases[i]
 
 		t.Run(tc.name, func(t *testing.T) {
-			p, err := newPlayer(tc.username, tc.money, tc.bot)
+			p, err := newPlayer(random.New(random.Config{}), tc.username, tc.money, tc.bot)
 			tc.check(p, err, tc.username, tc.money, tc.bot)
 		})
 	}
@@ -113,14 +114,8 @@ func TestPlayer_getPoints(t *testing.T) {
 			bot:      false,
 			buildStubs: func(d *Player) {
 				d.Cards = []*deck.Card{
-					{
-						Suit:  deck.Heart,
-						Value: deck.Queen,
-					},
-					{
-						Suit:  deck.Spade,
-						Value: deck.King,
-					},
+					deck.MustNewCard(deck.Heart, deck.Queen),
+					deck.MustNewCard(deck.Spade, deck.King),
 				}
 			},
 			check: func(d *Player, p int, err error) {
@@ -134,7 +129,7 @@ func TestPlayer_getPoints(t *testing.T) {
 		tc := testCases[i]
 
 		t.Run(tc.name, func(t *testing.T) {
-			d, err := newPlayer(tc.username, tc.money, tc.bot)
+			d, err := newPlayer(random.New(random.Config{}), tc.username, tc.money, tc.bot)
 			require.NoError(t, err)
 
 			tc.buildStubs(d)
@@ -0,0 +1,563 @@
+package blackjack
+
+import (
+	"bufio"
+	"course/internal/deck"
+	"course/pkg/random"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidHandHistory = errors.New("invalid hand history")
+	ErrIncompleteHand     = errors.New("hand is not finished in the history")
+	ErrReplayMismatch     = errors.New("replay does not match the recorded hand")
+)
+
+var (
+	historyHandRegexp         = regexp.MustCompile(`^Blackjack Hand #(\d+) - (.+)$`)
+	historySeatRegexp         = regexp.MustCompile(`^Seat (\d+): (.+) \((\d+) c\.\)( \[bot\])?$`)
+	historyDealerShowsRegexp  = regexp.MustCompile(`^Dealer shows \[(.*)\] \(total (\d+)\)$`)
+	historyDealerRevealRegexp = regexp.MustCompile(`^Dealer reveals \[(.*)\] \(total (\d+)\)$`)
+	historyDealerHitRegexp    = regexp.MustCompile(`^Dealer hits \[(.*)\] \(total (\d+)\)$`)
+	historyDealerStandRegexp  = regexp.MustCompile(`^Dealer stands \(total (\d+)\)$`)
+	historyDealRegexp         = regexp.MustCompile(`^Dealt to (.+) \[(.*)\] \(total (\d+)\)$`)
+	historyBetRegexp          = regexp.MustCompile(`^(.+): bets (\d+)$`)
+	historyHitRegexp          = regexp.MustCompile(`^(.+): hits \[(.*)\] \(total (\d+)\)$`)
+	historyStandRegexp        = regexp.MustCompile(`^(.+): stands \(total (\d+)\)$`)
+	historySettlementRegexp   = regexp.MustCompile(`^(.+): (win|draw|defeat), bet (\d+), payout (\d+) \(total (\d+), money (\d+)\)$`)
+)
+
+// RecordedHand
+// One round read from the hand history.
+type RecordedHand struct {
+	Number int
+	Time   time.Time
+	// Table rules from the table line
+	Rules Rules
+	// Players at the table at the start of the round
+	Seats []Seat
+	// Round events in the recorded order, starting with EventRoundStart
+	Events []Event
+}
+
+// ParseHandHistory
+// Read the rounds written by HandHistory.
+func ParseHandHistory(r io.Reader) ([]*RecordedHand, error) {
+	var hands []*RecordedHand
+	var hand *RecordedHand
+
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "***") {
+			continue
+		}
+
+		if match := historyHandRegexp.FindStringSubmatch(line); match != nil {
+			number, _ := strconv.Atoi(match[1])
+			handTime, err := time.ParseInLocation(historyTimeLayout, match[2], time.Local)
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidHandHistory, lineNumber, err)
+			}
+
+			hand = &RecordedHand{
+				Number: number,
+				Time:   handTime,
+			}
+			hand.Events = append(hand.Events, Event{
+				Type:  EventRoundStart,
+				Round: number,
+				Time:  handTime,
+			})
+			hands = append(hands, hand)
+			continue
+		}
+
+		if hand == nil {
+			return nil, fmt.Errorf("%w: line %d: hand header expected", ErrInvalidHandHistory, lineNumber)
+		}
+
+		if err := hand.parseLine(line); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidHandHistory, lineNumber, err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// The seats follow the hand header
+	for _, hand := range hands {
+		hand.Events[0].Seats = hand.Seats
+	}
+
+	return hands, nil
+}
+
+func (h *RecordedHand) parseLine(line string) error {
+	if strings.HasPrefix(line, "Table:") {
+		h.Rules = Rules{
+			DealerHitsSoft17:  strings.Contains(line, historyDealerHitsSoft17),
+			BlackjackPays3To2: strings.Contains(line, historyBlackjackPays3To2),
+			NoHoleCard:        strings.Contains(line, historyNoHoleCard),
+		}
+		return nil
+	}
+
+	if match := historySeatRegexp.FindStringSubmatch(line); match != nil {
+		money, _ := strconv.Atoi(match[3])
+		seat := Seat{
+			Id:    "seat-" + match[1],
+			Name:  match[2],
+			Bot:   match[4] != "",
+			Money: money,
+		}
+
+		h.Seats = append(h.Seats, seat)
+		h.Events[0].Seats = h.Seats
+		return nil
+	}
+
+	if match := historyDealerShowsRegexp.FindStringSubmatch(line); match != nil {
+		return h.addDealerEvent(EventDeal, match[1], match[2])
+	}
+
+	if match := historyDealerRevealRegexp.FindStringSubmatch(line); match != nil {
+		return h.addDealerEvent(EventDealerReveal, match[1], match[2])
+	}
+
+	if match := historyDealerHitRegexp.FindStringSubmatch(line); match != nil {
+		return h.addDealerEvent(EventDealerHit, match[1], match[2])
+	}
+
+	if match := historyDealerStandRegexp.FindStringSubmatch(line); match != nil {
+		return h.addDealerEvent(EventDealerStand, "", match[1])
+	}
+
+	if match := historyDealRegexp.FindStringSubmatch(line); match != nil {
+		return h.addPlayerEvent(Event{Type: EventDeal}, match[1], match[2], match[3])
+	}
+
+	if match := historyBetRegexp.FindStringSubmatch(line); match != nil {
+		bet, _ := strconv.Atoi(match[2])
+		return h.addPlayerEvent(Event{Type: EventBet, Bet: bet}, match[1], "", "0")
+	}
+
+	if match := historyHitRegexp.FindStringSubmatch(line); match != nil {
+		return h.addPlayerEvent(Event{Type: EventHit}, match[1], match[2], match[3])
+	}
+
+	if match := historyStandRegexp.FindStringSubmatch(line); match != nil {
+		return h.addPlayerEvent(Event{Type: EventStand}, match[1], "", match[2])
+	}
+
+	if match := historySettlementRegexp.FindStringSubmatch(line); match != nil {
+		bet, _ := strconv.Atoi(match[3])
+		payout, _ := strconv.Atoi(match[4])
+		money, _ := strconv.Atoi(match[6])
+
+		return h.addPlayerEvent(Event{
+			Type:   EventSettlement,
+			Result: RoundResult(match[2]),
+			Bet:    bet,
+			Payout: payout,
+			Money:  money,
+		}, match[1], "", match[5])
+	}
+
+	return fmt.Errorf("unknown line %q", line)
+}
+
+func (h *RecordedHand) addDealerEvent(eventType EventType, cards string, points string) error {
+	return h.addEvent(Event{
+		Type:          eventType,
+		ParticipantId: dealerName,
+		Name:          dealerName,
+	}, cards, points)
+}
+
+func (h *RecordedHand) addPlayerEvent(event Event, name string, cards string, points string) error {
+	seat := h.findSeat(name)
+	if seat == nil {
+		return fmt.Errorf("unknown player %q", name)
+	}
+
+	event.ParticipantId = seat.Id
+	event.Name = seat.Name
+
+	return h.addEvent(event, cards, points)
+}
+
+func (h *RecordedHand) addEvent(event Event, cards string, points string) error {
+	var err error
+
+	event.Round = h.Number
+	event.Time = h.Time
+
+	event.Points, err = strconv.Atoi(points)
+	if err != nil {
+		return err
+	}
+
+	event.Cards, err = parseHistoryCards(cards)
+	if err != nil {
+		return err
+	}
+
+	h.Events = append(h.Events, event)
+	return nil
+}
+
+func (h *RecordedHand) findSeat(name string) *Seat {
+	for i := range h.Seats {
+		if h.Seats[i].Name == name {
+			return &h.Seats[i]
+		}
+	}
+
+	return nil
+}
+
+// Cards in the order they were taken from the deck.
+// The hidden dealer card goes right after the opened one, without a hole card it is taken as a hit.
+func (h *RecordedHand) deckOrder() ([]*deck.Card, error) {
+	var dealt, hidden, taken []*deck.Card
+	revealed, settled := false, false
+
+	for _, event := range h.Events {
+		switch event.Type {
+		case EventDeal:
+			dealt = append(dealt, event.Cards...)
+		case EventDealerReveal:
+			hidden = event.Cards
+			revealed = true
+		case EventHit, EventDealerHit:
+			taken = append(taken, event.Cards...)
+		case EventSettlement:
+			settled = true
+		}
+	}
+
+	if !revealed || !settled {
+		return nil, ErrIncompleteHand
+	}
+
+	order := append(dealt, hidden...)
+	return append(order, taken...), nil
+}
+
+type replayObserver struct {
+	events []Event
+}
+
+func (o *replayObserver) OnEvent(event Event) {
+	if event.Type != EventRoundStart {
+		o.events = append(o.events, event)
+	}
+}
+
+// VerifyHand
+// Run the engine with the recorded deck order, bets and moves
+// and check that it reproduces the recorded dealer play and settlement.
+func VerifyHand(hand *RecordedHand) error {
+	deckOrder, err := hand.deckOrder()
+	if err != nil {
+		return err
+	}
+
+	// The dealer Id is not recorded
+	dealer, err := newDealer(random.New(random.Config{}))
+	if err != nil {
+		return err
+	}
+
+	players := make([]*Player, 0, len(hand.Seats))
+	playersByName := make(map[string]*Player, len(hand.Seats))
+
+	for _, seat := range hand.Seats {
+		player := &Player{
+			Id:    seat.Id,
+			Money: seat.Money,
+			Name:  seat.Name,
+			Bot:   seat.Bot,
+		}
+		player.checkIsLost()
+
+		players = append(players, player)
+		playersByName[player.Name] = player
+	}
+
+	if len(players) == 0 {
+		return fmt.Errorf("%w: no seats", ErrInvalidHandHistory)
+	}
+
+	observer := &replayObserver{}
+
+	bj := &Blackjack{
+		deck:        deck.FromCards(deckOrder),
+		rules:       hand.Rules,
+		players:     players,
+		botsNumber:  len(players) - 1,
+		dealer:      dealer,
+		currentUser: players[0],
+		out:         io.Discard,
+		delay:       0,
+		round:       hand.Number - 1,
+		observers:   []Observer{observer},
+	}
+
+	bj.startRound()
+	settled := false
+
+	for _, event := range hand.Events {
+		player := playersByName[event.Name]
+
+		switch event.Type {
+		case EventBet:
+			bj.placeBet(player, event.Bet)
+
+		case EventDeal:
+			if !bj.isStartingCardsDistributed {
+				if err := bj.giveCardsToAll(len(event.Cards)); err != nil {
+					return err
+				}
+				bj.isStartingCardsDistributed = true
+			}
+
+		case EventHit:
+			card, err := bj.giveCardToPlayer(player, 1)
+			if err != nil {
+				return err
+			}
+			if err := bj.emitPlayerEvent(EventHit, player, card); err != nil {
+				return err
+			}
+
+		case EventStand:
+			bj.playerSaved(player)
+			if err := bj.emitPlayerEvent(EventStand, player); err != nil {
+				return err
+			}
+
+		case EventDealerReveal:
+			bj.checkAllPlayersSaved()
+			if !bj.isAllPlayersSaved {
+				return fmt.Errorf("%w: dealer plays before all the players saved", ErrReplayMismatch)
+			}
+			if err := bj.stageDealer(); err != nil {
+				return err
+			}
+
+		case EventSettlement:
+			if !settled {
+				if err := bj.printRoundResults(); err != nil {
+					return err
+				}
+				settled = true
+			}
+		}
+	}
+
+	recorded := hand.Events[1:]
+
+	for i := 0; i < len(recorded) || i < len(observer.events); i++ {
+		expected, actual := "", ""
+
+		if i < len(recorded) {
+			expected = historyLine(recorded[i])
+		}
+
+		if i < len(observer.events) {
+			actual = historyLine(observer.events[i])
+		}
+
+		if expected != actual {
+			return fmt.Errorf("%w: hand #%d: expected %q, got %q", ErrReplayMismatch, hand.Number, expected, actual)
+		}
+	}
+
+	return nil
+}
+
+// Replay
+// Steps through the events of a recorded hand.
+type Replay struct {
+	hand *RecordedHand
+	// Index of the last shown event
+	step int
+	// How the cards are printed
+	style deck.Style
+}
+
+func NewReplay(hand *RecordedHand) *Replay {
+	return &Replay{
+		hand:  hand,
+		step:  0,
+		style: deck.StyleShort,
+	}
+}
+
+// SetCardStyle
+// Print the cards in the style.
+func (r *Replay) SetCardStyle(style deck.Style) {
+	r.style = style
+}
+
+// Step
+// Index of the current event.
+func (r *Replay) Step() int {
+	return r.step
+}
+
+// Len
+// Number of the events in the hand.
+func (r *Replay) Len() int {
+	return len(r.hand.Events)
+}
+
+// Next
+// Move one event forward. Returns false at the end of the hand.
+func (r *Replay) Next() bool {
+	if r.step >= len(r.hand.Events)-1 {
+		return false
+	}
+
+	r.step++
+	return true
+}
+
+// Prev
+// Move one event back. Returns false at the start of the hand.
+func (r *Replay) Prev() bool {
+	if r.step <= 0 {
+		return false
+	}
+
+	r.step--
+	return true
+}
+
+// Render
+// Print the table state after the current event.
+func (r *Replay) Render(w io.Writer) error {
+	type seatState struct {
+		cards []*deck.Card
+		bet   int
+		money int
+	}
+
+	seats := make(map[string]*seatState, len(r.hand.Seats))
+	for _, seat := range r.hand.Seats {
+		seats[seat.Name] = &seatState{money: seat.Money}
+	}
+
+	var dealerCards []*deck.Card
+
+	for _, event := range r.hand.Events[:r.step+1] {
+		if event.Name == dealerName {
+			dealerCards = append(dealerCards, event.Cards...)
+			continue
+		}
+
+		seat, ok := seats[event.Name]
+		if !ok {
+			continue
+		}
+
+		seat.cards = append(seat.cards, event.Cards...)
+
+		switch event.Type {
+		case EventBet:
+			seat.bet = event.Bet
+			seat.money -= event.Bet
+		case EventSettlement:
+			seat.money = event.Money
+		}
+	}
+
+	event := r.hand.Events[r.step]
+	fmt.Fprintf(w, "\n--- Hand #%d, step %d/%d: %s ---", r.hand.Number, r.step+1, len(r.hand.Events), historyLine(event))
+
+	for _, seat := range r.hand.Seats {
+		state := seats[seat.Name]
+		printPlayerName(w, fmt.Sprintf("%s (bet %d, money %d)", seat.Name, state.bet, state.money))
+
+		if err := printReplayCards(w, r.style, state.cards); err != nil {
+			return err
+		}
+	}
+
+	printPlayerName(w, dealerName)
+
+	if err := printReplayCards(w, r.style, dealerCards); err != nil {
+		return err
+	}
+	fmt.Fprintln(w)
+
+	return nil
+}
+
+func printReplayCards(w io.Writer, style deck.Style, cards []*deck.Card) error {
+	for _, card := range cards {
+		if err := printCard(w, style, card); err != nil {
+			return err
+		}
+	}
+
+	points, _, err := CardsPoints(cards)
+	if err != nil {
+		return err
+	}
+
+	printTotalPoints(w, points)
+	return nil
+}
+
+// Cards in the notation of ParseCards, or in the "spade 7, heart king" format of the older histories
+func parseHistoryCards(cards string) ([]*deck.Card, error) {
+	if cards == "" {
+		return nil, nil
+	}
+
+	if parsed, err := deck.ParseCards(cards); err == nil {
+		return parsed, nil
+	}
+
+	names := strings.Split(cards, ", ")
+	parsed := make([]*deck.Card, 0, len(names))
+
+	for _, name := range names {
+		fields := strings.Fields(name)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid card %q", name)
+		}
+
+		suit := deck.CardSuit(fields[0])
+		if !suit.Valid() {
+			return nil, fmt.Errorf("invalid card suit %q", fields[0])
+		}
+
+		rank, err := deck.ParseRank(fields[1])
+		if err != nil {
+			return nil, err
+		}
+
+		card, err := deck.NewCard(suit, rank)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, card)
+	}
+
+	return parsed, nil
+}
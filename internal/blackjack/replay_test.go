@@ -0,0 +1,209 @@
+package blackjack
+
+import (
+	"bytes"
+	"course/internal/deck"
+	"github.com/stretchr/testify/require"
+	"io"
+	"strings"
+	"testing"
+)
+
+// Play a whole round through the engine without delays and return its hand history
+func recordTestRound(t *testing.T, rules Rules) string {
+	cfg := getValidTestCfg()
+	cfg.Rules = rules
+
+	var buf bytes.Buffer
+	cfg.Observers = []Observer{NewHandHistory(&buf, cfg)}
+
+	b, err := NewBlackjack(cfg)
+	require.NoError(t, err)
+	b.out = io.Discard
+	b.delay = 0
+
+	b.startRound()
+	for _, player := range b.players {
+		b.placeBet(player, 10)
+	}
+	require.NoError(t, b.giveCardsToAll(2))
+
+	_, err = b.onUserInput(string(ActionTakeCard))
+	require.NoError(t, err)
+	_, err = b.onUserInput(string(ActionPass))
+	require.NoError(t, err)
+
+	for !b.isAllPlayersSaved {
+		require.NoError(t, b.stageBots())
+		b.checkAllPlayersSaved()
+	}
+
+	require.NoError(t, b.stageDealer())
+	require.NoError(t, b.printRoundResults())
+
+	return buf.String()
+}
+
+func TestParseHandHistory(t *testing.T) {
+	testCases := []struct {
+		name    string
+		history func() string
+		check   func(hands []*RecordedHand, err error)
+	}{
+		{
+			name:    "Recorded Round",
+			history: func() string { return recordTestRound(t, Rules{}) + "\n" + recordTestRound(t, Rules{}) },
+			check: func(hands []*RecordedHand, err error) {
+				require.NoError(t, err)
+				require.Len(t, hands, 2)
+				require.Len(t, hands[0].Seats, 3)
+				require.Equal(t, EventRoundStart, hands[0].Events[0].Type)
+				require.Equal(t, hands[0].Seats, hands[0].Events[0].Seats)
+				require.Equal(t, EventSettlement, hands[0].Events[len(hands[0].Events)-1].Type)
+			},
+		},
+		{
+			name:    "Empty",
+			history: func() string { return "" },
+			check: func(hands []*RecordedHand, err error) {
+				require.NoError(t, err)
+				require.Empty(t, hands)
+			},
+		},
+		{
+			name:    "No Hand Header",
+			history: func() string { return "Seat 1: Alex (1000 c.)" },
+			check: func(hands []*RecordedHand, err error) {
+				require.ErrorIs(t, err, ErrInvalidHandHistory)
+			},
+		},
+		{
+			name: "Unknown Player",
+			history: func() string {
+				return "Blackjack Hand #1 - 2026-10-19 11:43:12\nSeat 1: Alex (1000 c.)\nOleg: bets 10"
+			},
+			check: func(hands []*RecordedHand, err error) {
+				require.ErrorIs(t, err, ErrInvalidHandHistory)
+			},
+		},
+		{
+			name: "Older Card Format",
+			history: func() string {
+				return "Blackjack Hand #1 - 2026-10-19 11:43:12\nSeat 1: Alex (1000 c.)\nDealt to Alex [spade 7, heart king] (total 17)"
+			},
+			check: func(hands []*RecordedHand, err error) {
+				require.NoError(t, err)
+				require.Len(t, hands, 1)
+				require.Equal(t, deck.MustParseCards("7s Kh"), hands[0].Events[1].Cards)
+			},
+		},
+		{
+			name: "Invalid Card",
+			history: func() string {
+				return "Blackjack Hand #1 - 2026-10-19 11:43:12\nSeat 1: Alex (1000 c.)\nDealt to Alex [star 7] (total 7)"
+			},
+			check: func(hands []*RecordedHand, err error) {
+				require.ErrorIs(t, err, ErrInvalidHandHistory)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			hands, err := ParseHandHistory(strings.NewReader(tc.history()))
+			tc.check(hands, err)
+		})
+	}
+}
+
+func TestVerifyHand(t *testing.T) {
+	testCases := []struct {
+		name    string
+		rules   Rules
+		history func(history string) string
+		check   func(err error)
+	}{
+		{
+			name:    "Ok",
+			history: func(history string) string { return history },
+			check: func(err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:    "Rule Variants",
+			rules:   Rules{DealerHitsSoft17: true, BlackjackPays3To2: true, NoHoleCard: true},
+			history: func(history string) string { return history },
+			check: func(err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "Changed Payout",
+			history: func(history string) string {
+				return strings.Replace(history, "payout", "payout 1", 1)
+			},
+			check: func(err error) {
+				require.Error(t, err)
+			},
+		},
+		{
+			name: "Changed Dealer Total",
+			history: func(history string) string {
+				return strings.Replace(history, "Dealer stands (total ", "Dealer stands (total 1", 1)
+			},
+			check: func(err error) {
+				require.ErrorIs(t, err, ErrReplayMismatch)
+			},
+		},
+		{
+			name: "Unfinished Hand",
+			history: func(history string) string {
+				return history[:strings.Index(history, "*** DEALER ***")]
+			},
+			check: func(err error) {
+				require.ErrorIs(t, err, ErrIncompleteHand)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			hands, err := ParseHandHistory(strings.NewReader(tc.history(recordTestRound(t, tc.rules))))
+			if err != nil {
+				tc.check(err)
+				return
+			}
+			require.Len(t, hands, 1)
+
+			tc.check(VerifyHand(hands[0]))
+		})
+	}
+}
+
+func TestReplay(t *testing.T) {
+	hands, err := ParseHandHistory(strings.NewReader(recordTestRound(t, Rules{})))
+	require.NoError(t, err)
+	require.Len(t, hands, 1)
+
+	r := NewReplay(hands[0])
+	require.Equal(t, 0, r.Step())
+	require.False(t, r.Prev())
+
+	for r.Next() {
+	}
+	require.Equal(t, r.Len()-1, r.Step())
+
+	var buf bytes.Buffer
+	require.NoError(t, r.Render(&buf))
+	require.Contains(t, buf.String(), "Hand #1, step")
+	require.Contains(t, buf.String(), "dealer:")
+	require.Contains(t, buf.String(), "Alex (bet 10, money")
+
+	require.True(t, r.Prev())
+	require.Equal(t, r.Len()-2, r.Step())
+}
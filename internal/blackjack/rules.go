@@ -0,0 +1,48 @@
+package blackjack
+
+import "course/internal/deck"
+
+// DealerTakesCard
+// Whether the dealer with the cards takes one more card.
+func (r Rules) DealerTakesCard(cards []*deck.Card) (bool, error) {
+	points, soft, err := CardsPoints(cards)
+	if err != nil {
+		return false, err
+	}
+
+	if points <= DealerPointsTakeCardLimit {
+		return true, nil
+	}
+
+	return r.DealerHitsSoft17 && soft && points == DealerPointsTakeCardLimit+1, nil
+}
+
+// Settle
+// Result of the player hand against the final dealer hand and the coins returned to the player,
+// including the bet.
+func (r Rules) Settle(playerCards []*deck.Card, dealerCards []*deck.Card, bet int) (RoundResult, int, error) {
+	playerPoints, _, err := CardsPoints(playerCards)
+	if err != nil {
+		return "", 0, err
+	}
+
+	dealerPoints, _, err := CardsPoints(dealerCards)
+	if err != nil {
+		return "", 0, err
+	}
+
+	playerNatural := r.BlackjackPays3To2 && IsNatural(playerCards)
+	dealerNatural := r.BlackjackPays3To2 && IsNatural(dealerCards)
+
+	if playerNatural && !dealerNatural {
+		return ResultWin, bet + bet + bet/2, nil
+	} else if dealerNatural && !playerNatural {
+		return ResultDefeat, 0, nil
+	} else if (playerPoints > dealerPoints || dealerPoints > MaxPoints) && playerPoints <= MaxPoints {
+		return ResultWin, bet + bet, nil
+	} else if playerPoints == dealerPoints && playerPoints <= MaxPoints {
+		return ResultDraw, bet, nil
+	}
+
+	return ResultDefeat, 0, nil
+}
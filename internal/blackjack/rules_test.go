@@ -0,0 +1,78 @@
+package blackjack
+
+import (
+	"course/internal/deck"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestRules_Settle(t *testing.T) {
+	natural := deck.MustParseCards("Ah Ks")
+	twenty := deck.MustParseCards("Qh Ks")
+	bust := deck.MustParseCards("Qh Ks 5c")
+
+	testCases := []struct {
+		name        string
+		rules       Rules
+		playerCards []*deck.Card
+		dealerCards []*deck.Card
+		result      RoundResult
+		payout      int
+	}{
+		{
+			name:        "Natural Pays 3 To 2",
+			rules:       Rules{BlackjackPays3To2: true},
+			playerCards: natural,
+			dealerCards: twenty,
+			result:      ResultWin,
+			payout:      25,
+		},
+		{
+			name:        "Natural Pays 1 To 1",
+			playerCards: natural,
+			dealerCards: twenty,
+			result:      ResultWin,
+			payout:      20,
+		},
+		{
+			name:        "Dealer Bust",
+			playerCards: twenty,
+			dealerCards: bust,
+			result:      ResultWin,
+			payout:      20,
+		},
+		{
+			name:        "Both Bust",
+			playerCards: bust,
+			dealerCards: bust,
+			result:      ResultDefeat,
+			payout:      0,
+		},
+		{
+			name:        "Push",
+			playerCards: twenty,
+			dealerCards: twenty,
+			result:      ResultDraw,
+			payout:      10,
+		},
+		{
+			name:        "Dealer Natural",
+			rules:       Rules{BlackjackPays3To2: true},
+			playerCards: twenty,
+			dealerCards: natural,
+			result:      ResultDefeat,
+			payout:      0,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			result, payout, err := tc.rules.Settle(tc.playerCards, tc.dealerCards, 10)
+			require.NoError(t, err)
+			require.Equal(t, tc.result, result)
+			require.Equal(t, tc.payout, payout)
+		})
+	}
+}
@@ -0,0 +1,372 @@
+package blackjack
+
+import (
+	"course/internal/counting"
+	"course/internal/deck"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+)
+
+// SaveVersion
+// Version of the save file format. Increase it when the saved state changes.
+const SaveVersion = 3
+
+// DefaultSaveFile
+// File used by the save and load commands when Config.SaveFile is empty.
+const DefaultSaveFile = "blackjack.save"
+
+var (
+	ErrUnsupportedSaveVersion = errors.New("unsupported save file version")
+	ErrInvalidSave            = errors.New("save file is corrupted")
+	ErrSaveConfigMismatch     = errors.New("save file is of a game with another config")
+)
+
+// Round phase at the moment of saving
+type savedPhase struct {
+	Round                      int  `json:"round"`
+	IsStartingCardsDistributed bool `json:"isStartingCardsDistributed"`
+	CurrentTurnIndex           int  `json:"currentTurnIndex"`
+	IsAllPlayersSaved          bool `json:"isAllPlayersSaved"`
+	IsAllSaved                 bool `json:"isAllSaved"`
+}
+
+// Config of the saved game. The save is loaded only into a game of the same config,
+// the shoe, the scores and the counts of the save depend on it
+type savedConfig struct {
+	Rules       Rules   `json:"rules"`
+	DecksNumber int     `json:"decksNumber"`
+	Penetration float64 `json:"penetration"`
+	Trainer     bool    `json:"trainer"`
+	// Counting system of the count quiz, empty if the quiz is off
+	CountSystem string `json:"countSystem,omitempty"`
+	// Strategies of the bots in the seat order
+	BotStrategies []string `json:"botStrategies,omitempty"`
+}
+
+type savedTrainer struct {
+	Round   TrainerScore `json:"round"`
+	Session TrainerScore `json:"session"`
+}
+
+type savedCountQuiz struct {
+	Answers int              `json:"answers"`
+	Correct int              `json:"correct"`
+	Counts  []counting.State `json:"counts"`
+}
+
+type savedGame struct {
+	Version           int          `json:"version"`
+	Deck              []*deck.Card `json:"deck"`
+	NextDeckCardIndex int          `json:"nextDeckCardIndex"`
+	Discard           []*deck.Card `json:"discard,omitempty"`
+	Players           []*Player    `json:"players"`
+	BotsNumber        int          `json:"botsNumber"`
+	Dealer            *Dealer      `json:"dealer"`
+	// Empty in a game of bots only
+	CurrentUserId string      `json:"currentUserId"`
+	Phase         savedPhase  `json:"phase"`
+	Config        savedConfig `json:"config"`
+	// The shoe was shuffled and no round was dealt from it yet
+	Shuffled bool `json:"shuffled"`
+	// Empty if the game is not in the trainer mode
+	Trainer *savedTrainer `json:"trainer,omitempty"`
+	// Empty if the count quiz is off
+	CountQuiz *savedCountQuiz `json:"countQuiz,omitempty"`
+	// Counts of the bots in the seat order, null for the bots that do not count the cards.
+	// Empty if no bot counts the cards
+	BotCounts [][]counting.State `json:"botCounts,omitempty"`
+}
+
+// Config of the game for the save
+func (bj *Blackjack) savedConfig() savedConfig {
+	config := savedConfig{
+		Rules:       bj.rules,
+		DecksNumber: bj.deckOptions.DecksNumber,
+		Penetration: bj.penetration,
+		Trainer:     bj.trainer != nil,
+	}
+
+	if bj.countQuiz != nil {
+		config.CountSystem = bj.countQuiz.tracker.Counters()[0].System().Name
+	}
+
+	for _, player := range bj.players {
+		if player.Bot {
+			config.BotStrategies = append(config.BotStrategies, player.strategy.Name())
+		}
+	}
+
+	return config
+}
+
+// Count trackers of the bots in the seat order, nil for the bots that do not count the cards.
+// nil if no bot counts the cards
+func (bj *Blackjack) botCountTrackers() []*CountTracker {
+	var trackers []*CountTracker
+	counting := false
+
+	for _, player := range bj.players {
+		if !player.Bot {
+			continue
+		}
+
+		var tracker *CountTracker
+		if counter, ok := player.strategy.(*CounterStrategy); ok {
+			tracker = counter.tracker
+			counting = true
+		}
+		trackers = append(trackers, tracker)
+	}
+
+	if !counting {
+		return nil
+	}
+
+	return trackers
+}
+
+// Save
+// Write the whole game state to w.
+func (bj *Blackjack) Save(w io.Writer) error {
+	// A game of bots only has no user
+	currentUserId := ""
+	if bj.currentUser != nil {
+		currentUserId = bj.currentUser.Id
+	}
+
+	save := savedGame{
+		Version:           SaveVersion,
+		Deck:              bj.deck.Cards(),
+		NextDeckCardIndex: bj.deck.Drawn(),
+		Discard:           bj.deck.Discarded(),
+		Players:           bj.players,
+		BotsNumber:        bj.botsNumber,
+		Dealer:            bj.dealer,
+		CurrentUserId:     currentUserId,
+		Phase: savedPhase{
+			Round:                      bj.round,
+			IsStartingCardsDistributed: bj.isStartingCardsDistributed,
+			CurrentTurnIndex:           bj.currentTurnIndex,
+			IsAllPlayersSaved:          bj.isAllPlayersSaved,
+			IsAllSaved:                 bj.isAllSaved,
+		},
+		Config:   bj.savedConfig(),
+		Shuffled: bj.shuffled,
+	}
+
+	if bj.trainer != nil {
+		save.Trainer = &savedTrainer{
+			Round:   bj.trainer.round,
+			Session: bj.trainer.session,
+		}
+	}
+
+	if bj.countQuiz != nil {
+		save.CountQuiz = &savedCountQuiz{
+			Answers: bj.countQuiz.Answers,
+			Correct: bj.countQuiz.Correct,
+			Counts:  bj.countQuiz.tracker.states(),
+		}
+	}
+
+	for _, tracker := range bj.botCountTrackers() {
+		var counts []counting.State
+		if tracker != nil {
+			counts = tracker.states()
+		}
+		save.BotCounts = append(save.BotCounts, counts)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(save)
+}
+
+// Load
+// Replace the game state with the one read from r.
+// The state is left untouched if the save is invalid.
+func (bj *Blackjack) Load(r io.Reader) error {
+	var data json.RawMessage
+
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSave, err)
+	}
+
+	// The version is checked first, the cards of the other versions may not be decoded
+	var header struct {
+		Version int `json:"version"`
+	}
+
+	if err := json.Unmarshal(data, &header); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSave, err)
+	}
+
+	if header.Version != SaveVersion {
+		return fmt.Errorf("%w: %d", ErrUnsupportedSaveVersion, header.Version)
+	}
+
+	var save savedGame
+
+	if err := json.Unmarshal(data, &save); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSave, err)
+	}
+
+	if save.Dealer == nil || len(save.Players) == 0 {
+		return ErrInvalidSave
+	}
+
+	if save.NextDeckCardIndex < 0 || save.NextDeckCardIndex > len(save.Deck) {
+		return ErrInvalidSave
+	}
+
+	var currentUser *Player
+	hasUsers := false
+	bots := 0
+
+	for _, player := range save.Players {
+		if player == nil {
+			return ErrInvalidSave
+		}
+
+		if player.Bot {
+			bots++
+		} else {
+			hasUsers = true
+		}
+
+		if save.CurrentUserId != "" && player.Id == save.CurrentUserId {
+			currentUser = player
+		}
+	}
+
+	// The current user is only missing in a game of bots only
+	if currentUser == nil && (save.CurrentUserId != "" || hasUsers) {
+		return ErrInvalidSave
+	}
+
+	// The strategies of the config are the ones of the bot seats
+	if bots != len(save.Config.BotStrategies) {
+		return ErrInvalidSave
+	}
+
+	shoe := deck.FromCards(save.Deck)
+	if _, err := shoe.Draw(save.NextDeckCardIndex); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSave, err)
+	}
+	shoe.Discard(save.Discard...)
+
+	if config := bj.savedConfig(); !reflect.DeepEqual(save.Config, config) {
+		return fmt.Errorf("%w: %+v, the game has %+v", ErrSaveConfigMismatch, save.Config, config)
+	}
+
+	if err := bj.checkSavedScores(save); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSave, err)
+	}
+
+	bj.deck = shoe
+	bj.players = save.Players
+	bj.botsNumber = save.BotsNumber
+	bj.dealer = save.Dealer
+	bj.currentUser = currentUser
+	bj.round = save.Phase.Round
+	bj.isStartingCardsDistributed = save.Phase.IsStartingCardsDistributed
+	bj.currentTurnIndex = save.Phase.CurrentTurnIndex
+	bj.isAllPlayersSaved = save.Phase.IsAllPlayersSaved
+	bj.isAllSaved = save.Phase.IsAllSaved
+	bj.shuffled = save.Shuffled
+	bj.assignBotStrategies()
+	bj.assignUserInputs()
+	bj.restoreSavedScores(save)
+
+	return nil
+}
+
+// The scores and the counts of the save fit the trainer, the count quiz and the bots of the game
+func (bj *Blackjack) checkSavedScores(save savedGame) error {
+	if (save.Trainer != nil) != (bj.trainer != nil) {
+		return errors.New("trainer score does not match the trainer mode")
+	}
+
+	if (save.CountQuiz != nil) != (bj.countQuiz != nil) {
+		return errors.New("count quiz score does not match the count quiz")
+	}
+
+	if save.CountQuiz != nil {
+		if err := bj.countQuiz.tracker.checkStates(save.CountQuiz.Counts); err != nil {
+			return fmt.Errorf("count quiz: %w", err)
+		}
+	}
+
+	trackers := bj.botCountTrackers()
+	if len(save.BotCounts) != len(trackers) {
+		return fmt.Errorf("counts of %d bots for %d bots", len(save.BotCounts), len(trackers))
+	}
+
+	for i, tracker := range trackers {
+		if tracker == nil {
+			if save.BotCounts[i] != nil {
+				return fmt.Errorf("counts of the bot %d that does not count the cards", i+1)
+			}
+			continue
+		}
+
+		if err := tracker.checkStates(save.BotCounts[i]); err != nil {
+			return fmt.Errorf("bot %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// Continue the scores and the counts of the save, they are checked with checkSavedScores first
+func (bj *Blackjack) restoreSavedScores(save savedGame) {
+	if save.Trainer != nil {
+		bj.trainer.round = save.Trainer.Round
+		bj.trainer.session = save.Trainer.Session
+	}
+
+	if save.CountQuiz != nil {
+		bj.countQuiz.Answers = save.CountQuiz.Answers
+		bj.countQuiz.Correct = save.CountQuiz.Correct
+		bj.countQuiz.tracker.restore(save.CountQuiz.Counts)
+	}
+
+	for i, tracker := range bj.botCountTrackers() {
+		if tracker != nil {
+			tracker.restore(save.BotCounts[i])
+		}
+	}
+}
+
+// SaveFile
+// Save the game to the file at path.
+func (bj *Blackjack) SaveFile(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err = bj.Save(file); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// LoadFile
+// Load the game from the file at path.
+func (bj *Blackjack) LoadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return bj.Load(file)
+}
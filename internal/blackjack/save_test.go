@@ -0,0 +1,360 @@
+package blackjack
+
+import (
+	"bytes"
+	"course/internal/deck"
+	"github.com/stretchr/testify/require"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBlackjack_SaveLoad(t *testing.T) {
+	cfg := getValidTestCfg()
+	// Both games get the same random source, it is a part of the bot strategies
+	cfg.Seed = 1
+
+	testCases := []struct {
+		name  string
+		check func(original *Blackjack, resumed *Blackjack)
+	}{
+		{
+			name: "Same State",
+			check: func(original *Blackjack, resumed *Blackjack) {
+				require.Equal(t, original.deck, resumed.deck)
+				require.Equal(t, original.deck.Drawn(), resumed.deck.Drawn())
+				require.Equal(t, original.players, resumed.players)
+				require.Equal(t, original.dealer, resumed.dealer)
+				require.Equal(t, original.currentUser.Id, resumed.currentUser.Id)
+				require.Same(t, resumed.players[0], resumed.currentUser)
+				require.Equal(t, original.isStartingCardsDistributed, resumed.isStartingCardsDistributed)
+				require.Equal(t, original.currentTurnIndex, resumed.currentTurnIndex)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			original, err := NewBlackjack(cfg)
+			require.NoError(t, err)
+
+			for _, player := range original.players {
+				player.Bet = 10
+				player.Money -= 10
+			}
+
+			require.NoError(t, original.giveCardsToAll(2))
+			original.isStartingCardsDistributed = true
+
+			var buf bytes.Buffer
+			require.NoError(t, original.Save(&buf))
+
+			resumed, err := NewBlackjack(cfg)
+			require.NoError(t, err)
+			require.NoError(t, resumed.Load(&buf))
+
+			tc.check(original, resumed)
+		})
+	}
+}
+
+func TestBlackjack_SaveLoadScores(t *testing.T) {
+	cfg := getValidTestCfg()
+	cfg.Seats = []SeatConfig{{Name: "Alex"}, {Name: "Bender", Bot: true}, {Name: "Fry", Bot: true}}
+	cfg.BotStrategyNames = []string{StrategyCounter, StrategyNaive}
+	cfg.Trainer = true
+	cfg.CountQuiz = true
+	cfg.CountSystem = "ko"
+	cfg.DecksNumber = 2
+	cfg.Penetration = 0.5
+	cfg.Rules = Rules{DealerHitsSoft17: true}
+	cfg.Seed = 1
+	cfg.Output = io.Discard
+
+	original, err := NewBlackjack(cfg)
+	require.NoError(t, err)
+
+	original.shuffled = false
+	original.trainer.round = TrainerScore{Decisions: 2, Mistakes: 1}
+	original.trainer.session = TrainerScore{Decisions: 9, Mistakes: 3}
+	original.countQuiz.Answers = 4
+	original.countQuiz.Correct = 3
+	counter := original.players[1].strategy.(*CounterStrategy)
+	hit := Event{Type: EventHit, Cards: deck.MustParseCards("5h 6s Kd")}
+	original.countQuiz.tracker.OnEvent(hit)
+	counter.OnEvent(hit)
+	counter.OnEvent(hit)
+
+	var buf bytes.Buffer
+	require.NoError(t, original.Save(&buf))
+	save := buf.String()
+
+	resumed, err := NewBlackjack(cfg)
+	require.NoError(t, err)
+	require.NoError(t, resumed.Load(strings.NewReader(save)))
+
+	require.Equal(t, original.rules, resumed.rules)
+	require.Equal(t, original.deckOptions.DecksNumber, resumed.deckOptions.DecksNumber)
+	require.Equal(t, original.penetration, resumed.penetration)
+	require.False(t, resumed.shuffled)
+	require.Equal(t, *original.trainer, *resumed.trainer)
+	require.Equal(t, original.countQuiz.Answers, resumed.countQuiz.Answers)
+	require.Equal(t, original.countQuiz.Correct, resumed.countQuiz.Correct)
+	require.Equal(t, original.countQuiz.tracker.states(), resumed.countQuiz.tracker.states())
+	require.Equal(t, 3, resumed.countQuiz.tracker.states()[0].Seen)
+	resumedCounter := resumed.players[1].strategy.(*CounterStrategy)
+	require.Equal(t, counter.tracker.states(), resumedCounter.tracker.states())
+	require.Equal(t, 6, resumedCounter.tracker.states()[0].Seen)
+	require.Equal(t, counter.TrueCount(), resumedCounter.TrueCount())
+
+	// The counts are checked before the state is replaced
+	fresh, err := NewBlackjack(cfg)
+	require.NoError(t, err)
+	require.Contains(t, save, `"seen": 3`)
+	err = fresh.Load(strings.NewReader(strings.Replace(save, `"seen": 3`, `"seen": -3`, 1)))
+	require.ErrorIs(t, err, ErrInvalidSave)
+	require.Zero(t, fresh.countQuiz.Answers)
+	require.True(t, fresh.shuffled)
+
+	// A save is loaded only into a game of the same config
+	testCases := []struct {
+		name   string
+		change func(c *Config)
+	}{
+		{name: "Rules", change: func(c *Config) { c.Rules = Rules{} }},
+		{name: "Decks Number", change: func(c *Config) { c.DecksNumber = 6 }},
+		{name: "Penetration", change: func(c *Config) { c.Penetration = 0.75 }},
+		{name: "Trainer", change: func(c *Config) { c.Trainer = false }},
+		{name: "Count System", change: func(c *Config) { c.CountSystem = "hi-lo" }},
+		{name: "Count Quiz", change: func(c *Config) { c.CountQuiz = false }},
+		{name: "Bot Strategies", change: func(c *Config) { c.BotStrategyNames = []string{StrategyNaive, StrategyCounter} }},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			other := cfg
+			tc.change(&other)
+
+			b, err := NewBlackjack(other)
+			require.NoError(t, err)
+
+			err = b.Load(strings.NewReader(save))
+			require.ErrorIs(t, err, ErrSaveConfigMismatch)
+			require.True(t, b.shuffled)
+		})
+	}
+}
+
+// Events from the first move of the user to the end of the round, without the times
+func getTestRoundEnd(t *testing.T, events []Event) []Event {
+	var round []Event
+
+	for _, event := range events {
+		if len(round) == 0 && event.Type != EventHit && event.Type != EventStand {
+			continue
+		}
+
+		if event.Type == EventRoundStart {
+			break
+		}
+
+		event.Time = time.Time{}
+		round = append(round, event)
+	}
+
+	require.NotEmpty(t, round)
+	return round
+}
+
+func TestBlackjack_SaveLoadRound(t *testing.T) {
+	basic, err := NewStrategy(StrategyBasic, nil)
+	require.NoError(t, err)
+
+	cfg := getValidTestCfg()
+	cfg.Seats = []SeatConfig{{Name: "Alex"}, {Name: "Bender", Bot: true}, {Name: "Fry", Bot: true}}
+	cfg.BotStrategies = []Strategy{basic, basic}
+	cfg.Seed = 1
+	cfg.NoDelay = true
+	cfg.Output = io.Discard
+	cfg.SaveFile = filepath.Join(t.TempDir(), "game.save")
+
+	// Alex saves the game after the deal and stands, the bots and the dealer play the round out
+	original := &eventRecorder{}
+	cfg.Observers = []Observer{original}
+	cfg.Input = &scriptInput{lines: []string{"10", string(ActionSave), string(ActionPass), ""}}
+
+	b, err := NewBlackjack(cfg)
+	require.NoError(t, err)
+	require.NoError(t, b.Run())
+
+	// The resumed game goes on from the deal with the same moves
+	resumed := &eventRecorder{}
+	cfg.Observers = []Observer{resumed}
+	cfg.Input = &scriptInput{lines: []string{string(ActionPass), ""}}
+
+	r, err := NewBlackjack(cfg)
+	require.NoError(t, err)
+	require.NoError(t, r.LoadFile(cfg.SaveFile))
+	require.NoError(t, r.Run())
+
+	originalRound := getTestRoundEnd(t, original.events)
+	resumedRound := getTestRoundEnd(t, resumed.events)
+
+	require.Equal(t, 3, countEvents(originalRound, EventSettlement))
+	require.Equal(t, originalRound, resumedRound)
+	require.Equal(t, b.players[0].Money, r.players[0].Money)
+}
+
+func countEvents(events []Event, eventType EventType) int {
+	count := 0
+	for _, event := range events {
+		if event.Type == eventType {
+			count++
+		}
+	}
+
+	return count
+}
+
+func TestBlackjack_SaveBotsOnly(t *testing.T) {
+	cfg := getValidTestCfg()
+	cfg.BotsOnly = true
+	cfg.Seed = 1
+	cfg.NoDelay = true
+	cfg.Output = io.Discard
+
+	b, err := NewBlackjack(cfg)
+	require.NoError(t, err)
+	require.NoError(t, b.Simulate(2))
+
+	var buf bytes.Buffer
+	require.NoError(t, b.Save(&buf))
+	require.Contains(t, buf.String(), `"currentUserId": ""`)
+
+	resumed, err := NewBlackjack(cfg)
+	require.NoError(t, err)
+	require.NoError(t, resumed.Load(&buf))
+	require.Nil(t, resumed.currentUser)
+	require.Len(t, resumed.players, len(b.players))
+	for i, player := range b.players {
+		require.Equal(t, player.Id, resumed.players[i].Id)
+		require.Equal(t, player.Money, resumed.players[i].Money)
+	}
+	require.NoError(t, resumed.Simulate(1))
+}
+
+func TestBlackjack_Load(t *testing.T) {
+	cfg := getValidTestCfg()
+
+	testCases := []struct {
+		name  string
+		save  string
+		check func(b *Blackjack, err error)
+	}{
+		{
+			name: "Not JSON",
+			save: "blackjack",
+			check: func(b *Blackjack, err error) {
+				require.ErrorIs(t, err, ErrInvalidSave)
+			},
+		},
+		{
+			name: "Unsupported Version",
+			save: `{"version": 100}`,
+			check: func(b *Blackjack, err error) {
+				require.ErrorIs(t, err, ErrUnsupportedSaveVersion)
+			},
+		},
+		{
+			name: "Cards With Values",
+			save: `{"version": 1, "deck": [{"Suit": "spade", "Value": "ace"}]}`,
+			check: func(b *Blackjack, err error) {
+				require.ErrorIs(t, err, ErrUnsupportedSaveVersion)
+			},
+		},
+		{
+			name: "Invalid Card",
+			save: `{"version": 3, "deck": [{"Suit": "spade", "Rank": "11"}], "dealer": {}, "players": [{"Id": "a"}], "currentUserId": "a"}`,
+			check: func(b *Blackjack, err error) {
+				require.ErrorIs(t, err, ErrInvalidSave)
+			},
+		},
+		{
+			name: "Joker Of Spades",
+			save: `{"version": 3, "deck": [{"Suit": "spade", "Rank": "joker"}], "dealer": {}, "players": [{"Id": "a"}], "currentUserId": "a"}`,
+			check: func(b *Blackjack, err error) {
+				require.ErrorIs(t, err, ErrInvalidSave)
+			},
+		},
+		{
+			name: "No Players",
+			save: `{"version": 3, "dealer": {}}`,
+			check: func(b *Blackjack, err error) {
+				require.ErrorIs(t, err, ErrInvalidSave)
+			},
+		},
+		{
+			name: "Unknown Current User",
+			save: `{"version": 3, "dealer": {}, "players": [{"Id": "a"}], "currentUserId": "b"}`,
+			check: func(b *Blackjack, err error) {
+				require.ErrorIs(t, err, ErrInvalidSave)
+				require.Len(t, b.players, 1+cfg.BotsNumber)
+			},
+		},
+		{
+			name: "No Current User",
+			save: `{"version": 3, "dealer": {}, "players": [{"Id": "a"}], "currentUserId": ""}`,
+			check: func(b *Blackjack, err error) {
+				require.ErrorIs(t, err, ErrInvalidSave)
+			},
+		},
+		{
+			name: "Deck Index Out Of Range",
+			save: `{"version": 3, "dealer": {}, "players": [{"Id": "a"}], "currentUserId": "a", "nextDeckCardIndex": 1}`,
+			check: func(b *Blackjack, err error) {
+				require.ErrorIs(t, err, ErrInvalidSave)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := NewBlackjack(cfg)
+			require.NoError(t, err)
+
+			err = b.Load(strings.NewReader(tc.save))
+			tc.check(b, err)
+		})
+	}
+}
+
+func TestBlackjack_onUserInputSaveLoad(t *testing.T) {
+	cfg := getValidTestCfg()
+	cfg.SaveFile = filepath.Join(t.TempDir(), "game.save")
+
+	b, err := NewBlackjack(cfg)
+	require.NoError(t, err)
+	require.NoError(t, b.giveCardsToAll(2))
+
+	res, err := b.onUserInput(string(ActionSave))
+	require.NoError(t, err)
+	require.False(t, res)
+
+	savedCards := b.currentUser.Cards
+
+	_, err = b.giveCardToPlayer(b.currentUser, 1)
+	require.NoError(t, err)
+
+	res, err = b.onUserInput(string(ActionLoad))
+	require.NoError(t, err)
+	require.False(t, res)
+	require.Equal(t, savedCards, b.currentUser.Cards)
+}
@@ -0,0 +1,137 @@
+package blackjack
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// PlayerStats
+// Results of a player over the observed rounds.
+type PlayerStats struct {
+	Name string
+	Bot  bool
+	// Rounds in which the player had a seat
+	Rounds     int
+	Wins       int
+	Draws      int
+	Defeats    int
+	Busts      int
+	Blackjacks int
+	// Sum of the bets
+	Wagered int
+	// Money of the player before the first and after the last observed round
+	StartingMoney int
+	Money         int
+}
+
+// Net
+// Money won or lost over the rounds.
+func (s PlayerStats) Net() int {
+	return s.Money - s.StartingMoney
+}
+
+// Stats
+// Collects the results of the players from the game events. Players are told apart by name,
+// so the events of several games or hand histories can be collected together.
+type Stats struct {
+	rounds  int
+	players []*PlayerStats
+	byName  map[string]*PlayerStats
+}
+
+func NewStats() *Stats {
+	return &Stats{
+		byName: make(map[string]*PlayerStats),
+	}
+}
+
+// Rounds
+// Number of the observed rounds.
+func (s *Stats) Rounds() int {
+	return s.rounds
+}
+
+// Players
+// Results of the players in the order they were first seen.
+func (s *Stats) Players() []PlayerStats {
+	players := make([]PlayerStats, 0, len(s.players))
+
+	for _, player := range s.players {
+		players = append(players, *player)
+	}
+
+	return players
+}
+
+func (s *Stats) OnEvent(event Event) {
+	switch event.Type {
+	case EventRoundStart:
+		s.rounds++
+
+		for _, seat := range event.Seats {
+			player := s.byName[seat.Name]
+			if player == nil {
+				player = &PlayerStats{
+					Name:          seat.Name,
+					Bot:           seat.Bot,
+					StartingMoney: seat.Money,
+				}
+				s.players = append(s.players, player)
+				s.byName[seat.Name] = player
+			}
+
+			player.Rounds++
+			player.Money = seat.Money
+		}
+
+	case EventDeal:
+		if player := s.byName[event.Name]; player != nil && event.Points == MaxPoints {
+			player.Blackjacks++
+		}
+
+	case EventHit:
+		if player := s.byName[event.Name]; player != nil && event.Points > MaxPoints {
+			player.Busts++
+		}
+
+	case EventSettlement:
+		player := s.byName[event.Name]
+		if player == nil {
+			return
+		}
+
+		player.Wagered += event.Bet
+		player.Money = event.Money
+
+		switch event.Result {
+		case ResultWin:
+			player.Wins++
+		case ResultDraw:
+			player.Draws++
+		case ResultDefeat:
+			player.Defeats++
+		}
+	}
+}
+
+// Report
+// Print a table with the results of the players.
+func (s *Stats) Report(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Rounds: %d\n", s.rounds)
+	fmt.Fprintf(tw, "Player\tRounds\tWins\tDraws\tDefeats\tBusts\tBlackjacks\tWagered\tMoney\tNet\n")
+
+	for _, player := range s.players {
+		name := player.Name
+		if player.Bot {
+			name += " [bot]"
+		}
+
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%+d\n", name, player.Rounds, player.Wins, player.Draws,
+			player.Defeats, player.Busts, player.Blackjacks, player.Wagered, player.Money, player.Net())
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,66 @@
+package blackjack
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/require"
+	"io"
+	"testing"
+)
+
+func TestStats_OnEvent(t *testing.T) {
+	s := NewStats()
+
+	events := []Event{
+		{Type: EventRoundStart, Seats: []Seat{{Name: "Alex", Money: 100}, {Name: "Anton", Bot: true, Money: 100}}},
+		{Type: EventBet, Name: "Alex", Bet: 10, Money: 90},
+		{Type: EventBet, Name: "Anton", Bet: 20, Money: 80},
+		{Type: EventDeal, Name: "Alex", Points: 21},
+		{Type: EventDeal, Name: "Anton", Points: 12},
+		{Type: EventDeal, Name: dealerName, Points: 10},
+		{Type: EventHit, Name: "Anton", Points: 22},
+		{Type: EventSettlement, Name: "Alex", Bet: 10, Payout: 25, Money: 115, Result: ResultWin},
+		{Type: EventSettlement, Name: "Anton", Bet: 20, Money: 80, Result: ResultDefeat},
+		{Type: EventRoundStart, Seats: []Seat{{Name: "Alex", Money: 115}, {Name: "Anton", Bot: true, Money: 80}}},
+		{Type: EventSettlement, Name: "Alex", Bet: 15, Payout: 15, Money: 115, Result: ResultDraw},
+		{Type: EventSettlement, Name: "Anton", Bet: 0, Money: 80, Result: ResultDefeat},
+	}
+
+	for _, event := range events {
+		s.OnEvent(event)
+	}
+
+	require.Equal(t, 2, s.Rounds())
+	require.Equal(t, []PlayerStats{
+		{Name: "Alex", Rounds: 2, Wins: 1, Draws: 1, Blackjacks: 1, Wagered: 25, StartingMoney: 100, Money: 115},
+		{Name: "Anton", Bot: true, Rounds: 2, Defeats: 2, Busts: 1, Wagered: 20, StartingMoney: 100, Money: 80},
+	}, s.Players())
+	require.Equal(t, 15, s.Players()[0].Net())
+
+	var buf bytes.Buffer
+	require.NoError(t, s.Report(&buf))
+	require.Contains(t, buf.String(), "Rounds: 2")
+	require.Contains(t, buf.String(), "Anton [bot]")
+	require.Contains(t, buf.String(), "+15")
+}
+
+func TestStats_Simulate(t *testing.T) {
+	s := NewStats()
+
+	cfg := getValidTestCfg()
+	cfg.BotsOnly = true
+	cfg.NoDelay = true
+	cfg.Output = io.Discard
+	cfg.Observers = []Observer{s}
+
+	b, err := NewBlackjack(cfg)
+	require.NoError(t, err)
+	require.NoError(t, b.Simulate(30))
+
+	require.Equal(t, 30, s.Rounds())
+
+	for i, player := range s.Players() {
+		require.Equal(t, 30, player.Rounds)
+		require.Equal(t, player.Rounds, player.Wins+player.Draws+player.Defeats)
+		require.Equal(t, b.players[i].Money, player.Money)
+	}
+}
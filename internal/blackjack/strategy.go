@@ -0,0 +1,213 @@
+package blackjack
+
+import (
+	"course/internal/advisor"
+	"course/internal/deck"
+	"course/pkg/random"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Move
+// Move of a player on his turn, the same as the moves of the advisor.
+type Move = advisor.Move
+
+// Moves of a player. The game allows only MoveHit and MoveStand,
+// the other moves are given by the strategy charts
+const (
+	MoveHit       = advisor.MoveHit
+	MoveStand     = advisor.MoveStand
+	MoveDouble    = advisor.MoveDouble
+	MoveSplit     = advisor.MoveSplit
+	MoveSurrender = advisor.MoveSurrender
+)
+
+// Moves allowed by the game
+var legalMoves = []Move{MoveHit, MoveStand}
+
+// Names of the built-in strategies
+const (
+	StrategyNaive   = "naive"
+	StrategyDealer  = "dealer"
+	StrategyBasic   = "basic"
+	StrategyRandom  = "random"
+	StrategyCounter = "counter"
+)
+
+var ErrUnknownStrategy = errors.New("unknown strategy")
+
+// BetState
+// What the player knows when making a bet.
+type BetState struct {
+	// Number of the round, starting from 1
+	Round       int
+	Money       int
+	Rules       Rules
+	DecksNumber int
+}
+
+// HandState
+// The player hand on his turn.
+type HandState struct {
+	Cards []*deck.Card
+	// Points of the cards, Soft is true if an ace is counted as 11
+	Points int
+	Soft   bool
+	Bet    int
+	Money  int
+	Rules  Rules
+	// Number of decks in the game
+	DecksNumber int
+}
+
+// Strategy
+// Decisions of a bot seat.
+type Strategy interface {
+	Name() string
+	// Coins to bet, from 0 to the money of the player
+	Bet(state BetState) int
+	// One of the legal moves with the hand against the opened dealer card
+	Decide(hand HandState, dealerUpCard *deck.Card, legalMoves []Move) Move
+}
+
+var strategies = map[string]func(r *random.Random) Strategy{
+	StrategyNaive: func(r *random.Random) Strategy {
+		return &NaiveStrategy{random: r}
+	},
+	StrategyDealer: func(r *random.Random) Strategy {
+		return &DealerStrategy{}
+	},
+	StrategyBasic: func(r *random.Random) Strategy {
+		return &BasicStrategy{}
+	},
+	StrategyRandom: func(r *random.Random) Strategy {
+		return &RandomStrategy{random: r}
+	},
+	StrategyCounter: func(r *random.Random) Strategy {
+		return NewCounterStrategy()
+	},
+}
+
+// NewStrategy
+// Create the built-in strategy by name. The strategy takes the random numbers from r.
+func NewStrategy(name string, r *random.Random) (Strategy, error) {
+	newStrategy, ok := strategies[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q, available strategies: %v", ErrUnknownStrategy, name, StrategyNames())
+	}
+
+	return newStrategy(r), nil
+}
+
+// StrategyNames
+// Sorted names of the built-in strategies.
+func StrategyNames() []string {
+	names := make([]string, 0, len(strategies))
+
+	for name := range strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// NaiveStrategy
+// The original bot: bets a random part of the money and takes cards below 15 points.
+type NaiveStrategy struct {
+	random *random.Random
+}
+
+func (s *NaiveStrategy) Name() string {
+	return StrategyNaive
+}
+
+func (s *NaiveStrategy) Bet(state BetState) int {
+	if state.Money <= 0 {
+		return 0
+	}
+
+	return s.random.RandInt(0, state.Money)
+}
+
+func (s *NaiveStrategy) Decide(hand HandState, dealerUpCard *deck.Card, legalMoves []Move) Move {
+	if hand.Points < 15 {
+		return chooseMove(legalMoves, MoveHit)
+	}
+
+	return chooseMove(legalMoves, MoveStand)
+}
+
+// DealerStrategy
+// Plays by the dealer rules of the table and bets a tenth of the money.
+type DealerStrategy struct{}
+
+func (s *DealerStrategy) Name() string {
+	return StrategyDealer
+}
+
+func (s *DealerStrategy) Bet(state BetState) int {
+	return tenthBet(state.Money)
+}
+
+func (s *DealerStrategy) Decide(hand HandState, dealerUpCard *deck.Card, legalMoves []Move) Move {
+	takeCard, err := hand.Rules.DealerTakesCard(hand.Cards)
+	if err == nil && takeCard {
+		return chooseMove(legalMoves, MoveHit)
+	}
+
+	return chooseMove(legalMoves, MoveStand)
+}
+
+// RandomStrategy
+// Bets and moves at random.
+type RandomStrategy struct {
+	random *random.Random
+}
+
+func (s *RandomStrategy) Name() string {
+	return StrategyRandom
+}
+
+func (s *RandomStrategy) Bet(state BetState) int {
+	if state.Money <= 0 {
+		return 0
+	}
+
+	return s.random.RandInt(1, state.Money+1)
+}
+
+func (s *RandomStrategy) Decide(hand HandState, dealerUpCard *deck.Card, legalMoves []Move) Move {
+	if len(legalMoves) == 0 {
+		return MoveStand
+	}
+
+	return legalMoves[s.random.RandInt(0, len(legalMoves))]
+}
+
+// The first of the moves that is legal, MoveStand if none is legal
+func chooseMove(legalMoves []Move, moves ...Move) Move {
+	for _, move := range moves {
+		for _, legalMove := range legalMoves {
+			if move == legalMove {
+				return move
+			}
+		}
+	}
+
+	return MoveStand
+}
+
+// A tenth of the money, but at least one coin
+func tenthBet(money int) int {
+	if money <= 0 {
+		return 0
+	}
+
+	if money < 10 {
+		return 1
+	}
+
+	return money / 10
+}
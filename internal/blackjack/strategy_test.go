@@ -0,0 +1,267 @@
+package blackjack
+
+import (
+	"bytes"
+	"course/internal/deck"
+	"course/pkg/random"
+	"github.com/stretchr/testify/require"
+	"io"
+	"testing"
+)
+
+func getTestHand(t *testing.T, rules Rules, values ...deck.Rank) HandState {
+	cards := make([]*deck.Card, 0, len(values))
+
+	for _, value := range values {
+		cards = append(cards, deck.MustNewCard(deck.Spade, value))
+	}
+
+	points, soft, err := CardsPoints(cards)
+	require.NoError(t, err)
+
+	return HandState{
+		Cards:       cards,
+		Points:      points,
+		Soft:        soft,
+		Bet:         10,
+		Money:       90,
+		Rules:       rules,
+		DecksNumber: 6,
+	}
+}
+
+func TestStrategy_Decide(t *testing.T) {
+	allMoves := []Move{MoveHit, MoveStand, MoveDouble, MoveSplit, MoveSurrender}
+
+	testCases := []struct {
+		name     string
+		strategy string
+		rules    Rules
+		hand     []deck.Rank
+		dealer   deck.Rank
+		moves    []Move
+		move     Move
+	}{
+		{
+			name:     "Naive Hits 14",
+			strategy: StrategyNaive,
+			hand:     []deck.Rank{deck.Nine, deck.Five},
+			dealer:   deck.Seven,
+			moves:    legalMoves,
+			move:     MoveHit,
+		},
+		{
+			name:     "Naive Stands 15",
+			strategy: StrategyNaive,
+			hand:     []deck.Rank{deck.Nine, deck.Six},
+			dealer:   deck.Ace,
+			moves:    legalMoves,
+			move:     MoveStand,
+		},
+		{
+			name:     "Dealer Stands Soft 17",
+			strategy: StrategyDealer,
+			hand:     []deck.Rank{deck.Ace, deck.Six},
+			dealer:   deck.Seven,
+			moves:    legalMoves,
+			move:     MoveStand,
+		},
+		{
+			name:     "Dealer Hits Soft 17",
+			strategy: StrategyDealer,
+			rules:    Rules{DealerHitsSoft17: true},
+			hand:     []deck.Rank{deck.Ace, deck.Six},
+			dealer:   deck.Seven,
+			moves:    legalMoves,
+			move:     MoveHit,
+		},
+		{
+			name:     "Basic Stands 12 Against 4",
+			strategy: StrategyBasic,
+			hand:     []deck.Rank{deck.King, deck.Two},
+			dealer:   deck.Four,
+			moves:    legalMoves,
+			move:     MoveStand,
+		},
+		{
+			name:     "Basic Hits 16 Against Ten",
+			strategy: StrategyBasic,
+			hand:     []deck.Rank{deck.King, deck.Six},
+			dealer:   deck.Queen,
+			moves:    legalMoves,
+			move:     MoveHit,
+		},
+		{
+			name:     "Basic Doubles Soft 18 Against 4",
+			strategy: StrategyBasic,
+			hand:     []deck.Rank{deck.Ace, deck.Seven},
+			dealer:   deck.Four,
+			moves:    allMoves,
+			move:     MoveDouble,
+		},
+		{
+			name:     "Basic Doubles 11",
+			strategy: StrategyBasic,
+			hand:     []deck.Rank{deck.Five, deck.Six},
+			dealer:   deck.Six,
+			moves:    allMoves,
+			move:     MoveDouble,
+		},
+		{
+			name:     "Basic Doubles 11 Against 10",
+			strategy: StrategyBasic,
+			hand:     []deck.Rank{deck.Five, deck.Six},
+			dealer:   deck.Ten,
+			moves:    allMoves,
+			move:     MoveDouble,
+		},
+		{
+			name:     "Basic Hits 11 Against 10 Without Hole Card",
+			strategy: StrategyBasic,
+			rules:    Rules{NoHoleCard: true},
+			hand:     []deck.Rank{deck.Five, deck.Six},
+			dealer:   deck.Ten,
+			moves:    allMoves,
+			move:     MoveHit,
+		},
+		{
+			name:     "Basic Hits 11 Without Double",
+			strategy: StrategyBasic,
+			hand:     []deck.Rank{deck.Five, deck.Six},
+			dealer:   deck.Six,
+			moves:    legalMoves,
+			move:     MoveHit,
+		},
+		{
+			name:     "Basic Stands Soft 18 Without Double",
+			strategy: StrategyBasic,
+			hand:     []deck.Rank{deck.Ace, deck.Seven},
+			dealer:   deck.Four,
+			moves:    legalMoves,
+			move:     MoveStand,
+		},
+		{
+			name:     "Basic Splits Eights",
+			strategy: StrategyBasic,
+			hand:     []deck.Rank{deck.Eight, deck.Eight},
+			dealer:   deck.Ace,
+			moves:    allMoves,
+			move:     MoveSplit,
+		},
+		{
+			name:     "Basic Hits Eights Without Split",
+			strategy: StrategyBasic,
+			hand:     []deck.Rank{deck.Eight, deck.Eight},
+			dealer:   deck.Ace,
+			moves:    legalMoves,
+			move:     MoveHit,
+		},
+		{
+			name:     "Basic Hits Aces Without Split",
+			strategy: StrategyBasic,
+			hand:     []deck.Rank{deck.Ace, deck.Ace},
+			dealer:   deck.Five,
+			moves:    legalMoves,
+			move:     MoveHit,
+		},
+		{
+			name:     "Random Busted Stands",
+			strategy: StrategyRandom,
+			hand:     []deck.Rank{deck.King, deck.Queen, deck.Five},
+			dealer:   deck.Five,
+			moves:    []Move{MoveStand},
+			move:     MoveStand,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			strategy, err := NewStrategy(tc.strategy, random.New(random.Config{Seed: 1}))
+			require.NoError(t, err)
+			require.Equal(t, tc.strategy, strategy.Name())
+
+			hand := getTestHand(t, tc.rules, tc.hand...)
+			dealerUpCard := deck.MustNewCard(deck.Heart, tc.dealer)
+
+			require.Equal(t, tc.move, strategy.Decide(hand, dealerUpCard, tc.moves))
+		})
+	}
+}
+
+func TestStrategy_Bet(t *testing.T) {
+	r := random.New(random.Config{Seed: 1})
+
+	for _, name := range StrategyNames() {
+		strategy, err := NewStrategy(name, r)
+		require.NoError(t, err)
+
+		for money := 0; money < 50; money++ {
+			bet := strategy.Bet(BetState{Round: 1, Money: money})
+			require.GreaterOrEqual(t, bet, 0, name)
+			require.LessOrEqual(t, bet, money, name)
+		}
+	}
+}
+
+func TestNewStrategy(t *testing.T) {
+	_, err := NewStrategy("card-counter", nil)
+	require.ErrorIs(t, err, ErrUnknownStrategy)
+	require.Equal(t, []string{"basic", "counter", "dealer", "naive", "random"}, StrategyNames())
+}
+
+func TestBlackjack_BotStrategies(t *testing.T) {
+	cfg := getValidTestCfg()
+	cfg.BotsOnly = true
+	cfg.BotsNumber = 3
+	cfg.NoDelay = true
+	cfg.Output = io.Discard
+	cfg.BotStrategies = []Strategy{&BasicStrategy{}, nil}
+
+	b, err := NewBlackjack(cfg)
+	require.NoError(t, err)
+
+	require.Equal(t, StrategyBasic, b.players[0].strategy.Name())
+	require.Equal(t, StrategyNaive, b.players[1].strategy.Name())
+	require.Equal(t, StrategyNaive, b.players[2].strategy.Name())
+	require.NoError(t, b.Simulate(20))
+
+	cfg.BotStrategies = []Strategy{&BasicStrategy{}, &BasicStrategy{}, &BasicStrategy{}, &BasicStrategy{}}
+	_, err = NewBlackjack(cfg)
+	require.ErrorIs(t, err, ErrTooManyBotStrategies)
+}
+
+func TestBlackjack_BotStrategyNames(t *testing.T) {
+	cfg := getValidTestCfg()
+	cfg.BotsOnly = true
+	cfg.BotsNumber = 3
+	cfg.NoDelay = true
+	cfg.Seed = 5
+	cfg.BotStrategyNames = []string{StrategyRandom, StrategyCounter}
+
+	play := func() string {
+		out := &bytes.Buffer{}
+		cfg.Output = out
+
+		b, err := NewBlackjack(cfg)
+		require.NoError(t, err)
+		require.Equal(t, StrategyRandom, b.players[0].strategy.Name())
+		require.Equal(t, StrategyCounter, b.players[1].strategy.Name())
+		require.Equal(t, StrategyNaive, b.players[2].strategy.Name())
+		require.NoError(t, b.Simulate(20))
+
+		return out.String()
+	}
+
+	// The seed repeats the bets and the moves of the random bots
+	require.Equal(t, play(), play())
+
+	cfg.BotStrategyNames = []string{"card-counter"}
+	_, err := NewBlackjack(cfg)
+	require.ErrorIs(t, err, ErrUnknownStrategy)
+
+	cfg.BotStrategyNames = []string{StrategyBasic, StrategyBasic, StrategyBasic, StrategyBasic}
+	_, err = NewBlackjack(cfg)
+	require.ErrorIs(t, err, ErrTooManyBotStrategies)
+}
@@ -0,0 +1,132 @@
+package blackjack
+
+import (
+	"course/internal/advisor"
+	"fmt"
+)
+
+// TrainerScore
+// Decisions of the user compared with the basic strategy.
+type TrainerScore struct {
+	Decisions int
+	Mistakes  int
+}
+
+// Accuracy
+// Percent of the decisions that follow the basic strategy, 100 if there were no decisions.
+func (s TrainerScore) Accuracy() float64 {
+	if s.Decisions == 0 {
+		return 100
+	}
+
+	return float64(s.Decisions-s.Mistakes) * 100 / float64(s.Decisions)
+}
+
+func (s TrainerScore) String() string {
+	return fmt.Sprintf("%d of %d decisions correct (%.1f%%)", s.Decisions-s.Mistakes, s.Decisions, s.Accuracy())
+}
+
+// Trainer
+// Keeps the score of the user in the round and in the whole session.
+type Trainer struct {
+	round   TrainerScore
+	session TrainerScore
+}
+
+// Round
+// Score of the current round.
+func (t *Trainer) Round() TrainerScore {
+	return t.round
+}
+
+// Session
+// Score of all the rounds of the game.
+func (t *Trainer) Session() TrainerScore {
+	return t.session
+}
+
+// Record the user move, true if it is the move of the basic strategy
+func (t *Trainer) record(move Move, advice advisor.Advice) bool {
+	correct := move == advice.Move
+
+	t.round.Decisions++
+	t.session.Decisions++
+
+	if !correct {
+		t.round.Mistakes++
+		t.session.Mistakes++
+	}
+
+	return correct
+}
+
+func (t *Trainer) resetRound() {
+	t.round = TrainerScore{}
+}
+
+// Basic strategy advice for the user hand against the opened dealer card
+func (bj *Blackjack) userAdvice() (advisor.Advice, error) {
+	points, soft, err := CardsPoints(bj.currentUser.Cards)
+	if err != nil {
+		return advisor.Advice{}, err
+	}
+
+	return Advise(HandState{
+		Cards:       bj.currentUser.Cards,
+		Points:      points,
+		Soft:        soft,
+		Bet:         bj.currentUser.Bet,
+		Money:       bj.currentUser.Money,
+		Rules:       bj.rules,
+		DecksNumber: bj.deckOptions.DecksNumber,
+	}, bj.dealer.Cards[0], legalMoves)
+}
+
+func (bj *Blackjack) printHint() error {
+	advice, err := bj.userAdvice()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(bj.out, "\nHint: %s. %s\n", advice.Move, advice.Reason)
+
+	return nil
+}
+
+// Compare the user move with the basic strategy in the trainer mode.
+// A busted hand has nothing to decide and is not scored
+func (bj *Blackjack) trainMove(move Move) error {
+	if bj.trainer == nil {
+		return nil
+	}
+
+	points, err := bj.currentUser.getPoints()
+	if err != nil {
+		return err
+	}
+
+	if points > MaxPoints {
+		return nil
+	}
+
+	advice, err := bj.userAdvice()
+	if err != nil {
+		return err
+	}
+
+	if bj.trainer.record(move, advice) {
+		fmt.Fprintf(bj.out, "\nTrainer: correct, %s\n", advice.Reason)
+	} else {
+		fmt.Fprintf(bj.out, "\nTrainer: mistake! You chose %s, the basic strategy is %s. %s\n", move, advice.Move, advice.Reason)
+	}
+
+	return nil
+}
+
+func (bj *Blackjack) printTrainerRound() {
+	if bj.trainer == nil {
+		return
+	}
+
+	fmt.Fprintf(bj.out, "\nTrainer: %s in the round, %s in the session\n", bj.trainer.Round(), bj.trainer.Session())
+}
@@ -0,0 +1,114 @@
+package blackjack
+
+import (
+	"bytes"
+	"course/internal/deck"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// Game in which the user has hard 16 against the dealer ten
+func getTestTrainerGame(t *testing.T, trainer bool) (*Blackjack, *bytes.Buffer) {
+	var out bytes.Buffer
+
+	cfg := getValidTestCfg()
+	cfg.BotsNumber = 1
+	cfg.NoDelay = true
+	cfg.Output = &out
+	cfg.Trainer = trainer
+
+	b, err := NewBlackjack(cfg)
+	require.NoError(t, err)
+
+	b.deck = deck.FromCards(deck.MustParseCards("Ks 6h Kc 8c Qd 9h 5d"))
+	require.NoError(t, b.giveCardsToAll(2))
+
+	return b, &out
+}
+
+func TestBlackjack_onUserInputHint(t *testing.T) {
+	b, out := getTestTrainerGame(t, false)
+
+	res, err := b.onUserInput(string(ActionHint))
+	require.NoError(t, err)
+	require.False(t, res)
+	require.Contains(t, out.String(), "Hint: hit. hard 16 against 10: hit (EV")
+	require.Len(t, b.currentUser.Cards, 2)
+	require.Nil(t, b.Trainer())
+}
+
+func TestBlackjack_Trainer(t *testing.T) {
+	testCases := []struct {
+		name     string
+		inputs   []Action
+		score    TrainerScore
+		accuracy float64
+		output   string
+	}{
+		{
+			name:     "Correct Hit",
+			inputs:   []Action{ActionTakeCard},
+			score:    TrainerScore{Decisions: 1},
+			accuracy: 100,
+			output:   "Trainer: correct, hard 16 against 10: hit",
+		},
+		{
+			name:     "Mistake Stand",
+			inputs:   []Action{ActionPass},
+			score:    TrainerScore{Decisions: 1, Mistakes: 1},
+			accuracy: 0,
+			output:   "Trainer: mistake! You chose stand, the basic strategy is hit.",
+		},
+		{
+			name:     "Hit And Stand On 21",
+			inputs:   []Action{ActionTakeCard, ActionPass},
+			score:    TrainerScore{Decisions: 2},
+			accuracy: 100,
+			output:   "Trainer: correct, hard 21 against 10: stand",
+		},
+		{
+			name:     "Hint Is Not Scored",
+			inputs:   []Action{ActionHint, ActionViewMyCards},
+			score:    TrainerScore{},
+			accuracy: 100,
+			output:   "Hint: hit.",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			b, out := getTestTrainerGame(t, true)
+
+			for _, input := range tc.inputs {
+				_, err := b.onUserInput(string(input))
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, tc.score, b.Trainer().Round())
+			require.Equal(t, tc.score, b.Trainer().Session())
+			require.Equal(t, tc.accuracy, b.Trainer().Session().Accuracy())
+			require.Contains(t, out.String(), tc.output)
+
+			require.NoError(t, b.resetRound())
+			require.Equal(t, TrainerScore{}, b.Trainer().Round())
+			require.Equal(t, tc.score, b.Trainer().Session())
+		})
+	}
+}
+
+func TestBlackjack_onUserInputExit(t *testing.T) {
+	b, out := getTestTrainerGame(t, true)
+
+	_, err := b.onUserInput(string(ActionPass))
+	require.NoError(t, err)
+
+	b.currentUser.IsSaved = false
+	_, err = b.onUserInput(string(ActionExit))
+	require.ErrorIs(t, err, errExit)
+
+	b.printSummary()
+	require.Contains(t, out.String(), "--- Session summary ---")
+	require.Contains(t, out.String(), "Trainer: 0 of 1 decisions correct (0.0%)")
+}
@@ -2,64 +2,93 @@ package blackjack
 
 import (
 	"course/internal/deck"
+	"course/internal/odds"
+	"course/pkg/random"
+	"errors"
 	"fmt"
-	"log"
-	"strconv"
+	"io"
 )
 
+// ErrNoCardPoints
+// The card has no points in blackjack, like the joker.
+var ErrNoCardPoints = errors.New("card has no points")
+
 func getCardCost(card *deck.Card) (int, error) {
-	switch card.Value {
-	case deck.Ace:
-		{
-			return int(AceCostBig), nil
-		}
+	value, err := odds.CardValue(card)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrNoCardPoints, err)
+	}
 
-	case deck.King:
-		{
-			return int(FaceCost), nil
-		}
+	// The ace is counted as 11 on its own
+	if value == int(AceCostSmall) {
+		return int(AceCostBig), nil
+	}
 
-	case deck.Queen:
-		{
-			return int(FaceCost), nil
-		}
+	return value, nil
+}
+
+// Pick distinct bot names that differ from the taken names, so that the players can be told apart by name
+func getBotNames(r *random.Random, takenNames []string, botsNumber int) []string {
+	taken := make(map[string]bool, len(takenNames))
+	for _, name := range takenNames {
+		taken[name] = true
+	}
+
+	names := make([]string, 0, len(DefaultPlayerNames))
 
-	case deck.Jack:
-		{
-			return int(FaceCost), nil
+	for _, name := range DefaultPlayerNames {
+		if !taken[name] {
+			names = append(names, name)
 		}
+	}
 
-	default:
-		{
-			points, err := strconv.Atoi(string(card.Value))
+	for i := 0; i < botsNumber && i < len(names); i++ {
+		j := i + r.RandInt(0, len(names)-i)
+		names[i], names[j] = names[j], names[i]
+	}
 
-			if err == nil {
-				return points, nil
-			} else {
-				log.Printf("error when converting the card value to a number: %v", err)
-			}
+	return names[:botsNumber]
+}
 
-			log.Printf("\nincorrect card value: %v", card.Value)
-			return 0, nil
-		}
+// CardsPoints
+// Points of the cards. Aces are counted as 11 points while the hand does not exceed MaxPoints,
+// soft is true if an ace is still counted as 11.
+func CardsPoints(cards []*deck.Card) (points int, soft bool, err error) {
+	h, err := odds.HandOf(cards)
+	if err != nil {
+		return -1, false, fmt.Errorf("%w: %v", ErrNoCardPoints, err)
 	}
+
+	points, soft = h.Points()
+	return points, soft, nil
+}
+
+// IsNatural
+// Natural blackjack: MaxPoints with the two starting cards.
+func IsNatural(cards []*deck.Card) bool {
+	if len(cards) != 2 {
+		return false
+	}
+
+	points, _, err := CardsPoints(cards)
+	return err == nil && points == MaxPoints
 }
 
-func printCard(card *deck.Card) error {
+func printCard(w io.Writer, style deck.Style, card *deck.Card) error {
 	cost, err := getCardCost(card)
 	if err != nil {
 		return err
 	} else {
-		fmt.Printf("\n%s %s. Gives %d points", card.Suit, card.Value, cost)
+		fmt.Fprintf(w, "\n%s. Gives %d points", card.Format(style), cost)
 	}
 
 	return nil
 }
 
-func printPlayerName(playerName string) {
-	fmt.Printf("\n\n%s:", playerName)
+func printPlayerName(w io.Writer, playerName string) {
+	fmt.Fprintf(w, "\n\n%s:", playerName)
 }
 
-func printTotalPoints(totalPoints int) {
-	fmt.Printf("\nTotal: %d", totalPoints)
+func printTotalPoints(w io.Writer, totalPoints int) {
+	fmt.Fprintf(w, "\nTotal: %d", totalPoints)
 }
@@ -14,10 +14,7 @@ func Test_getCardCost(t *testing.T) {
 	}{
 		{
 			name: "Ok Ace",
-			card: &deck.Card{
-				Suit:  deck.Clover,
-				Value: deck.Ace,
-			},
+			card: deck.MustNewCard(deck.Clover, deck.Ace),
 			check: func(cost int, err error) {
 				require.NoError(t, err)
 				require.Equal(t, int(AceCostBig), cost)
@@ -25,10 +22,7 @@ func Test_getCardCost(t *testing.T) {
 		},
 		{
 			name: "Ok Face",
-			card: &deck.Card{
-				Suit:  deck.Heart,
-				Value: deck.Jack,
-			},
+			card: deck.MustNewCard(deck.Heart, deck.Jack),
 			check: func(cost int, err error) {
 				require.NoError(t, err)
 				require.Equal(t, int(FaceCost), cost)
@@ -36,15 +30,20 @@ func Test_getCardCost(t *testing.T) {
 		},
 		{
 			name: "Ok Number",
-			card: &deck.Card{
-				Suit:  deck.Spade,
-				Value: "6",
-			},
+			card: deck.MustNewCard(deck.Spade, deck.Six),
 			check: func(cost int, err error) {
 				require.NoError(t, err)
 				require.Equal(t, 6, cost)
 			},
 		},
+		{
+			name: "Joker",
+			card: deck.MustNewCard(deck.TrumpCard, deck.Joker),
+			check: func(cost int, err error) {
+				require.ErrorIs(t, err, ErrNoCardPoints)
+				require.Zero(t, cost)
+			},
+		},
 	}
 
 	for i := range testCases {
@@ -56,3 +55,54 @@ func Test_getCardCost(t *testing.T) {
 		})
 	}
 }
+
+func TestCardsPoints(t *testing.T) {
+	testCases := []struct {
+		name   string
+		cards  []*deck.Card
+		points int
+		soft   bool
+	}{
+		{
+			name:   "No Cards",
+			cards:  nil,
+			points: 0,
+			soft:   false,
+		},
+		{
+			name:   "Natural",
+			cards:  deck.MustParseCards("Ah Ks"),
+			points: 21,
+			soft:   true,
+		},
+		{
+			name:   "Two Aces",
+			cards:  deck.MustParseCards("Ah As"),
+			points: 12,
+			soft:   true,
+		},
+		{
+			name:   "Hard Ace",
+			cards:  deck.MustParseCards("Ah Ks 5c"),
+			points: 16,
+			soft:   false,
+		},
+		{
+			name:   "Bust",
+			cards:  deck.MustParseCards("Qh Ks 5c"),
+			points: 25,
+			soft:   false,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			points, soft, err := CardsPoints(tc.cards)
+			require.NoError(t, err)
+			require.Equal(t, tc.points, points)
+			require.Equal(t, tc.soft, soft)
+		})
+	}
+}
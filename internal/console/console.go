@@ -8,7 +8,10 @@ import (
 	"strings"
 )
 
-type Console struct{}
+type Console struct {
+	// The input is over
+	eof bool
+}
 
 func NewConsole() (*Console, error) {
 	return &Console{}, nil
@@ -22,9 +25,17 @@ func (c *Console) Input() string {
 	if str, _, err := reader.ReadLine(); err != nil {
 		if err != io.EOF {
 			log.Printf("\nerror reading user input: %v", err)
+		} else {
+			c.eof = true
 		}
 		return ""
 	} else {
 		return strings.TrimRight(string(str), "\r\n")
 	}
 }
+
+// EOF
+// The input is over, Input returns only empty lines.
+func (c *Console) EOF() bool {
+	return c.eof
+}
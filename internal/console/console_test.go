@@ -85,3 +85,28 @@ func TestConsole_Input(t *testing.T) {
 		})
 	}
 }
+
+func TestConsole_EOF(t *testing.T) {
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+
+	stdin := os.Stdin
+	os.Stdin = r
+	defer func() {
+		os.Stdin = stdin
+		r.Close()
+	}()
+
+	_, err = w.WriteString("n\n")
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	c, err := NewConsole()
+	require.NoError(t, err)
+
+	require.Equal(t, "n", c.Input())
+	require.False(t, c.EOF())
+
+	require.Equal(t, "", c.Input())
+	require.True(t, c.EOF())
+}
@@ -0,0 +1,251 @@
+/*
+  This package implements the card counting systems of blackjack.
+*/
+
+package counting
+
+import (
+	"course/internal/deck"
+	"course/internal/odds"
+	"errors"
+	"fmt"
+)
+
+const (
+	// Cards in a deck without jokers
+	deckSize = 52
+	// Cards of a value in a deck, the ten-valued cards are counted together
+	rankCardsInDeck = 4
+	tenCardsInDeck  = 16
+	// The remaining decks are not estimated below half a deck, so the true count stays finite
+	minRemainingDecks = 0.5
+)
+
+var (
+	ErrUnknownSystem      = errors.New("unknown counting system")
+	ErrInvalidDecksNumber = errors.New("decks number less than 1")
+	ErrInvalidCard        = odds.ErrInvalidCard
+	ErrInvalidState       = errors.New("invalid counter state")
+)
+
+// System
+// Counting system: the tag of every card value is added to the running count.
+type System struct {
+	Name string
+	// Tags of the card values, the index is the value from 1 (ace) to 10 (tens and faces)
+	Tags [11]int
+	// The tags of a deck do not sum up to 0. The running count starts from
+	// the initial running count, so the key count does not depend on the decks number
+	Unbalanced bool
+}
+
+// Built-in counting systems
+var (
+	HiLo = System{
+		Name: "hi-lo",
+		Tags: [11]int{1: -1, 2: 1, 3: 1, 4: 1, 5: 1, 6: 1, 10: -1},
+	}
+	KO = System{
+		Name:       "ko",
+		Tags:       [11]int{1: -1, 2: 1, 3: 1, 4: 1, 5: 1, 6: 1, 7: 1, 10: -1},
+		Unbalanced: true,
+	}
+	OmegaII = System{
+		Name: "omega-ii",
+		Tags: [11]int{2: 1, 3: 1, 4: 2, 5: 2, 6: 2, 7: 1, 9: -1, 10: -2},
+	}
+)
+
+// Systems
+// The built-in counting systems.
+func Systems() []System {
+	return []System{HiLo, KO, OmegaII}
+}
+
+// SystemNames
+// Names of the built-in counting systems.
+func SystemNames() []string {
+	names := make([]string, 0, len(Systems()))
+
+	for _, system := range Systems() {
+		names = append(names, system.Name)
+	}
+
+	return names
+}
+
+// SystemByName
+// The built-in counting system with the name.
+func SystemByName(name string) (System, error) {
+	for _, system := range Systems() {
+		if system.Name == name {
+			return system, nil
+		}
+	}
+
+	return System{}, fmt.Errorf("%w: %q, available systems: %v", ErrUnknownSystem, name, SystemNames())
+}
+
+// Sum of the tags of a whole deck, 0 for the balanced systems
+func (s System) imbalance() int {
+	sum := 0
+
+	for value := 1; value <= 10; value++ {
+		cards := rankCardsInDeck
+		if value == 10 {
+			cards = tenCardsInDeck
+		}
+		sum += s.Tags[value] * cards
+	}
+
+	return sum
+}
+
+// Tag
+// Count of the card in the system.
+func (s System) Tag(card *deck.Card) (int, error) {
+	value, err := odds.CardValue(card)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.Tags[value], nil
+}
+
+// Counter
+// Running and true count of the cards seen since the shoe was shuffled.
+type Counter struct {
+	system      System
+	decksNumber int
+	running     int
+	seen        int
+}
+
+func NewCounter(system System, decksNumber int) (*Counter, error) {
+	if decksNumber < 1 {
+		return nil, ErrInvalidDecksNumber
+	}
+
+	c := &Counter{
+		system: system,
+	}
+	c.Reset(decksNumber)
+
+	return c, nil
+}
+
+// Reset
+// Start counting a new shoe of the decks number.
+func (c *Counter) Reset(decksNumber int) {
+	if decksNumber < 1 {
+		decksNumber = 1
+	}
+
+	c.decksNumber = decksNumber
+	c.seen = 0
+	c.running = c.initialRunningCount()
+}
+
+// The unbalanced systems start below 0 by the imbalance of all the decks but one
+func (c *Counter) initialRunningCount() int {
+	if !c.system.Unbalanced {
+		return 0
+	}
+
+	return -c.system.imbalance() * (c.decksNumber - 1)
+}
+
+// Count
+// Add the seen card to the count.
+func (c *Counter) Count(card *deck.Card) error {
+	tag, err := c.system.Tag(card)
+	if err != nil {
+		return err
+	}
+
+	c.running += tag
+	c.seen++
+
+	return nil
+}
+
+func (c *Counter) System() System {
+	return c.system
+}
+
+func (c *Counter) RunningCount() int {
+	return c.running
+}
+
+// Seen
+// Number of the counted cards.
+func (c *Counter) Seen() int {
+	return c.seen
+}
+
+// RemainingDecks
+// Estimate of the decks left in the shoe, at least half a deck.
+func (c *Counter) RemainingDecks() float64 {
+	remaining := float64(c.decksNumber*deckSize-c.seen) / deckSize
+	if remaining < minRemainingDecks {
+		return minRemainingDecks
+	}
+
+	return remaining
+}
+
+// TrueCount
+// Running count per remaining deck. For the unbalanced systems the initial running count and
+// the expected imbalance of the seen cards are taken out first, so the true counts of the systems can be compared.
+func (c *Counter) TrueCount() float64 {
+	seenDecks := float64(c.seen) / deckSize
+	count := float64(c.running-c.initialRunningCount()) - float64(c.system.imbalance())*seenDecks
+
+	return count / c.RemainingDecks()
+}
+
+// State
+// Counts of a counter, to save the counter and restore it later.
+type State struct {
+	DecksNumber  int `json:"decksNumber"`
+	RunningCount int `json:"runningCount"`
+	Seen         int `json:"seen"`
+}
+
+// Validate
+// The decks number is at least 1 and the seen cards fit into the decks.
+func (s State) Validate() error {
+	if s.DecksNumber < 1 {
+		return fmt.Errorf("%w: %v", ErrInvalidState, ErrInvalidDecksNumber)
+	}
+
+	if s.Seen < 0 || s.Seen > s.DecksNumber*deckSize {
+		return fmt.Errorf("%w: %d cards seen of %d decks", ErrInvalidState, s.Seen, s.DecksNumber)
+	}
+
+	return nil
+}
+
+// State
+// Counts of the cards seen since the shoe was shuffled.
+func (c *Counter) State() State {
+	return State{
+		DecksNumber:  c.decksNumber,
+		RunningCount: c.running,
+		Seen:         c.seen,
+	}
+}
+
+// Restore
+// Continue counting from the state. The counter is left untouched if the state is invalid.
+func (c *Counter) Restore(state State) error {
+	if err := state.Validate(); err != nil {
+		return err
+	}
+
+	c.decksNumber = state.DecksNumber
+	c.running = state.RunningCount
+	c.seen = state.Seen
+
+	return nil
+}
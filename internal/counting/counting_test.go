@@ -0,0 +1,147 @@
+package counting
+
+import (
+	"course/internal/deck"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func getTestCards(values ...deck.Rank) []*deck.Card {
+	cards := make([]*deck.Card, 0, len(values))
+
+	for _, value := range values {
+		cards = append(cards, deck.MustNewCard(deck.Heart, value))
+	}
+
+	return cards
+}
+
+func TestCounter_Count(t *testing.T) {
+	cards := getTestCards(deck.Two, deck.Five, deck.Seven, deck.Nine, deck.King, deck.Ace, deck.Four, deck.Six)
+
+	testCases := []struct {
+		name        string
+		system      System
+		decksNumber int
+		running     int
+		trueCount   float64
+	}{
+		{
+			name:        "Hi-Lo",
+			system:      HiLo,
+			decksNumber: 1,
+			running:     2,
+			// 2 / (44 / 52)
+			trueCount: 2.3636,
+		},
+		{
+			name:        "KO Single Deck",
+			system:      KO,
+			decksNumber: 1,
+			running:     3,
+			// (3 - 4 * 8 / 52) / (44 / 52)
+			trueCount: 2.8182,
+		},
+		{
+			name:        "KO Six Decks",
+			system:      KO,
+			decksNumber: 6,
+			running:     -17,
+			// (-17 + 20 - 4 * 8 / 52) / (304 / 52)
+			trueCount: 0.4079,
+		},
+		{
+			name:        "Omega II",
+			system:      OmegaII,
+			decksNumber: 2,
+			running:     5,
+			// 5 / (96 / 52)
+			trueCount: 2.7083,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := NewCounter(tc.system, tc.decksNumber)
+			require.NoError(t, err)
+
+			for _, card := range cards {
+				require.NoError(t, c.Count(card))
+			}
+
+			require.Equal(t, tc.running, c.RunningCount())
+			require.Equal(t, len(cards), c.Seen())
+			require.InDelta(t, tc.trueCount, c.TrueCount(), 0.0001)
+
+			c.Reset(tc.decksNumber)
+			require.Equal(t, 0, c.Seen())
+			require.Equal(t, 0.0, c.TrueCount())
+		})
+	}
+}
+
+func TestCounter_WholeShoe(t *testing.T) {
+	for _, system := range Systems() {
+		t.Run(system.Name, func(t *testing.T) {
+			cards, err := deck.NewDeck(deck.NewDeckOptions{DecksNumber: 2})
+			require.NoError(t, err)
+
+			c, err := NewCounter(system, 2)
+			require.NoError(t, err)
+
+			for _, card := range cards {
+				require.NoError(t, c.Count(card))
+			}
+
+			// The KO count ends at the imbalance of one deck
+			running := 0
+			if system.Unbalanced {
+				running = 4
+			}
+			require.Equal(t, running, c.RunningCount())
+			require.Equal(t, minRemainingDecks, c.RemainingDecks())
+		})
+	}
+}
+
+func TestCounter_Errors(t *testing.T) {
+	_, err := NewCounter(HiLo, 0)
+	require.ErrorIs(t, err, ErrInvalidDecksNumber)
+
+	c, err := NewCounter(HiLo, 1)
+	require.NoError(t, err)
+	require.ErrorIs(t, c.Count(deck.MustNewCard(deck.TrumpCard, deck.Joker)), ErrInvalidCard)
+	require.ErrorIs(t, c.Count(nil), ErrInvalidCard)
+	require.Equal(t, 0, c.Seen())
+}
+
+func TestCounter_Restore(t *testing.T) {
+	c, err := NewCounter(KO, 2)
+	require.NoError(t, err)
+	require.NoError(t, c.Count(deck.MustNewCard(deck.Heart, deck.Five)))
+
+	restored, err := NewCounter(KO, 1)
+	require.NoError(t, err)
+	require.NoError(t, restored.Restore(c.State()))
+	require.Equal(t, c.State(), restored.State())
+	require.Equal(t, c.TrueCount(), restored.TrueCount())
+
+	state := c.State()
+	require.ErrorIs(t, restored.Restore(State{DecksNumber: 0}), ErrInvalidState)
+	require.ErrorIs(t, restored.Restore(State{DecksNumber: 1, Seen: 53}), ErrInvalidState)
+	require.ErrorIs(t, restored.Restore(State{DecksNumber: 1, Seen: -1}), ErrInvalidState)
+	require.Equal(t, state, restored.State())
+}
+
+func TestSystemByName(t *testing.T) {
+	for _, system := range Systems() {
+		found, err := SystemByName(system.Name)
+		require.NoError(t, err)
+		require.Equal(t, system, found)
+	}
+
+	_, err := SystemByName("zen")
+	require.ErrorIs(t, err, ErrUnknownSystem)
+}
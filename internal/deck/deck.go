@@ -6,7 +6,9 @@ package deck
 
 import (
 	"course/pkg/random"
-	"strconv"
+	"errors"
+	"fmt"
+	"strings"
 )
 
 type CardSuit string
@@ -20,22 +22,46 @@ const (
 	TrumpCard CardSuit = "trumpCard"
 )
 
-type CardValue string
+// Rank
+// Rank of a card. The number cards have their numbers, the picture cards go above them: Jack, Queen, King, Ace.
+// The ranks are compared as numbers.
+type Rank int
 
-// Card Values
+// Card Ranks
 const (
-	Ace    CardValue = "ace"
-	King   CardValue = "king"
-	Queen  CardValue = "queen"
-	Jack   CardValue = "jack"
-	Number CardValue = "number"
-	Joker  CardValue = "joker"
+	Two Rank = iota + 2
+	Three
+	Four
+	Five
+	Six
+	Seven
+	Eight
+	Nine
+	Ten
+	Jack
+	Queen
+	King
+	Ace
+	Joker
 )
 
+// Number
+// Place of the number cards in NewDeckOptions.CardValuesOrder, as before the typed ranks.
+// It is below Two, so it is never a rank of a card and NewCard rejects it.
+const Number Rank = 0
+
+// CardValue
+// Former name of Rank. NewDeckOptions.CardValuesOrder keeps its type,
+// so the orders written as []deck.CardValue{deck.Ace, deck.Number} still compile.
+type CardValue = Rank
+
+// Card
+// The cards are built with NewCard or ParseCard, which check the suit and rank,
+// so every card but the zero value is Valid.
 type Card struct {
-	Suit CardSuit
+	suit CardSuit
 	// (A, K, Q ...)
-	Value CardValue
+	rank Rank
 }
 
 type NewDeckOptions struct {
@@ -47,11 +73,14 @@ type NewDeckOptions struct {
 	ShuffleFn func(deck []*Card)
 	// Whether to shuffle the deck when creating. If the ShuffleFn function is passed, then shuffling will not work by default
 	NoShuffle bool
+	// Source of the default shuffle. A source seeded with the time is used if nil
+	Random *random.Random
 	// Available suits in the deck
 	Suits []CardSuit
-	// Order of cards of suits
+	// Order of cards of suits, Number is the place of the number cards
 	CardValuesOrder []CardValue
-	// Initial value for cards with a numeric value
+	// Initial value for cards with a numeric value. The numbers go on past 10 as before the typed ranks,
+	// 11 to 14 are the ranks of Jack, Queen, King and Ace. A number that is not a rank of a suit is an error
 	CardNumberValueStart int
 	// Number of decks
 	DecksNumber int
@@ -69,6 +98,11 @@ func NewDeck(options NewDeckOptions) ([]*Card, error) {
 	cardValuesOrder := options.CardValuesOrder
 	cardNumberValueStart := options.CardNumberValueStart
 	decksNumber := options.DecksNumber
+	rnd := options.Random
+
+	if rnd == nil {
+		rnd = random.New(random.Config{})
+	}
 
 	if suitCardsCount <= 0 {
 		suitCardsCount = 13
@@ -83,7 +117,7 @@ func NewDeck(options NewDeckOptions) ([]*Card, error) {
 	}
 
 	if cardNumberValueStart == 0 {
-		cardNumberValueStart = 2
+		cardNumberValueStart = int(Two)
 	}
 
 	if decksNumber == 0 {
@@ -104,19 +138,19 @@ func NewDeck(options NewDeckOptions) ([]*Card, error) {
 
 			for j := 0; j < suitCardsCount; j++ {
 				suit := suits[i]
-				var value CardValue
+				var value Rank
 
 				cardValueOrder := cardValuesOrder[cardValuesOrderIndex]
 
 				if cardValueOrder == Number {
-					value = CardValue(strconv.Itoa(cardNumberValue))
+					value = Rank(cardNumberValue)
 					cardNumberValue++
 				} else {
 					value = cardValueOrder
 					cardValuesOrderIndex++
 				}
 
-				card, err := newCard(suit, value)
+				card, err := NewCard(suit, value)
 				if err != nil {
 					return nil, err
 				}
@@ -127,7 +161,7 @@ func NewDeck(options NewDeckOptions) ([]*Card, error) {
 		}
 
 		for i := 0; i < jokersNumber; i++ {
-			card, err := newCard(TrumpCard, Joker)
+			card, err := NewCard(TrumpCard, Joker)
 			if err != nil {
 				return nil, err
 			}
@@ -141,7 +175,7 @@ func NewDeck(options NewDeckOptions) ([]*Card, error) {
 		i := len(deck)
 		for i > 1 {
 			i = i - 1
-			j := random.RandInt(0, i)
+			j := rnd.RandInt(0, i)
 			deck[j], deck[i] = deck[i], deck[j]
 		}
 	} else if shuffleFn != nil {
@@ -151,9 +185,184 @@ func NewDeck(options NewDeckOptions) ([]*Card, error) {
 	return deck, nil
 }
 
-func newCard(suit CardSuit, value CardValue) (*Card, error) {
-	return &Card{
-		Suit:  suit,
-		Value: value,
-	}, nil
+var (
+	ErrInvalidDeck    = errors.New("deck does not match its options")
+	ErrNotEnoughCards = errors.New("not enough cards in the deck")
+)
+
+// Deck
+// Cards of a deck or a shoe in the dealing order. The cards are drawn from the top,
+// the drawn cards may be put on the discard pile.
+type Deck struct {
+	cards []*Card
+	// Index of the card to be drawn next
+	next    int
+	discard []*Card
+}
+
+// New
+// Deck of the options, see NewDeck.
+func New(options NewDeckOptions) (*Deck, error) {
+	cards, err := NewDeck(options)
+	if err != nil {
+		return nil, err
+	}
+
+	return FromCards(cards), nil
+}
+
+// FromCards
+// Deck of the cards in the dealing order.
+func FromCards(cards []*Card) *Deck {
+	return &Deck{cards: cards}
+}
+
+// Len
+// Number of all the cards of the deck, the drawn ones too.
+func (d *Deck) Len() int {
+	return len(d.cards)
+}
+
+// Drawn
+// Number of the cards drawn from the deck.
+func (d *Deck) Drawn() int {
+	return d.next
+}
+
+// Remaining
+// Number of the cards not drawn yet.
+func (d *Deck) Remaining() int {
+	return len(d.cards) - d.next
+}
+
+// Cards
+// All the cards of the deck in the dealing order, the drawn ones too.
+func (d *Deck) Cards() []*Card {
+	return append([]*Card{}, d.cards...)
+}
+
+// Rest
+// Cards not drawn yet in the dealing order.
+func (d *Deck) Rest() []*Card {
+	return append([]*Card{}, d.cards[d.next:]...)
+}
+
+// Count
+// Number of the remaining cards of the rank.
+func (d *Deck) Count(rank Rank) int {
+	count := 0
+	for _, card := range d.cards[d.next:] {
+		if card.rank == rank {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Composition
+// Number of the remaining cards of every rank.
+func (d *Deck) Composition() map[Rank]int {
+	composition := make(map[Rank]int)
+	for _, card := range d.cards[d.next:] {
+		composition[card.rank]++
+	}
+
+	return composition
+}
+
+// Validate
+// Check that the deck has the expected number of every card of the options, the drawn cards are counted too.
+func (d *Deck) Validate(options NewDeckOptions) error {
+	options.NoShuffle = true
+	options.ShuffleFn = nil
+
+	expected, err := NewDeck(options)
+	if err != nil {
+		return err
+	}
+
+	counts := make(map[Card]int)
+	for _, card := range d.cards {
+		if !card.Valid() {
+			return fmt.Errorf("%w: %v", ErrInvalidCard, *card)
+		}
+		counts[*card]++
+	}
+
+	for _, card := range expected {
+		counts[*card]--
+	}
+
+	// The cards are reported once in the order of a new deck, then in the order of the deck
+	var missing, extra []string
+	for _, card := range append(expected, d.cards...) {
+		count := counts[*card]
+		switch {
+		case count < 0:
+			missing = append(missing, fmt.Sprintf("%s x%d", card, -count))
+		case count > 0:
+			extra = append(extra, fmt.Sprintf("%s x%d", card, count))
+		}
+		delete(counts, *card)
+	}
+
+	var problems []string
+	if len(missing) > 0 {
+		problems = append(problems, "missing "+strings.Join(missing, ", "))
+	}
+	if len(extra) > 0 {
+		problems = append(problems, "extra "+strings.Join(extra, ", "))
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidDeck, strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// Peek
+// Next n cards without drawing them.
+func (d *Deck) Peek(n int) ([]*Card, error) {
+	if n < 0 || n > d.Remaining() {
+		return nil, fmt.Errorf("%w: %d cards asked, %d left", ErrNotEnoughCards, n, d.Remaining())
+	}
+
+	return append([]*Card{}, d.cards[d.next:d.next+n]...), nil
+}
+
+// Draw
+// Take the next n cards from the deck.
+func (d *Deck) Draw(n int) ([]*Card, error) {
+	cards, err := d.Peek(n)
+	if err != nil {
+		return nil, err
+	}
+
+	d.next += n
+	return cards, nil
+}
+
+// Burn
+// Draw the next n cards and put them on the discard pile unseen.
+func (d *Deck) Burn(n int) error {
+	cards, err := d.Draw(n)
+	if err != nil {
+		return err
+	}
+
+	d.Discard(cards...)
+	return nil
+}
+
+// Discard
+// Put the played cards on the discard pile.
+func (d *Deck) Discard(cards ...*Card) {
+	d.discard = append(d.discard, cards...)
+}
+
+// Discarded
+// Cards of the discard pile in the order they were put on it.
+func (d *Deck) Discarded() []*Card {
+	return append([]*Card{}, d.discard...)
 }
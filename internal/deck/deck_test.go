@@ -96,6 +96,38 @@ func TestNewDeck(t *testing.T) {
 				require.NoError(t, err)
 				require.NotNil(t, cards)
 				require.Len(t, cards, 52)
+
+				// The numbers go on from 5 to 13, the numbers past 10 are the ranks of the picture cards
+				ranks := make(map[Rank]int)
+				for _, card := range cards {
+					ranks[card.rank]++
+				}
+				require.Len(t, ranks, 10)
+				require.Zero(t, ranks[Four])
+				require.Equal(t, 4, ranks[Five])
+				require.Equal(t, 8, ranks[Jack])
+				require.Equal(t, 8, ranks[King])
+				require.Equal(t, 4, ranks[Ace])
+			},
+		},
+		{
+			name: "Invalid Card Number Value Start",
+			config: NewDeckOptions{
+				// The numbers go on past the ace
+				CardNumberValueStart: 11,
+			},
+			check: func(cards []*Card, err error, config NewDeckOptions) {
+				require.ErrorIs(t, err, ErrInvalidCard)
+			},
+		},
+		{
+			name: "Card Number Value Start Below Two",
+			config: NewDeckOptions{
+				// The string values started from "1", it is not a rank of a card
+				CardNumberValueStart: 1,
+			},
+			check: func(cards []*Card, err error, config NewDeckOptions) {
+				require.ErrorIs(t, err, ErrInvalidCard)
 			},
 		},
 		{
@@ -120,3 +152,151 @@ func TestNewDeck(t *testing.T) {
 		})
 	}
 }
+
+func TestDeck_Draw(t *testing.T) {
+	d := FromCards(MustParseCards("As Kh 10c 2d 7s"))
+	require.Equal(t, 5, d.Len())
+	require.Equal(t, 5, d.Remaining())
+
+	cards, err := d.Peek(2)
+	require.NoError(t, err)
+	require.Equal(t, MustParseCards("As Kh"), cards)
+	require.Equal(t, 0, d.Drawn())
+
+	cards, err = d.Draw(2)
+	require.NoError(t, err)
+	require.Equal(t, MustParseCards("As Kh"), cards)
+	require.Equal(t, 2, d.Drawn())
+	require.Equal(t, 3, d.Remaining())
+
+	require.NoError(t, d.Burn(1))
+	require.Equal(t, MustParseCards("10c"), d.Discarded())
+	require.Equal(t, MustParseCards("2d 7s"), d.Rest())
+
+	d.Discard(cards...)
+	require.Equal(t, MustParseCards("10c As Kh"), d.Discarded())
+
+	_, err = d.Draw(3)
+	require.ErrorIs(t, err, ErrNotEnoughCards)
+	_, err = d.Peek(-1)
+	require.ErrorIs(t, err, ErrNotEnoughCards)
+	require.ErrorIs(t, d.Burn(3), ErrNotEnoughCards)
+	require.Equal(t, 2, d.Remaining())
+
+	cards, err = d.Draw(2)
+	require.NoError(t, err)
+	require.Equal(t, MustParseCards("2d 7s"), cards)
+	require.Equal(t, 0, d.Remaining())
+	require.Len(t, d.Cards(), 5)
+}
+
+func TestDeck_Composition(t *testing.T) {
+	d, err := New(NewDeckOptions{DecksNumber: 2})
+	require.NoError(t, err)
+	require.Equal(t, 104, d.Remaining())
+	require.Equal(t, 8, d.Count(Ace))
+	require.Equal(t, 0, d.Count(Joker))
+
+	composition := d.Composition()
+	require.Len(t, composition, 13)
+	for _, rank := range Ranks() {
+		require.Equal(t, 8, composition[rank])
+	}
+
+	// The drawn cards are not counted
+	d = FromCards(MustParseCards("As Ah Kd"))
+	_, err = d.Draw(1)
+	require.NoError(t, err)
+	require.Equal(t, 1, d.Count(Ace))
+	require.Equal(t, map[Rank]int{Ace: 1, King: 1}, d.Composition())
+}
+
+func TestDeck_Validate(t *testing.T) {
+	options := NewDeckOptions{DecksNumber: 2, JokersNumber: 1}
+	queen, king := MustParseCards("Qh")[0], MustParseCards("Kh")[0]
+
+	// Cards of a new deck with the first card equal to the given one replaced
+	replaced := func(card *Card, by []*Card) []*Card {
+		cards, err := NewDeck(options)
+		require.NoError(t, err)
+
+		for i := range cards {
+			if *cards[i] == *card {
+				return append(append(cards[:i:i], by...), cards[i+1:]...)
+			}
+		}
+		require.FailNow(t, "no card in the deck", card.String())
+		return nil
+	}
+
+	testCases := []struct {
+		name    string
+		cards   func() []*Card
+		err     error
+		message string
+	}{
+		{
+			name: "Shuffled",
+			cards: func() []*Card {
+				cards, err := NewDeck(options)
+				require.NoError(t, err)
+				return cards
+			},
+		},
+		{
+			name: "Missing Card",
+			cards: func() []*Card {
+				return replaced(queen, nil)
+			},
+			err:     ErrInvalidDeck,
+			message: "missing " + queen.String() + " x1",
+		},
+		{
+			name: "Extra Card",
+			cards: func() []*Card {
+				return replaced(queen, []*Card{queen, queen, queen})
+			},
+			err:     ErrInvalidDeck,
+			message: "extra " + queen.String() + " x2",
+		},
+		{
+			name: "Swapped Card",
+			cards: func() []*Card {
+				return replaced(queen, []*Card{king})
+			},
+			err:     ErrInvalidDeck,
+			message: "missing " + queen.String() + " x1; extra " + king.String() + " x1",
+		},
+		{
+			name: "Invalid Card",
+			cards: func() []*Card {
+				cards, err := NewDeck(options)
+				require.NoError(t, err)
+				cards[0] = &Card{}
+				return cards
+			},
+			err: ErrInvalidCard,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			d := FromCards(tc.cards())
+
+			// The drawn cards are a part of the deck too
+			_, err := d.Draw(10)
+			require.NoError(t, err)
+
+			err = d.Validate(options)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				require.Contains(t, err.Error(), tc.message)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
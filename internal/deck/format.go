@@ -0,0 +1,288 @@
+package deck
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type Style string
+
+// Card formatting styles
+const (
+	// Value and suit symbol: "A♠", "10♥"
+	StyleShort Style = "short"
+	// English name: "Ace of Spades", "Ten of Hearts"
+	StyleLong Style = "long"
+	// Value and suit letter, ASCII only: "As", "10h"
+	StyleASCII Style = "ascii"
+	// Short notation with ANSI colors: hearts and diamonds are red
+	StyleColor Style = "color"
+)
+
+// ANSI escape sequences of the colored style
+const (
+	colorRed   = "\x1b[31m"
+	colorReset = "\x1b[0m"
+)
+
+var (
+	ErrInvalidStyle = errors.New("unknown card style")
+	ErrInvalidSuit  = errors.New("invalid card suit")
+	ErrInvalidCard  = errors.New("invalid card")
+)
+
+type suitFormat struct {
+	symbol string
+	letter string
+	name   string
+}
+
+var suitFormats = map[CardSuit]suitFormat{
+	Spade:     {symbol: "♠", letter: "s", name: "Spades"},
+	Heart:     {symbol: "♥", letter: "h", name: "Hearts"},
+	Clover:    {symbol: "♣", letter: "c", name: "Clubs"},
+	Diamond:   {symbol: "♦", letter: "d", name: "Diamonds"},
+	TrumpCard: {symbol: "★", letter: "*", name: "Trumps"},
+}
+
+type valueFormat struct {
+	label string
+	name  string
+}
+
+var valueFormats = map[Rank]valueFormat{
+	Ace:   {label: "A", name: "Ace"},
+	King:  {label: "K", name: "King"},
+	Queen: {label: "Q", name: "Queen"},
+	Jack:  {label: "J", name: "Jack"},
+	Joker: {label: "JK", name: "Joker"},
+	Two:   {label: "2", name: "Two"},
+	Three: {label: "3", name: "Three"},
+	Four:  {label: "4", name: "Four"},
+	Five:  {label: "5", name: "Five"},
+	Six:   {label: "6", name: "Six"},
+	Seven: {label: "7", name: "Seven"},
+	Eight: {label: "8", name: "Eight"},
+	Nine:  {label: "9", name: "Nine"},
+	Ten:   {label: "10", name: "Ten"},
+}
+
+// Styles
+// All the card styles.
+func Styles() []Style {
+	return []Style{StyleShort, StyleLong, StyleASCII, StyleColor}
+}
+
+// ParseStyle
+// Style by its name. StyleShort is returned for an empty name.
+func ParseStyle(name string) (Style, error) {
+	if name == "" {
+		return StyleShort, nil
+	}
+
+	for _, style := range Styles() {
+		if string(style) == name {
+			return style, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w %q", ErrInvalidStyle, name)
+}
+
+// String
+// Symbol of the suit: "♠", "♥", "♣", "♦", "★" for the trump cards.
+func (s CardSuit) String() string {
+	if format, ok := suitFormats[s]; ok {
+		return format.symbol
+	}
+
+	return string(s)
+}
+
+// IsRed
+// Hearts and diamonds are red.
+func (s CardSuit) IsRed() bool {
+	return s == Heart || s == Diamond
+}
+
+// ParseSuit
+// Suit by its symbol ("♠"), letter ("s") or name ("spade", "spades", "clubs"), case insensitive.
+func ParseSuit(text string) (CardSuit, error) {
+	lower := strings.ToLower(strings.TrimSpace(text))
+
+	for suit, format := range suitFormats {
+		name := strings.ToLower(format.name)
+
+		// The names are in the plural and in the singular: "spades", "spade", "clubs", "club"
+		if lower == format.symbol || lower == format.letter || lower == strings.ToLower(string(suit)) ||
+			lower == name || lower == strings.TrimSuffix(name, "s") {
+			return suit, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w %q", ErrInvalidSuit, text)
+}
+
+// Label
+// Rank in the card notation: "A", "K", "Q", "J", "2" to "10", "JK" for the joker.
+func (r Rank) Label() string {
+	if format, ok := valueFormats[r]; ok {
+		return format.label
+	}
+
+	return r.String()
+}
+
+// String
+// Card in the short notation: "A♠", "10♥". The joker is "JK".
+func (c Card) String() string {
+	return c.Format(StyleShort)
+}
+
+// Format
+// Card in the style. The short style is used for an unknown style.
+func (c Card) Format(style Style) string {
+	if c.rank == Joker {
+		if style == StyleLong {
+			return valueFormats[Joker].name
+		}
+		return valueFormats[Joker].label
+	}
+
+	switch style {
+	case StyleLong:
+		return rankName(c.rank) + " of " + suitName(c.suit)
+
+	case StyleASCII:
+		letter := string(c.suit)
+		if format, ok := suitFormats[c.suit]; ok {
+			letter = format.letter
+		}
+		return c.rank.Label() + letter
+
+	case StyleColor:
+		short := c.rank.Label() + c.suit.String()
+		if c.suit.IsRed() {
+			return colorRed + short + colorReset
+		}
+		return short
+
+	default:
+		return c.rank.Label() + c.suit.String()
+	}
+}
+
+func rankName(rank Rank) string {
+	if format, ok := valueFormats[rank]; ok {
+		return format.name
+	}
+
+	return rank.String()
+}
+
+func suitName(suit CardSuit) string {
+	if format, ok := suitFormats[suit]; ok {
+		return format.name
+	}
+
+	return string(suit)
+}
+
+// ParseCard
+// Card in the short, ASCII or long notation: "A♠", "Qh", "10c", "Ace of Spades". "JK" and "Joker" are the joker.
+// The colors of the colored style are ignored.
+func ParseCard(text string) (*Card, error) {
+	notation := strings.TrimSpace(stripColors(text))
+
+	if strings.EqualFold(notation, valueFormats[Joker].label) || strings.EqualFold(notation, valueFormats[Joker].name) {
+		return NewCard(TrumpCard, Joker)
+	}
+
+	var valueText, suitText string
+
+	if name, suit, ok := strings.Cut(notation, " of "); ok {
+		valueText, suitText = strings.TrimSpace(name), strings.TrimSpace(suit)
+	} else {
+		last, size := utf8.DecodeLastRuneInString(notation)
+		if last == utf8.RuneError {
+			return nil, fmt.Errorf("%w %q", ErrInvalidCard, text)
+		}
+		valueText, suitText = notation[:len(notation)-size], notation[len(notation)-size:]
+	}
+
+	rank, err := ParseRank(valueText)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q", ErrInvalidCard, text)
+	}
+
+	suit, err := ParseSuit(suitText)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q", ErrInvalidCard, text)
+	}
+
+	// The joker has no suit and the suits have no joker
+	card, err := NewCard(suit, rank)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q", ErrInvalidCard, text)
+	}
+
+	return card, nil
+}
+
+func stripColors(text string) string {
+	return strings.NewReplacer(colorRed, "", colorReset, "").Replace(text)
+}
+
+// ParseCards
+// Cards separated by spaces or commas in the notations of ParseCard: "As Kd 10c", "A♠, K♦".
+func ParseCards(text string) ([]*Card, error) {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+
+	// The long names have spaces: "Ace of Spades, Ten of Clubs"
+	if strings.Contains(text, " of ") {
+		fields = strings.Split(text, ",")
+	}
+
+	cards := make([]*Card, 0, len(fields))
+
+	for _, field := range fields {
+		card, err := ParseCard(field)
+		if err != nil {
+			return nil, err
+		}
+		cards = append(cards, card)
+	}
+
+	return cards, nil
+}
+
+// MustParseCards
+// ParseCards that panics on an invalid card, for the stacked decks of the tests and the scenarios.
+func MustParseCards(text string) []*Card {
+	cards, err := ParseCards(text)
+	if err != nil {
+		panic(err)
+	}
+
+	return cards
+}
+
+// FormatCards
+// Cards in the style separated by spaces, or by commas in the long style. ParseCards reads them back.
+func FormatCards(cards []*Card, style Style) string {
+	names := make([]string, 0, len(cards))
+	for _, card := range cards {
+		names = append(names, card.Format(style))
+	}
+
+	if style == StyleLong {
+		return strings.Join(names, ", ")
+	}
+
+	return strings.Join(names, " ")
+}
@@ -0,0 +1,254 @@
+package deck
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestCard_Format(t *testing.T) {
+	testCases := []struct {
+		name  string
+		card  Card
+		style Style
+		text  string
+	}{
+		{
+			name:  "Short",
+			card:  Card{suit: Spade, rank: Ace},
+			style: StyleShort,
+			text:  "A♠",
+		},
+		{
+			name:  "Short Ten",
+			card:  Card{suit: Heart, rank: Ten},
+			style: StyleShort,
+			text:  "10♥",
+		},
+		{
+			name:  "Long",
+			card:  Card{suit: Clover, rank: Ten},
+			style: StyleLong,
+			text:  "Ten of Clubs",
+		},
+		{
+			name:  "ASCII",
+			card:  Card{suit: Diamond, rank: Queen},
+			style: StyleASCII,
+			text:  "Qd",
+		},
+		{
+			name:  "Color Red",
+			card:  Card{suit: Diamond, rank: Seven},
+			style: StyleColor,
+			text:  "\x1b[31m7♦\x1b[0m",
+		},
+		{
+			name:  "Color Black",
+			card:  Card{suit: Clover, rank: King},
+			style: StyleColor,
+			text:  "K♣",
+		},
+		{
+			name:  "Joker",
+			card:  Card{suit: TrumpCard, rank: Joker},
+			style: StyleShort,
+			text:  "JK",
+		},
+		{
+			name:  "Long Joker",
+			card:  Card{suit: TrumpCard, rank: Joker},
+			style: StyleLong,
+			text:  "Joker",
+		},
+		{
+			name:  "Unknown Style",
+			card:  Card{suit: Spade, rank: Jack},
+			style: "braille",
+			text:  "J♠",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.text, tc.card.Format(tc.style))
+		})
+	}
+}
+
+func TestCard_String(t *testing.T) {
+	card := &Card{suit: Heart, rank: King}
+
+	require.Equal(t, "K♥", card.String())
+	require.Equal(t, "K♥", fmt.Sprint(card))
+	require.Equal(t, "♥", Heart.String())
+	require.Equal(t, "J", Jack.Label())
+}
+
+func TestParseSuit(t *testing.T) {
+	testCases := []struct {
+		text string
+		suit CardSuit
+		err  error
+	}{
+		{text: "♠", suit: Spade},
+		{text: "h", suit: Heart},
+		{text: "C", suit: Clover},
+		{text: "clubs", suit: Clover},
+		{text: "club", suit: Clover},
+		{text: "Diamonds", suit: Diamond},
+		{text: "spade", suit: Spade},
+		{text: "trumpCard", suit: TrumpCard},
+		{text: "x", err: ErrInvalidSuit},
+		{text: "", err: ErrInvalidSuit},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.text, func(t *testing.T) {
+			suit, err := ParseSuit(tc.text)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.suit, suit)
+		})
+	}
+}
+
+func TestParseCard(t *testing.T) {
+	testCases := []struct {
+		text string
+		card *Card
+		err  error
+	}{
+		{text: "A♠", card: &Card{suit: Spade, rank: Ace}},
+		{text: "Qh", card: &Card{suit: Heart, rank: Queen}},
+		{text: "10c", card: &Card{suit: Clover, rank: Ten}},
+		{text: "Ten of Clubs", card: &Card{suit: Clover, rank: Ten}},
+		{text: "\x1b[31m7♦\x1b[0m", card: &Card{suit: Diamond, rank: Seven}},
+		{text: "JK", card: &Card{suit: TrumpCard, rank: Joker}},
+		{text: "joker", card: &Card{suit: TrumpCard, rank: Joker}},
+		{text: "1s", err: ErrInvalidCard},
+		{text: "11h", err: ErrInvalidCard},
+		{text: "07h", err: ErrInvalidCard},
+		{text: "Qx", err: ErrInvalidCard},
+		{text: "A*", err: ErrInvalidCard},
+		{text: "", err: ErrInvalidCard},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.text, func(t *testing.T) {
+			card, err := ParseCard(tc.text)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.card, card)
+		})
+	}
+}
+
+func TestParseStyle(t *testing.T) {
+	style, err := ParseStyle("")
+	require.NoError(t, err)
+	require.Equal(t, StyleShort, style)
+
+	for _, style := range Styles() {
+		parsed, err := ParseStyle(string(style))
+		require.NoError(t, err)
+		require.Equal(t, style, parsed)
+	}
+
+	_, err = ParseStyle("braille")
+	require.ErrorIs(t, err, ErrInvalidStyle)
+}
+
+func TestParseCards(t *testing.T) {
+	testCases := []struct {
+		name  string
+		text  string
+		cards []*Card
+		err   error
+	}{
+		{
+			name:  "Spaces",
+			text:  "As Kd 10c",
+			cards: []*Card{{suit: Spade, rank: Ace}, {suit: Diamond, rank: King}, {suit: Clover, rank: Ten}},
+		},
+		{
+			name:  "Commas",
+			text:  "A♠, 7♥,JK",
+			cards: []*Card{{suit: Spade, rank: Ace}, {suit: Heart, rank: Seven}, {suit: TrumpCard, rank: Joker}},
+		},
+		{
+			name:  "Long Names",
+			text:  "Ace of Spades, Joker, Two of Hearts",
+			cards: []*Card{{suit: Spade, rank: Ace}, {suit: TrumpCard, rank: Joker}, {suit: Heart, rank: Two}},
+		},
+		{
+			name:  "Empty",
+			text:  " ",
+			cards: []*Card{},
+		},
+		{
+			name: "Invalid Card",
+			text: "As Kx",
+			err:  ErrInvalidCard,
+		},
+		{
+			name: "Joker With Suit",
+			text: "JKs",
+			err:  ErrInvalidCard,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			cards, err := ParseCards(tc.text)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.cards, cards)
+		})
+	}
+
+	require.Panics(t, func() {
+		MustParseCards("1s")
+	})
+}
+
+func TestFormatCards_RoundTrip(t *testing.T) {
+	cards, err := NewDeck(NewDeckOptions{JokersNumber: 2, NoShuffle: true})
+	require.NoError(t, err)
+
+	for _, style := range Styles() {
+		t.Run(string(style), func(t *testing.T) {
+			for _, card := range cards {
+				parsed, err := ParseCard(card.Format(style))
+				require.NoError(t, err)
+				require.Equal(t, card, parsed)
+			}
+
+			parsed, err := ParseCards(FormatCards(cards, style))
+			require.NoError(t, err)
+			require.Equal(t, cards, parsed)
+		})
+	}
+
+	require.Equal(t, "As 10h JK", FormatCards(MustParseCards("A♠ 10♥ JK"), StyleASCII))
+}
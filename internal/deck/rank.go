@@ -0,0 +1,242 @@
+package deck
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidRank = errors.New("invalid card rank")
+
+type Color string
+
+// Card Colors
+const (
+	Black Color = "black"
+	Red   Color = "red"
+)
+
+// Ranks
+// Ranks of the cards of a suit from Two to Ace. The joker is not a rank of a suit.
+func Ranks() []Rank {
+	ranks := make([]Rank, 0, Ace-Two+1)
+	for rank := Two; rank <= Ace; rank++ {
+		ranks = append(ranks, rank)
+	}
+
+	return ranks
+}
+
+// Suits
+// Suits of a standard deck.
+func Suits() []CardSuit {
+	return []CardSuit{Spade, Heart, Clover, Diamond}
+}
+
+// Valid
+// The rank is one of the card ranks from Two to Joker.
+func (r Rank) Valid() bool {
+	return r >= Two && r <= Joker
+}
+
+// IsNumber
+// Number cards from Two to Ten.
+func (r Rank) IsNumber() bool {
+	return r >= Two && r <= Ten
+}
+
+// IsFace
+// Picture cards: Jack, Queen and King.
+func (r Rank) IsFace() bool {
+	return r >= Jack && r <= King
+}
+
+// Compare
+// -1 if the rank is lower than the other one, 1 if it is higher, 0 if they are equal. The ace is the highest rank of a suit.
+func (r Rank) Compare(other Rank) int {
+	switch {
+	case r < other:
+		return -1
+	case r > other:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// String
+// Name of the rank in the saves and the event logs: "2" to "10", "jack", "queen", "king", "ace", "joker".
+func (r Rank) String() string {
+	if r.IsNumber() {
+		return strconv.Itoa(int(r))
+	}
+
+	if format, ok := valueFormats[r]; ok {
+		return strings.ToLower(format.name)
+	}
+
+	return fmt.Sprintf("Rank(%d)", int(r))
+}
+
+// MarshalText
+// The rank is encoded by its name, see String.
+func (r Rank) MarshalText() ([]byte, error) {
+	if !r.Valid() {
+		return nil, fmt.Errorf("%w %d", ErrInvalidRank, int(r))
+	}
+
+	return []byte(r.String()), nil
+}
+
+func (r *Rank) UnmarshalText(text []byte) error {
+	rank, err := ParseRank(string(text))
+	if err != nil {
+		return err
+	}
+
+	*r = rank
+	return nil
+}
+
+// ParseRank
+// Rank by its label ("Q", "10", "JK") or name ("Queen", "ten", "joker"), case insensitive.
+func ParseRank(text string) (Rank, error) {
+	if number, err := strconv.Atoi(text); err == nil {
+		rank := Rank(number)
+		if rank.IsNumber() && text == strconv.Itoa(number) {
+			return rank, nil
+		}
+		return 0, fmt.Errorf("%w %q", ErrInvalidRank, text)
+	}
+
+	for rank, format := range valueFormats {
+		if strings.EqualFold(text, format.label) || strings.EqualFold(text, format.name) {
+			return rank, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w %q", ErrInvalidRank, text)
+}
+
+// Valid
+// The suit is one of the four suits or the trump cards of the jokers.
+func (s CardSuit) Valid() bool {
+	_, ok := suitFormats[s]
+	return ok
+}
+
+// Color
+// Hearts and diamonds are red, the other suits are black.
+func (s CardSuit) Color() Color {
+	if s.IsRed() {
+		return Red
+	}
+
+	return Black
+}
+
+// NewCard
+// Card of the suit and rank. The joker is the only card of the trump cards.
+func NewCard(suit CardSuit, rank Rank) (*Card, error) {
+	card := &Card{
+		suit: suit,
+		rank: rank,
+	}
+
+	if !card.Valid() {
+		return nil, fmt.Errorf("%w: suit %q, rank %d", ErrInvalidCard, string(suit), int(rank))
+	}
+
+	return card, nil
+}
+
+// MustNewCard
+// NewCard that panics on an invalid card, for the hands of the tests.
+func MustNewCard(suit CardSuit, rank Rank) *Card {
+	card, err := NewCard(suit, rank)
+	if err != nil {
+		panic(err)
+	}
+
+	return card
+}
+
+// Suit
+// Suit of the card, TrumpCard for the joker.
+func (c Card) Suit() CardSuit {
+	return c.suit
+}
+
+// Rank
+// Rank of the card.
+func (c Card) Rank() Rank {
+	return c.rank
+}
+
+// Valid
+// The card has a valid suit and rank, the joker is of the trump cards.
+func (c Card) Valid() bool {
+	if !c.suit.Valid() || !c.rank.Valid() {
+		return false
+	}
+
+	return (c.rank == Joker) == (c.suit == TrumpCard)
+}
+
+// Color
+// Color of the suit of the card.
+func (c Card) Color() Color {
+	return c.suit.Color()
+}
+
+// Compare
+// Cards are compared by rank, the suits are not ranked.
+func (c Card) Compare(other Card) int {
+	return c.rank.Compare(other.rank)
+}
+
+// ValidateCards
+// Every card is set and Valid. Only the zero value of a card is not Valid.
+func ValidateCards(cards []*Card) error {
+	for i, card := range cards {
+		if card == nil {
+			return fmt.Errorf("%w: no card at %d", ErrInvalidCard, i)
+		}
+		if !card.Valid() {
+			return fmt.Errorf("%w: suit %q, rank %d at %d", ErrInvalidCard, string(card.suit), int(card.rank), i)
+		}
+	}
+
+	return nil
+}
+
+// The JSON fields of a card
+type jsonCard struct {
+	Suit CardSuit
+	Rank Rank
+}
+
+// MarshalJSON
+// The card is encoded by its suit and rank name: {"Suit": "spade", "Rank": "10"}.
+func (c Card) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonCard{Suit: c.suit, Rank: c.rank})
+}
+
+// UnmarshalJSON
+// A card is decoded only if it is valid.
+func (c *Card) UnmarshalJSON(data []byte) error {
+	var card jsonCard
+	if err := json.Unmarshal(data, &card); err != nil {
+		return err
+	}
+
+	decoded, err := NewCard(card.Suit, card.Rank)
+	if err != nil {
+		return err
+	}
+
+	*c = *decoded
+	return nil
+}
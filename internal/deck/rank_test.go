@@ -0,0 +1,143 @@
+package deck
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestRank(t *testing.T) {
+	ranks := Ranks()
+	require.Len(t, ranks, 13)
+	require.Equal(t, Two, ranks[0])
+	require.Equal(t, Ace, ranks[12])
+
+	require.True(t, Ten.IsNumber())
+	require.False(t, Jack.IsNumber())
+	require.True(t, King.IsFace())
+	require.False(t, Ace.IsFace())
+	require.True(t, Joker.Valid())
+	require.False(t, Number.Valid())
+	require.False(t, Rank(16).Valid())
+
+	require.Equal(t, -1, Nine.Compare(Ten))
+	require.Equal(t, 1, Ace.Compare(King))
+	require.Equal(t, 0, Queen.Compare(Queen))
+	require.True(t, Jack < Queen)
+
+	require.Equal(t, 1, Card{suit: Spade, rank: Ace}.Compare(Card{suit: Heart, rank: Two}))
+	require.Equal(t, 0, Card{suit: Spade, rank: Seven}.Compare(Card{suit: Heart, rank: Seven}))
+}
+
+func TestParseRank(t *testing.T) {
+	testCases := []struct {
+		text string
+		rank Rank
+		err  error
+	}{
+		{text: "2", rank: Two},
+		{text: "10", rank: Ten},
+		{text: "Q", rank: Queen},
+		{text: "ten", rank: Ten},
+		{text: "ace", rank: Ace},
+		{text: "JK", rank: Joker},
+		{text: "joker", rank: Joker},
+		{text: "1", err: ErrInvalidRank},
+		{text: "11", err: ErrInvalidRank},
+		{text: "07", err: ErrInvalidRank},
+		{text: "number", err: ErrInvalidRank},
+		{text: "", err: ErrInvalidRank},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.text, func(t *testing.T) {
+			rank, err := ParseRank(tc.text)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.rank, rank)
+		})
+	}
+
+	for rank := Two; rank <= Joker; rank++ {
+		parsed, err := ParseRank(rank.String())
+		require.NoError(t, err)
+		require.Equal(t, rank, parsed)
+	}
+}
+
+func TestNewCard(t *testing.T) {
+	testCases := []struct {
+		name  string
+		suit  CardSuit
+		rank  Rank
+		valid bool
+	}{
+		{name: "Ace", suit: Spade, rank: Ace, valid: true},
+		{name: "Joker", suit: TrumpCard, rank: Joker, valid: true},
+		{name: "Joker Of Spades", suit: Spade, rank: Joker},
+		{name: "Trump Two", suit: TrumpCard, rank: Two},
+		{name: "Number Placeholder", suit: Heart, rank: Number},
+		{name: "Unknown Suit", suit: "star", rank: Two},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			card, err := NewCard(tc.suit, tc.rank)
+			if !tc.valid {
+				require.ErrorIs(t, err, ErrInvalidCard)
+				require.False(t, Card{suit: tc.suit, rank: tc.rank}.Valid())
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.suit, card.Suit())
+			require.Equal(t, tc.rank, card.Rank())
+			require.True(t, card.Valid())
+			require.Equal(t, card, MustNewCard(tc.suit, tc.rank))
+		})
+	}
+
+	require.Panics(t, func() { MustNewCard(Spade, Joker) })
+}
+
+func TestValidateCards(t *testing.T) {
+	require.NoError(t, ValidateCards(MustParseCards("As 10h JK")))
+	require.NoError(t, ValidateCards(nil))
+
+	// The zero value is the only card that is not Valid
+	require.ErrorIs(t, ValidateCards([]*Card{MustNewCard(Spade, Ace), {}}), ErrInvalidCard)
+	require.ErrorIs(t, ValidateCards([]*Card{nil}), ErrInvalidCard)
+}
+
+func TestColor(t *testing.T) {
+	require.Equal(t, Red, Heart.Color())
+	require.Equal(t, Red, Card{suit: Diamond, rank: Two}.Color())
+	require.Equal(t, Black, Spade.Color())
+	require.Equal(t, Black, Clover.Color())
+	require.Equal(t, []CardSuit{Spade, Heart, Clover, Diamond}, Suits())
+}
+
+func TestCard_JSON(t *testing.T) {
+	data, err := json.Marshal(&Card{suit: Spade, rank: Ten})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"Suit": "spade", "Rank": "10"}`, string(data))
+
+	var card Card
+	require.NoError(t, json.Unmarshal([]byte(`{"Suit": "heart", "Rank": "queen"}`), &card))
+	require.Equal(t, Card{suit: Heart, rank: Queen}, card)
+
+	require.ErrorIs(t, json.Unmarshal([]byte(`{"Suit": "heart", "Rank": "11"}`), &card), ErrInvalidRank)
+	require.ErrorIs(t, json.Unmarshal([]byte(`{"Suit": "heart", "Rank": "joker"}`), &card), ErrInvalidCard)
+	require.ErrorIs(t, json.Unmarshal([]byte(`{"Suit": "heart"}`), &card), ErrInvalidCard)
+
+	_, err = json.Marshal(&Card{suit: Heart, rank: Number})
+	require.ErrorIs(t, err, ErrInvalidRank)
+}
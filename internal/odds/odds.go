@@ -0,0 +1,377 @@
+/*
+  This package computes the probabilities of the final dealer points for any shoe composition.
+*/
+
+package odds
+
+import (
+	"course/internal/deck"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+const (
+	// Number of the card values, the ace is 1 and the tens and faces are 10
+	CardValues = 10
+	maxPoints  = 21
+	// The dealer takes cards below this number of points
+	dealerStandPoints = 17
+	// Cards of a value in a deck, the ten-valued cards are counted together
+	rankCardsInDeck = 4
+	tenCardsInDeck  = 16
+	// Number of the outcomes a calculator remembers, the memo starts over when it is full
+	defaultMemoSize = 1 << 14
+)
+
+var (
+	ErrInvalidCard  = errors.New("invalid card")
+	ErrEmptyShoe    = errors.New("no cards left for the dealer")
+	ErrNoCardInShoe = errors.New("the card is not in the shoe")
+)
+
+// Shoe
+// Cards left in the shoe by value. The zero value is an empty shoe.
+type Shoe struct {
+	counts [CardValues + 1]int
+	total  int
+}
+
+// NewShoe
+// Full shoe of the decks number.
+func NewShoe(decksNumber int) Shoe {
+	var s Shoe
+
+	for value := 1; value <= CardValues; value++ {
+		count := rankCardsInDeck * decksNumber
+		if value == 10 {
+			count = tenCardsInDeck * decksNumber
+		}
+
+		s.counts[value] = count
+		s.total += count
+	}
+
+	return s
+}
+
+// ShoeOf
+// Shoe of the cards.
+func ShoeOf(cards []*deck.Card) (Shoe, error) {
+	var s Shoe
+
+	for _, card := range cards {
+		value, err := CardValue(card)
+		if err != nil {
+			return Shoe{}, err
+		}
+
+		s.counts[value]++
+		s.total++
+	}
+
+	return s, nil
+}
+
+// Count
+// Number of the cards of the value from 1 (ace) to 10.
+func (s Shoe) Count(value int) int {
+	if value < 1 || value > CardValues {
+		return 0
+	}
+
+	return s.counts[value]
+}
+
+func (s Shoe) Total() int {
+	return s.total
+}
+
+// Probability
+// Probability that the next card has the value.
+func (s Shoe) Probability(value int) float64 {
+	if s.total == 0 {
+		return 0
+	}
+
+	return float64(s.Count(value)) / float64(s.total)
+}
+
+// Without
+// The shoe without the cards of the values.
+func (s Shoe) Without(values ...int) (Shoe, error) {
+	for _, value := range values {
+		if s.Count(value) == 0 {
+			return Shoe{}, fmt.Errorf("%w: %d", ErrNoCardInShoe, value)
+		}
+
+		s.counts[value]--
+		s.total--
+	}
+
+	return s, nil
+}
+
+// Rules
+// Table rules that change the dealer outcomes.
+type Rules struct {
+	// The dealer takes a card on soft 17
+	DealerHitsSoft17 bool
+	// The dealer checks the hole card for a natural before the players play.
+	// The outcomes are conditioned on the dealer having no natural
+	DealerPeeks bool
+	// A natural is an outcome of its own that beats 21 points, otherwise it is counted as 21 points.
+	// A natural is always on its own with the peek
+	NaturalBeats21 bool
+}
+
+// Outcomes
+// Probabilities of the final dealer hand.
+type Outcomes struct {
+	// Final points from 17 to 21, Points[0] is 17
+	Points [maxPoints - dealerStandPoints + 1]float64
+	Bust   float64
+	// Probability of a natural. With the peek it is the chance of the peeked natural,
+	// and the points and the bust sum up to 1 without it
+	Blackjack float64
+	// The outcomes are conditioned on no natural
+	peeked bool
+}
+
+// Probability
+// Probability that the dealer ends with the points, 0 for the points below 17.
+func (o Outcomes) Probability(points int) float64 {
+	if points < dealerStandPoints || points > maxPoints {
+		return 0
+	}
+
+	return o.Points[points-dealerStandPoints]
+}
+
+// StandEV
+// Expected value of standing with the points against the dealer, in bets.
+// A dealer natural is a loss, with the peek the value is for the hands that are played.
+func (o Outcomes) StandEV(points int) float64 {
+	if points > maxPoints {
+		return -1
+	}
+
+	ev := o.Bust
+	if !o.peeked {
+		ev -= o.Blackjack
+	}
+
+	for dealerPoints := dealerStandPoints; dealerPoints <= maxPoints; dealerPoints++ {
+		if points > dealerPoints {
+			ev += o.Probability(dealerPoints)
+		} else if points < dealerPoints {
+			ev -= o.Probability(dealerPoints)
+		}
+	}
+
+	return ev
+}
+
+type memoKey struct {
+	upValue int
+	shoe    Shoe
+}
+
+// Calculator
+// Computes the dealer outcomes by the rules and remembers the last ones by the up card and the shoe.
+// It is safe for concurrent use.
+type Calculator struct {
+	rules Rules
+	mutex sync.Mutex
+	memo  map[memoKey]Outcomes
+	// The keys are whole shoes, so the memo of a long game would grow without the limit
+	memoSize int
+}
+
+func NewCalculator(rules Rules) *Calculator {
+	return &Calculator{
+		rules:    rules,
+		memo:     make(map[memoKey]Outcomes),
+		memoSize: defaultMemoSize,
+	}
+}
+
+func (c *Calculator) Rules() Rules {
+	return c.rules
+}
+
+// Outcomes
+// Outcomes of the dealer with the up card value from 1 (ace) to 10. The shoe is the cards
+// the hole card and the next dealer cards are taken from, without the up card.
+func (c *Calculator) Outcomes(upValue int, shoe Shoe) (Outcomes, error) {
+	if upValue < 1 || upValue > CardValues {
+		return Outcomes{}, fmt.Errorf("%w: value %d", ErrInvalidCard, upValue)
+	}
+
+	if shoe.total == 0 {
+		return Outcomes{}, ErrEmptyShoe
+	}
+
+	key := memoKey{upValue: upValue, shoe: shoe}
+
+	c.mutex.Lock()
+	outcomes, ok := c.memo[key]
+	c.mutex.Unlock()
+
+	if ok {
+		return outcomes, nil
+	}
+
+	outcomes = c.compute(upValue, shoe)
+
+	c.mutex.Lock()
+	if len(c.memo) >= c.memoSize {
+		c.memo = make(map[memoKey]Outcomes)
+	}
+	c.memo[key] = outcomes
+	c.mutex.Unlock()
+
+	return outcomes, nil
+}
+
+// OutcomesOf
+// Outcomes of the dealer with the up card.
+func (c *Calculator) OutcomesOf(upCard *deck.Card, shoe Shoe) (Outcomes, error) {
+	upValue, err := CardValue(upCard)
+	if err != nil {
+		return Outcomes{}, err
+	}
+
+	return c.Outcomes(upValue, shoe)
+}
+
+func (c *Calculator) compute(upValue int, shoe Shoe) Outcomes {
+	outcomes := Outcomes{
+		peeked: c.rules.DealerPeeks,
+	}
+
+	// The hole card that makes a natural
+	naturalValue := 0
+	if upValue == 1 {
+		naturalValue = 10
+	} else if upValue == 10 {
+		naturalValue = 1
+	}
+
+	natural := c.rules.DealerPeeks || c.rules.NaturalBeats21
+	if natural && naturalValue > 0 {
+		outcomes.Blackjack = shoe.Probability(naturalValue)
+	}
+
+	up := Hand{}.Add(upValue)
+
+	for value := 1; value <= CardValues; value++ {
+		if shoe.counts[value] == 0 || (natural && value == naturalValue) {
+			continue
+		}
+
+		rest, _ := shoe.Without(value)
+		c.play(rest, up.Add(value), shoe.Probability(value), &outcomes)
+	}
+
+	if c.rules.DealerPeeks && outcomes.Blackjack > 0 && outcomes.Blackjack < 1 {
+		played := 1 - outcomes.Blackjack
+
+		for i := range outcomes.Points {
+			outcomes.Points[i] /= played
+		}
+		outcomes.Bust /= played
+	}
+
+	return outcomes
+}
+
+func (c *Calculator) play(shoe Shoe, dealer Hand, probability float64, outcomes *Outcomes) {
+	points, soft := dealer.Points()
+
+	if points > maxPoints {
+		outcomes.Bust += probability
+		return
+	}
+
+	if points >= dealerStandPoints && !(c.rules.DealerHitsSoft17 && soft && points == dealerStandPoints) {
+		outcomes.Points[points-dealerStandPoints] += probability
+		return
+	}
+
+	// The shoe ran out: the hand can not be finished and is left out of the outcomes
+	if shoe.total == 0 {
+		return
+	}
+
+	for value := 1; value <= CardValues; value++ {
+		if shoe.counts[value] == 0 {
+			continue
+		}
+
+		rest, _ := shoe.Without(value)
+		c.play(rest, dealer.Add(value), probability*shoe.Probability(value), outcomes)
+	}
+}
+
+// Hand
+// Cards of a hand by their values: the sum with the aces counted as 1 and whether the hand has an ace.
+// The zero value is an empty hand.
+type Hand struct {
+	Sum int
+	Ace bool
+}
+
+// HandOf
+// Hand of the cards.
+func HandOf(cards []*deck.Card) (Hand, error) {
+	var h Hand
+
+	for _, card := range cards {
+		value, err := CardValue(card)
+		if err != nil {
+			return Hand{}, err
+		}
+		h = h.Add(value)
+	}
+
+	return h, nil
+}
+
+// Add
+// The hand with one more card of the value from 1 (ace) to 10.
+func (h Hand) Add(value int) Hand {
+	return Hand{
+		Sum: h.Sum + value,
+		Ace: h.Ace || value == 1,
+	}
+}
+
+// Points
+// Points of the hand and whether it is soft: an ace is counted as 11 if the hand does not bust with it.
+func (h Hand) Points() (int, bool) {
+	if h.Ace && h.Sum+10 <= maxPoints {
+		return h.Sum + 10, true
+	}
+
+	return h.Sum, false
+}
+
+// CardValue
+// Value of the card from 1 (ace) to 10.
+func CardValue(card *deck.Card) (int, error) {
+	if card == nil {
+		return 0, ErrInvalidCard
+	}
+
+	switch {
+	case card.Rank() == deck.Ace:
+		return 1, nil
+	case card.Rank().IsFace():
+		return 10, nil
+	case card.Rank().IsNumber():
+		return int(card.Rank()), nil
+	}
+
+	return 0, fmt.Errorf("%w: %s", ErrInvalidCard, card)
+}
@@ -0,0 +1,245 @@
+package odds
+
+import (
+	"course/internal/deck"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func getTestShoe(t *testing.T, values ...deck.Rank) Shoe {
+	cards := make([]*deck.Card, 0, len(values))
+
+	for _, value := range values {
+		cards = append(cards, deck.MustNewCard(deck.Diamond, value))
+	}
+
+	shoe, err := ShoeOf(cards)
+	require.NoError(t, err)
+
+	return shoe
+}
+
+func TestCalculator_Outcomes(t *testing.T) {
+	testCases := []struct {
+		name    string
+		rules   Rules
+		upValue int
+		shoe    []deck.Rank
+		check   func(outcomes Outcomes, err error)
+	}{
+		{
+			name:    "Ten Against Small Shoe",
+			upValue: 10,
+			shoe:    []deck.Rank{deck.King, deck.Five, deck.Four},
+			check: func(outcomes Outcomes, err error) {
+				require.NoError(t, err)
+				// The king gives 20, the 5 and the 4 give 19 or the bust with the next card
+				require.InDelta(t, 1.0/3, outcomes.Probability(20), 1e-9)
+				require.InDelta(t, 1.0/3, outcomes.Probability(19), 1e-9)
+				require.InDelta(t, 1.0/3, outcomes.Bust, 1e-9)
+				require.InDelta(t, 0, outcomes.StandEV(19), 1e-9)
+				require.InDelta(t, 1, outcomes.StandEV(21), 1e-9)
+				require.InDelta(t, -1.0/3, outcomes.StandEV(17), 1e-9)
+				require.InDelta(t, -1, outcomes.StandEV(22), 1e-9)
+			},
+		},
+		{
+			name:    "Natural Counted As 21",
+			upValue: 1,
+			shoe:    []deck.Rank{deck.King, deck.Nine},
+			check: func(outcomes Outcomes, err error) {
+				require.NoError(t, err)
+				require.Zero(t, outcomes.Blackjack)
+				require.InDelta(t, 0.5, outcomes.Probability(21), 1e-9)
+				require.InDelta(t, 0.5, outcomes.Probability(20), 1e-9)
+			},
+		},
+		{
+			name:    "Natural Beats 21",
+			rules:   Rules{NaturalBeats21: true},
+			upValue: 1,
+			shoe:    []deck.Rank{deck.King, deck.Nine},
+			check: func(outcomes Outcomes, err error) {
+				require.NoError(t, err)
+				require.InDelta(t, 0.5, outcomes.Blackjack, 1e-9)
+				require.Zero(t, outcomes.Probability(21))
+				require.InDelta(t, 0.5, outcomes.Probability(20), 1e-9)
+				// Standing 20 pushes with 20 and loses to the natural
+				require.InDelta(t, -0.5, outcomes.StandEV(20), 1e-9)
+			},
+		},
+		{
+			name:    "Peek",
+			rules:   Rules{DealerPeeks: true},
+			upValue: 1,
+			shoe:    []deck.Rank{deck.King, deck.Nine},
+			check: func(outcomes Outcomes, err error) {
+				require.NoError(t, err)
+				require.InDelta(t, 0.5, outcomes.Blackjack, 1e-9)
+				require.InDelta(t, 1, outcomes.Probability(20), 1e-9)
+				require.InDelta(t, 0, outcomes.StandEV(20), 1e-9)
+			},
+		},
+		{
+			name:    "Stands Soft 17",
+			upValue: 1,
+			shoe:    []deck.Rank{deck.Six, deck.Four, deck.Four},
+			check: func(outcomes Outcomes, err error) {
+				require.NoError(t, err)
+				require.InDelta(t, 1.0/3, outcomes.Probability(17), 1e-9)
+				require.InDelta(t, 1.0/3, outcomes.Probability(19), 1e-9)
+				require.InDelta(t, 1.0/3, outcomes.Probability(21), 1e-9)
+			},
+		},
+		{
+			name:    "Hits Soft 17",
+			rules:   Rules{DealerHitsSoft17: true},
+			upValue: 1,
+			shoe:    []deck.Rank{deck.Six, deck.Four, deck.Four},
+			check: func(outcomes Outcomes, err error) {
+				require.NoError(t, err)
+				require.Zero(t, outcomes.Probability(17))
+				require.InDelta(t, 1.0/3, outcomes.Probability(19), 1e-9)
+				require.InDelta(t, 2.0/3, outcomes.Probability(21), 1e-9)
+			},
+		},
+		{
+			name:    "Empty Shoe",
+			upValue: 10,
+			check: func(outcomes Outcomes, err error) {
+				require.ErrorIs(t, err, ErrEmptyShoe)
+			},
+		},
+		{
+			name:    "Invalid Up Card",
+			upValue: 11,
+			shoe:    []deck.Rank{deck.Six},
+			check: func(outcomes Outcomes, err error) {
+				require.ErrorIs(t, err, ErrInvalidCard)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			outcomes, err := NewCalculator(tc.rules).Outcomes(tc.upValue, getTestShoe(t, tc.shoe...))
+			tc.check(outcomes, err)
+		})
+	}
+}
+
+func TestCalculator_FullShoe(t *testing.T) {
+	for _, rules := range []Rules{{}, {DealerHitsSoft17: true}, {DealerPeeks: true}, {NaturalBeats21: true}} {
+		c := NewCalculator(rules)
+
+		for upValue := 1; upValue <= CardValues; upValue++ {
+			shoe, err := NewShoe(6).Without(upValue)
+			require.NoError(t, err)
+
+			outcomes, err := c.Outcomes(upValue, shoe)
+			require.NoError(t, err)
+
+			total := outcomes.Bust
+			for _, probability := range outcomes.Points {
+				total += probability
+			}
+
+			if !rules.DealerPeeks {
+				total += outcomes.Blackjack
+			}
+			require.InDelta(t, 1, total, 1e-9)
+
+			memoized, err := c.Outcomes(upValue, shoe)
+			require.NoError(t, err)
+			require.Equal(t, outcomes, memoized)
+		}
+	}
+
+	shoe, err := NewShoe(6).Without(6)
+	require.NoError(t, err)
+
+	stands, err := NewCalculator(Rules{}).Outcomes(6, shoe)
+	require.NoError(t, err)
+	hits, err := NewCalculator(Rules{DealerHitsSoft17: true}).Outcomes(6, shoe)
+	require.NoError(t, err)
+
+	// A dealer 6 busts about 42% of the time and more often when he hits soft 17
+	require.InDelta(t, 0.42, stands.Bust, 0.01)
+	require.Greater(t, hits.Bust, stands.Bust)
+	require.Less(t, hits.Probability(17), stands.Probability(17))
+}
+
+func TestShoe(t *testing.T) {
+	shoe := NewShoe(2)
+	require.Equal(t, 104, shoe.Total())
+	require.Equal(t, 32, shoe.Count(10))
+	require.Equal(t, 0, shoe.Count(11))
+	require.InDelta(t, 8.0/104, shoe.Probability(1), 1e-9)
+
+	rest, err := shoe.Without(1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 102, rest.Total())
+	require.Equal(t, 7, rest.Count(1))
+
+	_, err = getTestShoe(t, deck.Five).Without(5, 5)
+	require.ErrorIs(t, err, ErrNoCardInShoe)
+
+	_, err = ShoeOf([]*deck.Card{deck.MustNewCard(deck.TrumpCard, deck.Joker)})
+	require.ErrorIs(t, err, ErrInvalidCard)
+}
+
+func TestHandOf(t *testing.T) {
+	testCases := []struct {
+		name   string
+		cards  string
+		points int
+		soft   bool
+		err    error
+	}{
+		{name: "Empty", cards: "", points: 0},
+		{name: "Hard", cards: "Ks 7h", points: 17},
+		{name: "Soft", cards: "As 6h", points: 17, soft: true},
+		{name: "Ace Counted As 1", cards: "As 6h 9d", points: 16},
+		{name: "Two Aces", cards: "As Ah", points: 12, soft: true},
+		{name: "Bust", cards: "Ks Qh 2d", points: 22},
+		{name: "Joker", cards: "JK 5h", err: ErrInvalidCard},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			h, err := HandOf(deck.MustParseCards(tc.cards))
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			points, soft := h.Points()
+			require.Equal(t, tc.points, points)
+			require.Equal(t, tc.soft, soft)
+		})
+	}
+}
+
+func TestCalculator_MemoSize(t *testing.T) {
+	c := NewCalculator(Rules{})
+	c.memoSize = 3
+	shoe := NewShoe(1)
+
+	// Every shoe is a new key, the memo starts over when it is full
+	for value := 1; value <= 5; value++ {
+		var err error
+		shoe, err = shoe.Without(value)
+		require.NoError(t, err)
+
+		_, err = c.Outcomes(10, shoe)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(c.memo), c.memoSize)
+	}
+
+	require.Len(t, c.memo, 2)
+}
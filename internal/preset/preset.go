@@ -0,0 +1,158 @@
+/*
+  This package implements loading of named table presets from a config file.
+*/
+
+package preset
+
+import (
+	"course/internal/blackjack"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"sort"
+	"time"
+)
+
+var (
+	ErrPresetNotFound = errors.New("preset not found")
+	ErrInvalidPreset  = errors.New("invalid preset")
+)
+
+// Preset
+// Table setup. Fields that are not set in the file leave the config unchanged.
+// In JSON the delay is given in nanoseconds.
+type Preset struct {
+	Decks             *int           `yaml:"decks" json:"decks,omitempty"`
+	DealerHitsSoft17  *bool          `yaml:"dealerHitsSoft17" json:"dealerHitsSoft17,omitempty"`
+	BlackjackPays3To2 *bool          `yaml:"blackjackPays3To2" json:"blackjackPays3To2,omitempty"`
+	NoHoleCard        *bool          `yaml:"noHoleCard" json:"noHoleCard,omitempty"`
+	Bots              *int           `yaml:"bots" json:"bots,omitempty"`
+	StartingMoney     *int           `yaml:"startingMoney" json:"startingMoney,omitempty"`
+	Delay             *time.Duration `yaml:"delay" json:"delay,omitempty"`
+	Penetration       *float64       `yaml:"penetration" json:"penetration,omitempty"`
+	// Strategy names of the bot seats in order
+	BotStrategies []string `yaml:"botStrategies" json:"botStrategies,omitempty"`
+}
+
+type file struct {
+	Presets map[string]Preset `yaml:"presets"`
+}
+
+// Presets
+// Named presets from the config file.
+type Presets map[string]Preset
+
+// Load
+// Read the presets from a YAML or JSON file and validate them.
+func Load(path string) (Presets, error) {
+	configFile, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer configFile.Close()
+
+	// JSON is a subset of YAML, so both are read by the YAML decoder
+	decoder := yaml.NewDecoder(configFile)
+	decoder.KnownFields(true)
+
+	var presetsFile file
+	if err = decoder.Decode(&presetsFile); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPreset, err)
+	}
+
+	presets := Presets(presetsFile.Presets)
+
+	for _, name := range presets.Names() {
+		if err = presets[name].Validate(); err != nil {
+			return nil, fmt.Errorf("%w %q: %v", ErrInvalidPreset, name, err)
+		}
+	}
+
+	return presets, nil
+}
+
+// Names
+// Sorted names of the presets.
+func (p Presets) Names() []string {
+	names := make([]string, 0, len(p))
+
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Get
+// Find the preset by name.
+func (p Presets) Get(name string) (Preset, error) {
+	preset, ok := p[name]
+	if !ok {
+		return Preset{}, fmt.Errorf("%w: %q, available presets: %v", ErrPresetNotFound, name, p.Names())
+	}
+
+	return preset, nil
+}
+
+// Apply
+// Set the fields of the preset in the config.
+func (p Preset) Apply(cfg *blackjack.Config) {
+	if p.Decks != nil {
+		cfg.DecksNumber = *p.Decks
+	}
+
+	if p.DealerHitsSoft17 != nil {
+		cfg.Rules.DealerHitsSoft17 = *p.DealerHitsSoft17
+	}
+
+	if p.BlackjackPays3To2 != nil {
+		cfg.Rules.BlackjackPays3To2 = *p.BlackjackPays3To2
+	}
+
+	if p.NoHoleCard != nil {
+		cfg.Rules.NoHoleCard = *p.NoHoleCard
+	}
+
+	if p.Bots != nil {
+		cfg.BotsNumber = *p.Bots
+	}
+
+	if p.StartingMoney != nil {
+		cfg.PlayersStartingMoney = *p.StartingMoney
+	}
+
+	if p.Delay != nil {
+		cfg.Delay = *p.Delay
+		cfg.NoDelay = *p.Delay == 0
+	}
+
+	if p.Penetration != nil {
+		cfg.Penetration = *p.Penetration
+	}
+
+	if p.BotStrategies != nil {
+		cfg.BotStrategyNames = append([]string(nil), p.BotStrategies...)
+	}
+}
+
+// Validate
+// Check the preset against the blackjack.Config constraints.
+func (p Preset) Validate() error {
+	for _, name := range p.BotStrategies {
+		if _, err := blackjack.NewStrategy(name, nil); err != nil {
+			return err
+		}
+	}
+
+	cfg := blackjack.Config{
+		PlayersStartingMoney: 1,
+		BotsNumber:           blackjack.MaxBotsNumber,
+		// The username is not a part of the preset
+		Username: "preset",
+	}
+	p.Apply(&cfg)
+
+	return cfg.Validate()
+}
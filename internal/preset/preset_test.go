@@ -0,0 +1,165 @@
+package preset
+
+import (
+	"course/internal/blackjack"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeTestConfig(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	testCases := []struct {
+		name  string
+		path  func() string
+		check func(presets Presets, err error)
+	}{
+		{
+			name: "Repository Presets",
+			path: func() string { return "../../configs/presets.yaml" },
+			check: func(presets Presets, err error) {
+				require.NoError(t, err)
+				require.Equal(t, []string{"atlantic-city", "european", "single-deck", "vegas-strip"}, presets.Names())
+			},
+		},
+		{
+			name: "JSON",
+			path: func() string {
+				return writeTestConfig(t, "presets.json", `{"presets": {"fast": {"bots": 2, "delay": "0s"}}}`)
+			},
+			check: func(presets Presets, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 2, *presets["fast"].Bots)
+				require.Equal(t, time.Duration(0), *presets["fast"].Delay)
+			},
+		},
+		{
+			name: "Unknown Field",
+			path: func() string {
+				return writeTestConfig(t, "presets.yaml", "presets:\n  fast:\n    speed: 10\n")
+			},
+			check: func(presets Presets, err error) {
+				require.ErrorIs(t, err, ErrInvalidPreset)
+			},
+		},
+		{
+			name: "Invalid Bots Number",
+			path: func() string {
+				return writeTestConfig(t, "presets.yaml", "presets:\n  crowded:\n    bots: 20\n")
+			},
+			check: func(presets Presets, err error) {
+				require.ErrorIs(t, err, ErrInvalidPreset)
+				require.ErrorContains(t, err, blackjack.ErrBotsNumberGreaterThan.Error())
+			},
+		},
+		{
+			name: "Unknown Bot Strategy",
+			path: func() string {
+				return writeTestConfig(t, "presets.yaml", "presets:\n  psychic:\n    botStrategies: [mind-reader]\n")
+			},
+			check: func(presets Presets, err error) {
+				require.ErrorIs(t, err, ErrInvalidPreset)
+				require.ErrorContains(t, err, blackjack.ErrUnknownStrategy.Error())
+			},
+		},
+		{
+			name: "Invalid Decks Number",
+			path: func() string {
+				return writeTestConfig(t, "presets.yaml", "presets:\n  shoe:\n    decks: 100\n")
+			},
+			check: func(presets Presets, err error) {
+				require.ErrorIs(t, err, ErrInvalidPreset)
+			},
+		},
+		{
+			name: "Invalid Penetration",
+			path: func() string {
+				return writeTestConfig(t, "presets.yaml", "presets:\n  shoe:\n    penetration: 1.5\n")
+			},
+			check: func(presets Presets, err error) {
+				require.ErrorIs(t, err, ErrInvalidPreset)
+				require.ErrorContains(t, err, blackjack.ErrInvalidPenetration.Error())
+			},
+		},
+		{
+			name: "No File",
+			path: func() string { return filepath.Join(t.TempDir(), "none.yaml") },
+			check: func(presets Presets, err error) {
+				require.Error(t, err)
+				require.Nil(t, presets)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			presets, err := Load(tc.path())
+			tc.check(presets, err)
+		})
+	}
+}
+
+func TestPresets_Get(t *testing.T) {
+	presets, err := Load("../../configs/presets.yaml")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name   string
+		preset string
+		check  func(cfg blackjack.Config, err error)
+	}{
+		{
+			name:   "European",
+			preset: "european",
+			check: func(cfg blackjack.Config, err error) {
+				require.NoError(t, err)
+				require.True(t, cfg.Rules.NoHoleCard)
+				require.Equal(t, 6, cfg.DecksNumber)
+				require.Equal(t, 0.75, cfg.Penetration)
+				require.Len(t, cfg.BotStrategyNames, 3)
+				require.Equal(t, blackjack.StrategyDealer, cfg.BotStrategyNames[0])
+			},
+		},
+		{
+			name:   "Single Deck",
+			preset: "single-deck",
+			check: func(cfg blackjack.Config, err error) {
+				require.NoError(t, err)
+				require.True(t, cfg.Rules.DealerHitsSoft17)
+				require.Equal(t, 1, cfg.DecksNumber)
+				require.Equal(t, 500*time.Millisecond, cfg.Delay)
+			},
+		},
+		{
+			name:   "Unknown",
+			preset: "monte-carlo",
+			check: func(cfg blackjack.Config, err error) {
+				require.ErrorIs(t, err, ErrPresetNotFound)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := blackjack.Config{Username: "Alex"}
+
+			tablePreset, err := presets.Get(tc.preset)
+			if err == nil {
+				tablePreset.Apply(&cfg)
+			}
+			tc.check(cfg, err)
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"course/internal/blackjack"
+	"encoding/json"
+	"net"
+)
+
+// Client
+// Connection of a player to the server.
+type Client struct {
+	conn    net.Conn
+	encoder *json.Encoder
+	decoder *json.Decoder
+}
+
+func Dial(addr string) (*Client, error) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		conn:    conn,
+		encoder: json.NewEncoder(conn),
+		decoder: json.NewDecoder(conn),
+	}, nil
+}
+
+func (c *Client) Send(req Request) error {
+	return c.encoder.Encode(req)
+}
+
+// Join
+// Take any free user seat of the table.
+func (c *Client) Join(table string, name string) error {
+	return c.Send(Request{Type: RequestJoin, Table: table, Name: name})
+}
+
+// Rejoin
+// Take back the disconnected seat of the player with the reconnect token of his MessageJoined.
+func (c *Client) Rejoin(table string, name string, token string) error {
+	return c.Send(Request{Type: RequestJoin, Table: table, Name: name, Token: token})
+}
+
+// Wait
+// Take any free user seat of the table, or wait for one if the table is full.
+func (c *Client) Wait(table string, name string) error {
+	return c.Send(Request{Type: RequestJoin, Table: table, Name: name, Wait: true})
+}
+
+// Reserve
+// Hold the seat of the table for the player, any free seat if the number is 0.
+func (c *Client) Reserve(table string, name string, seat int) error {
+	return c.Send(Request{Type: RequestReserve, Table: table, Name: name, Seat: seat})
+}
+
+func (c *Client) Bet(amount int) error {
+	return c.Send(Request{Type: RequestBet, Amount: amount})
+}
+
+func (c *Client) Move(move blackjack.Move) error {
+	return c.Send(Request{Type: RequestMove, Move: move})
+}
+
+func (c *Client) Leave() error {
+	return c.Send(Request{Type: RequestLeave})
+}
+
+// Receive
+// Wait for the next message of the server.
+func (c *Client) Receive() (Message, error) {
+	var message Message
+	err := c.decoder.Decode(&message)
+
+	return message, err
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
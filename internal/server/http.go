@@ -0,0 +1,511 @@
+package server
+
+import (
+	"course/internal/blackjack"
+	"course/internal/preset"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Longest wait of a state request for a new version
+const pollTimeout = 30 * time.Second
+
+// Idle timeout of the tables created over HTTP if the request has none
+const defaultHTTPIdleTimeout = 10 * time.Minute
+
+// Game of the tables created over HTTP before the preset is applied
+var defaultHTTPGame = blackjack.Config{
+	PlayersStartingMoney: 100,
+	BotsNumber:           1,
+	NoDelay:              true,
+}
+
+var ErrUnauthorized = errors.New("seat token is missing or invalid")
+
+// CreateTableRequest
+// Body of POST /tables.
+type CreateTableRequest struct {
+	Name  string `json:"name"`
+	Users int    `json:"users"`
+	// Setup of the game, the default game is 1 bot, 100 c. and no delay
+	Config preset.Preset `json:"config"`
+	// Time after which the unused table is removed, like "10m". 10 minutes if empty, never if "0"
+	IdleTimeout string `json:"idleTimeout,omitempty"`
+}
+
+// JoinRequest
+// Body of POST /tables/{name}/seats.
+type JoinRequest struct {
+	Name string `json:"name"`
+	// Number of the user seat from 1. Any free seat is taken if 0
+	Seat int `json:"seat"`
+	// Wait for a seat if the table is full
+	Wait bool `json:"wait"`
+	// Reconnect token of the disconnected seat the player takes back
+	ReconnectToken string `json:"reconnectToken,omitempty"`
+}
+
+// JoinResponse
+// The seat and the token of the player for the bets and the actions.
+// A waiting player has no seat yet, he has his place in the waitlist from 1.
+// The reconnect token takes the seat back after the session has ended, a waiting player gets it with MessageJoined.
+type JoinResponse struct {
+	Table          string `json:"table"`
+	Name           string `json:"name"`
+	Seat           int    `json:"seat,omitempty"`
+	Position       int    `json:"position,omitempty"`
+	Token          string `json:"token"`
+	ReconnectToken string `json:"reconnectToken,omitempty"`
+}
+
+// ReserveRequest
+// Body of POST /tables/{name}/reservations.
+type ReserveRequest struct {
+	Name string `json:"name"`
+	// Number of the user seat from 1. Any free seat is reserved if 0
+	Seat int `json:"seat"`
+}
+
+// BetRequest
+// Body of POST /tables/{name}/bets. Version is the version of the state in which the bet was asked for.
+type BetRequest struct {
+	Amount  int   `json:"amount"`
+	Version int64 `json:"version"`
+}
+
+// ActionRequest
+// Body of POST /tables/{name}/actions. Version is the version of the state in which the move was asked for.
+type ActionRequest struct {
+	Move    blackjack.Move `json:"move"`
+	Version int64          `json:"version"`
+}
+
+// AnswerResponse
+// Version of the state after the answer was accepted.
+type AnswerResponse struct {
+	Version int64 `json:"version"`
+}
+
+// ErrorResponse
+// Body of a failed request. Version is the current version of the state on a conflict.
+type ErrorResponse struct {
+	Error   string `json:"error"`
+	Version int64  `json:"version,omitempty"`
+}
+
+// Seat of an HTTP player. The player polls the state or reads the messages of his seat from event streams.
+// The seat is looked up by the session, a waiting player gets it from the waitlist
+type session struct {
+	token string
+	table *table
+
+	mu      sync.Mutex
+	streams map[*client]struct{}
+}
+
+func newSession(token string, t *table) *session {
+	return &session{
+		token:   token,
+		table:   t,
+		streams: make(map[*client]struct{}),
+	}
+}
+
+// Pass the message of the seat to the event streams of the player
+func (s *session) write(message Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for c := range s.streams {
+		c.write(message)
+	}
+}
+
+func (s *session) attach(c *client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.streams[c] = struct{}{}
+}
+
+func (s *session) detach(c *client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.streams, c)
+}
+
+// HTTPHandler
+// HTTP/JSON API of the tables:
+//
+//	GET    /tables                        list of the tables with their metrics
+//	POST   /tables                        create a table
+//	GET    /tables/{name}                 the table with its metrics
+//	GET    /tables/{name}/state           state of the table, ?after=N waits for a version greater than N
+//	POST   /tables/{name}/seats           take a seat or wait for it and get the token of the player
+//	DELETE /tables/{name}/seats           leave the seat or the waitlist
+//	POST   /tables/{name}/reservations    hold a free seat for a player
+//	POST   /tables/{name}/bets            answer the bet prompt
+//	POST   /tables/{name}/actions         answer the move prompt
+//	GET    /tables/{name}/events          WebSocket stream of the events, see streamEvents
+//
+// The requests of a player carry the header "Authorization: Bearer <token>". A bet or an action
+// is accepted only with the current version of the state, otherwise it fails with 409 Conflict.
+func (s *Server) HTTPHandler() http.Handler {
+	return http.HandlerFunc(s.serveHTTP)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	if len(parts) == 0 || parts[0] != "tables" || len(parts) > 3 {
+		writeError(w, http.StatusNotFound, errors.New("not found"))
+		return
+	}
+
+	if len(parts) == 1 {
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, s.Tables())
+		case http.MethodPost:
+			s.createTable(w, r)
+		default:
+			writeMethodNotAllowed(w, "GET, POST")
+		}
+		return
+	}
+
+	t, err := s.table(parts[1])
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+
+	if len(parts) == 2 {
+		if r.Method != http.MethodGet {
+			writeMethodNotAllowed(w, "GET")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, t.info())
+		return
+	}
+
+	switch {
+	case parts[2] == "state" && r.Method == http.MethodGet:
+		s.getState(w, r, t)
+	case parts[2] == "seats" && r.Method == http.MethodPost:
+		s.joinTable(w, r, t)
+	case parts[2] == "seats" && r.Method == http.MethodDelete:
+		s.leaveTable(w, r, t)
+	case parts[2] == "reservations" && r.Method == http.MethodPost:
+		s.reserveSeat(w, r, t)
+	case parts[2] == "bets" && r.Method == http.MethodPost:
+		s.bet(w, r, t)
+	case parts[2] == "actions" && r.Method == http.MethodPost:
+		s.act(w, r, t)
+	case parts[2] == "events" && r.Method == http.MethodGet:
+		s.streamEvents(w, r, t)
+	case parts[2] == "state" || parts[2] == "events":
+		writeMethodNotAllowed(w, "GET")
+	case parts[2] == "seats":
+		writeMethodNotAllowed(w, "POST, DELETE")
+	case parts[2] == "bets" || parts[2] == "actions" || parts[2] == "reservations":
+		writeMethodNotAllowed(w, "POST")
+	default:
+		writeError(w, http.StatusNotFound, errors.New("not found"))
+	}
+}
+
+func (s *Server) createTable(w http.ResponseWriter, r *http.Request) {
+	var req CreateTableRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	if err := req.Config.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	game := defaultHTTPGame
+	req.Config.Apply(&game)
+
+	idleTimeout := defaultHTTPIdleTimeout
+	if req.IdleTimeout != "" {
+		var err error
+		if idleTimeout, err = time.ParseDuration(req.IdleTimeout); err != nil {
+			writeError(w, http.StatusBadRequest, ErrInvalidRequest)
+			return
+		}
+	}
+
+	cfg := TableConfig{
+		Name:        req.Name,
+		Users:       req.Users,
+		Game:        game,
+		IdleTimeout: idleTimeout,
+	}
+
+	if err := s.AddTable(cfg); err != nil {
+		writeAPIError(w, err)
+		return
+	}
+
+	t, err := s.table(cfg.Name)
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, t.info())
+}
+
+func (s *Server) getState(w http.ResponseWriter, r *http.Request, t *table) {
+	after := int64(-1)
+
+	if value := r.URL.Query().Get("after"); value != "" {
+		var err error
+		if after, err = strconv.ParseInt(value, 10, 64); err != nil {
+			writeError(w, http.StatusBadRequest, ErrInvalidRequest)
+			return
+		}
+	}
+
+	timeout := time.NewTimer(pollTimeout)
+	defer timeout.Stop()
+
+	for {
+		state, changed := t.snapshot()
+		if state.Version > after {
+			writeJSON(w, http.StatusOK, state)
+			return
+		}
+
+		select {
+		case <-changed:
+		case <-timeout.C:
+			writeJSON(w, http.StatusOK, state)
+			return
+		case <-r.Context().Done():
+			return
+		case <-s.done:
+			writeJSON(w, http.StatusOK, state)
+			return
+		}
+	}
+}
+
+func (s *Server) joinTable(w http.ResponseWriter, r *http.Request, t *table) {
+	var req JoinRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	token, err := newToken()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	session := newSession(token, t)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		writeAPIError(w, ErrServerClosed)
+		return
+	}
+
+	response := JoinResponse{Table: t.cfg.Name, Name: req.Name, Token: token}
+	status := http.StatusCreated
+
+	seat, err := t.join(session, req.Name, req.Seat, req.ReconnectToken)
+	if errors.Is(err, ErrTableFull) && req.Wait {
+		response.Position, err = t.wait(session, req.Name)
+		status = http.StatusAccepted
+	}
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+
+	if seat != nil {
+		response.Seat = seat.number
+		response.ReconnectToken = seat.reconnectToken()
+	}
+
+	s.sessions[token] = session
+
+	writeJSON(w, status, response)
+}
+
+func (s *Server) leaveTable(w http.ResponseWriter, r *http.Request, t *table) {
+	session, _, err := s.session(r, t)
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+
+	s.leave(session)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// End the session, its seat stands until the player joins again
+func (s *Server) leave(session *session) {
+	s.mu.Lock()
+	delete(s.sessions, session.token)
+	s.mu.Unlock()
+
+	session.table.drop(session)
+}
+
+func (s *Server) reserveSeat(w http.ResponseWriter, r *http.Request, t *table) {
+	var req ReserveRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	reservation, err := t.reserve(req.Name, req.Seat)
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, reservation)
+}
+
+func (s *Server) bet(w http.ResponseWriter, r *http.Request, t *table) {
+	session, seat, err := s.session(r, t)
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+
+	var req BetRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	s.writeAnswer(w, t, answer(session, seat, blackjack.PromptBet, strconv.Itoa(req.Amount), req.Version))
+}
+
+func (s *Server) act(w http.ResponseWriter, r *http.Request, t *table) {
+	session, seat, err := s.session(r, t)
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+
+	var req ActionRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+
+	s.writeAnswer(w, t, answerMove(session, seat, req.Move, req.Version))
+}
+
+func (s *Server) writeAnswer(w http.ResponseWriter, t *table, err error) {
+	state, _ := t.snapshot()
+
+	if errors.Is(err, ErrVersionConflict) {
+		writeJSON(w, http.StatusConflict, ErrorResponse{Error: err.Error(), Version: state.Version})
+		return
+	}
+
+	if err != nil {
+		writeAPIError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, AnswerResponse{Version: state.Version})
+}
+
+// Session of the token of the request and its seat, nil while the player waits.
+// The session ends when the game of its table is over
+func (s *Server) session(r *http.Request, t *table) (*session, *seat, error) {
+	return s.sessionByToken(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), t)
+}
+
+func (s *Server) sessionByToken(token string, t *table) (*session, *seat, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[token]
+	if !ok || session.table != t {
+		return nil, nil, ErrUnauthorized
+	}
+
+	seat := t.seatOf(session)
+	if seat == nil && !t.isWaiting(session) {
+		delete(s.sessions, token)
+		return nil, nil, ErrUnauthorized
+	}
+
+	return session, seat, nil
+}
+
+func newToken() (string, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(token), nil
+}
+
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, ErrInvalidRequest)
+		return false
+	}
+
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, ErrorResponse{Error: err.Error()})
+}
+
+func writeMethodNotAllowed(w http.ResponseWriter, allowed string) {
+	w.Header().Set("Allow", allowed)
+	writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+}
+
+// Status of the error of the tables
+func writeAPIError(w http.ResponseWriter, err error) {
+	status := http.StatusBadRequest
+
+	switch {
+	case errors.Is(err, ErrUnknownTable):
+		status = http.StatusNotFound
+	case errors.Is(err, ErrUnauthorized), errors.Is(err, ErrNotJoined), errors.Is(err, ErrInvalidToken):
+		status = http.StatusUnauthorized
+	case errors.Is(err, ErrDuplicateTable), errors.Is(err, ErrNameTaken), errors.Is(err, ErrSeatTaken),
+		errors.Is(err, ErrTableFull), errors.Is(err, ErrNotYourTurn), errors.Is(err, ErrVersionConflict),
+		errors.Is(err, ErrSeatReserved), errors.Is(err, ErrAlreadyWaiting):
+		status = http.StatusConflict
+	case errors.Is(err, ErrServerClosed):
+		status = http.StatusServiceUnavailable
+	}
+
+	writeError(w, status, err)
+}
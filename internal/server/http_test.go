@@ -0,0 +1,270 @@
+package server
+
+import (
+	"bytes"
+	"course/internal/blackjack"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func startTestHTTPServer(t *testing.T, tables ...TableConfig) string {
+	s, err := New(tables...)
+	require.NoError(t, err)
+
+	httpServer := httptest.NewServer(s.HTTPHandler())
+	t.Cleanup(func() {
+		require.NoError(t, s.Close())
+		httpServer.Close()
+	})
+
+	return httpServer.URL
+}
+
+func doRequest(t *testing.T, method string, url string, token string, body any, response any) int {
+	var reader bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&reader).Encode(body))
+	}
+
+	req, err := http.NewRequest(method, url, &reader)
+	require.NoError(t, err)
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	if response != nil && resp.StatusCode != http.StatusNoContent {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(response))
+	}
+
+	return resp.StatusCode
+}
+
+// Poll the state of the table until the condition
+func waitForState(t *testing.T, url string, condition func(state TableState) bool) TableState {
+	deadline := time.Now().Add(5 * time.Second)
+	after := int64(-1)
+
+	for time.Now().Before(deadline) {
+		var state TableState
+		require.Equal(t, http.StatusOK, doRequest(t, http.MethodGet, fmt.Sprintf("%s?after=%d", url, after), "", nil, &state))
+
+		// Hidden cards of the dealer are never shown before the reveal
+		if state.Dealer.HiddenCards > 0 {
+			require.Len(t, state.Dealer.Cards, 1)
+		}
+
+		if condition(state) {
+			return state
+		}
+		after = state.Version
+	}
+
+	t.Fatal("timeout")
+	return TableState{}
+}
+
+func TestServer_HTTPGame(t *testing.T) {
+	url := startTestHTTPServer(t)
+
+	var info TableInfo
+	status := doRequest(t, http.MethodPost, url+"/tables", "", map[string]any{
+		"name":   "vip",
+		"users":  1,
+		"config": map[string]any{"startingMoney": 50, "bots": 2},
+	}, &info)
+	require.Equal(t, http.StatusCreated, status)
+	require.False(t, info.Metrics.LastActive.IsZero())
+
+	created := info
+	created.Metrics.LastActive = time.Time{}
+	require.Equal(t, TableInfo{Name: "vip", Users: 1, Bots: 2, Free: 1}, created)
+
+	var tables []TableInfo
+	require.Equal(t, http.StatusOK, doRequest(t, http.MethodGet, url+"/tables", "", nil, &tables))
+	require.Equal(t, []TableInfo{info}, tables)
+
+	var table TableInfo
+	require.Equal(t, http.StatusOK, doRequest(t, http.MethodGet, url+"/tables/vip", "", nil, &table))
+	require.Equal(t, info, table)
+
+	var joined JoinResponse
+	status = doRequest(t, http.MethodPost, url+"/tables/vip/seats", "", JoinRequest{Name: "Alex"}, &joined)
+	require.Equal(t, http.StatusCreated, status)
+	require.Equal(t, 1, joined.Seat)
+	require.NotEmpty(t, joined.Token)
+	require.NotEmpty(t, joined.ReconnectToken)
+	require.NotEqual(t, joined.Token, joined.ReconnectToken)
+
+	stateURL := url + "/tables/vip/state"
+	state := waitForState(t, stateURL, func(state TableState) bool {
+		return state.Turn == "Alex" && state.Prompt == blackjack.PromptBet
+	})
+	require.Len(t, state.Seats, 3)
+	require.Equal(t, 50, state.Seats[0].Money)
+
+	var answered AnswerResponse
+	status = doRequest(t, http.MethodPost, url+"/tables/vip/bets", joined.Token, BetRequest{Amount: 5, Version: state.Version}, &answered)
+	require.Equal(t, http.StatusAccepted, status)
+	require.Greater(t, answered.Version, state.Version)
+
+	// The second answer in the same version is rejected
+	var conflict ErrorResponse
+	status = doRequest(t, http.MethodPost, url+"/tables/vip/bets", joined.Token, BetRequest{Amount: 5, Version: state.Version}, &conflict)
+	require.Equal(t, http.StatusConflict, status)
+	require.Equal(t, ErrVersionConflict.Error(), conflict.Error)
+	require.Greater(t, conflict.Version, state.Version)
+
+	// Before the move the dealer shows one card and hides the other
+	state = waitForState(t, stateURL, func(state TableState) bool {
+		return state.Turn == "Alex" && state.Prompt == blackjack.PromptMove
+	})
+	require.Equal(t, 1, state.Dealer.HiddenCards)
+	require.Equal(t, 5, state.Seats[0].Bet)
+
+	status = doRequest(t, http.MethodPost, url+"/tables/vip/actions", joined.Token, ActionRequest{Move: blackjack.MoveStand, Version: state.Version}, &answered)
+	require.Equal(t, http.StatusAccepted, status)
+
+	// The round is settled and the next one waits for the bet
+	state = waitForState(t, stateURL, func(state TableState) bool {
+		return state.Round == 2 && state.Turn == "Alex"
+	})
+	require.Empty(t, state.Dealer.Cards)
+
+	status = doRequest(t, http.MethodDelete, url+"/tables/vip/seats", joined.Token, nil, nil)
+	require.Equal(t, http.StatusNoContent, status)
+
+	// Without the players the game is over and the seat is free
+	waitForState(t, stateURL, func(state TableState) bool {
+		return !state.Playing && state.Seats[0].Name == ""
+	})
+}
+
+func TestServer_HTTPErrors(t *testing.T) {
+	url := startTestHTTPServer(t, getTestTable())
+
+	var joined JoinResponse
+	require.Equal(t, http.StatusCreated, doRequest(t, http.MethodPost, url+"/tables/main/seats", "", JoinRequest{Name: "Alex"}, &joined))
+
+	testCases := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		body   any
+		status int
+		err    error
+	}{
+		{
+			name:   "Unknown Table",
+			method: http.MethodGet,
+			path:   "/tables/vip/state",
+			status: http.StatusNotFound,
+			err:    ErrUnknownTable,
+		},
+		{
+			name:   "Duplicate Table",
+			method: http.MethodPost,
+			path:   "/tables",
+			body:   CreateTableRequest{Name: "main", Users: 1},
+			status: http.StatusConflict,
+			err:    ErrDuplicateTable,
+		},
+		{
+			name:   "Invalid Table",
+			method: http.MethodPost,
+			path:   "/tables",
+			body:   CreateTableRequest{Name: "vip"},
+			status: http.StatusBadRequest,
+			err:    ErrInvalidUsers,
+		},
+		{
+			name:   "Unknown Field",
+			method: http.MethodPost,
+			path:   "/tables",
+			body:   map[string]any{"name": "vip", "users": 1, "seats": 2},
+			status: http.StatusBadRequest,
+			err:    ErrInvalidRequest,
+		},
+		{
+			name:   "Name Taken",
+			method: http.MethodPost,
+			path:   "/tables/main/seats",
+			body:   JoinRequest{Name: "Alex"},
+			status: http.StatusConflict,
+			err:    ErrNameTaken,
+		},
+		{
+			name:   "Invalid Seat",
+			method: http.MethodPost,
+			path:   "/tables/main/seats",
+			body:   JoinRequest{Name: "Sam", Seat: 3},
+			status: http.StatusBadRequest,
+			err:    ErrInvalidSeat,
+		},
+		{
+			name:   "No Token",
+			method: http.MethodPost,
+			path:   "/tables/main/bets",
+			body:   BetRequest{Amount: 10},
+			status: http.StatusUnauthorized,
+			err:    ErrUnauthorized,
+		},
+		{
+			name:   "Invalid Token",
+			method: http.MethodPost,
+			path:   "/tables/main/actions",
+			token:  "0123",
+			body:   ActionRequest{Move: blackjack.MoveHit},
+			status: http.StatusUnauthorized,
+			err:    ErrUnauthorized,
+		},
+		{
+			name:   "Not Asked",
+			method: http.MethodPost,
+			path:   "/tables/main/bets",
+			token:  joined.Token,
+			body:   BetRequest{Amount: 10, Version: 1},
+			status: http.StatusConflict,
+			err:    ErrNotYourTurn,
+		},
+		{
+			name:   "Stale Version",
+			method: http.MethodPost,
+			path:   "/tables/main/bets",
+			token:  joined.Token,
+			body:   BetRequest{Amount: 10},
+			status: http.StatusConflict,
+			err:    ErrVersionConflict,
+		},
+		{
+			name:   "Invalid Move",
+			method: http.MethodPost,
+			path:   "/tables/main/actions",
+			token:  joined.Token,
+			body:   ActionRequest{Move: "split", Version: 1},
+			status: http.StatusBadRequest,
+			err:    ErrInvalidMove,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			var response ErrorResponse
+			status := doRequest(t, tc.method, url+tc.path, tc.token, tc.body, &response)
+			require.Equal(t, tc.status, status)
+			require.Equal(t, tc.err.Error(), response.Error)
+		})
+	}
+}
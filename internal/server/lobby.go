@@ -0,0 +1,319 @@
+package server
+
+import (
+	"course/internal/blackjack"
+	"errors"
+	"time"
+)
+
+// Time a reserved seat is held if TableConfig.ReservationTime is 0
+const defaultReservationTime = time.Minute
+
+var (
+	ErrSeatReserved   = errors.New("seat is reserved for another player")
+	ErrAlreadyWaiting = errors.New("already waiting for a seat")
+)
+
+// TableMetrics
+// Counters of a table since it was created.
+type TableMetrics struct {
+	// Games played, a game starts when all the user seats are taken
+	Games  int `json:"games"`
+	Rounds int `json:"rounds"`
+	// Settled hands of all the seats, the bots included
+	Hands int `json:"hands"`
+	// Players seated, the reconnections included
+	Joins int `json:"joins"`
+	// Coins bet and paid back to the players
+	Wagered int `json:"wagered"`
+	PaidOut int `json:"paidOut"`
+	// Current numbers of the spectator streams, the waiting players and the reserved seats
+	Spectators int `json:"spectators"`
+	Waiting    int `json:"waiting"`
+	Reserved   int `json:"reserved"`
+	// Last change of the table
+	LastActive time.Time `json:"lastActive"`
+}
+
+// A player waiting for a free seat
+type waiter struct {
+	conn connection
+	name string
+	// Reconnect token of the seat the player gets
+	token string
+}
+
+// Reservation
+// A seat held for a player. Only he can take it until the reservation expires.
+type Reservation struct {
+	Table   string    `json:"table"`
+	Name    string    `json:"name"`
+	Seat    int       `json:"seat"`
+	Expires time.Time `json:"expires"`
+}
+
+// Reserve a free seat for the player. Any free seat is reserved if the number is 0
+func (t *table) reserve(name string, number int) (Reservation, error) {
+	if name == "" {
+		return Reservation{}, ErrEmptyName
+	}
+
+	if blackjack.IsReservedName(name) {
+		return Reservation{}, blackjack.ErrReservedName
+	}
+
+	if number < 0 || number > len(t.seats) {
+		return Reservation{}, ErrInvalidSeat
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.removed {
+		return Reservation{}, ErrUnknownTable
+	}
+
+	if t.hasName(name) {
+		return Reservation{}, ErrNameTaken
+	}
+
+	now := time.Now()
+	for _, s := range t.seats {
+		if s.isReservedFor(name, now) {
+			return Reservation{}, ErrNameTaken
+		}
+	}
+
+	if t.playing {
+		return Reservation{}, ErrTableFull
+	}
+
+	free, err := t.freeSeat(name, number)
+	if err != nil {
+		return Reservation{}, err
+	}
+
+	reservationTime := t.cfg.ReservationTime
+	if reservationTime == 0 {
+		reservationTime = defaultReservationTime
+	}
+
+	free.reservedFor = name
+	free.reservedUntil = now.Add(reservationTime)
+	t.changeState()
+
+	return Reservation{
+		Table:   t.cfg.Name,
+		Name:    name,
+		Seat:    free.number,
+		Expires: free.reservedUntil,
+	}, nil
+}
+
+// Free seat for the player, the seat reserved for him first. t.mu is held
+func (t *table) freeSeat(name string, number int) (*seat, error) {
+	now := time.Now()
+
+	for _, s := range t.seats {
+		if s.name == "" && s.isReservedFor(name, now) && (number == 0 || number == s.number) {
+			return s, nil
+		}
+	}
+
+	for _, s := range t.seats {
+		if s.name != "" || (number != 0 && number != s.number) {
+			continue
+		}
+
+		if s.isReserved(now) {
+			if number != 0 {
+				return nil, ErrSeatReserved
+			}
+			continue
+		}
+
+		return s, nil
+	}
+
+	if number != 0 {
+		return nil, ErrSeatTaken
+	}
+
+	return nil, ErrTableFull
+}
+
+// The player is seated or waiting. t.mu is held
+func (t *table) hasName(name string) bool {
+	for _, s := range t.seats {
+		if s.name == name {
+			return true
+		}
+	}
+
+	for _, w := range t.waitlist {
+		if w.name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Put the player on the waitlist of the full table and return his place in it from 1
+func (t *table) wait(c connection, name string) (int, error) {
+	if name == "" {
+		return 0, ErrEmptyName
+	}
+
+	if blackjack.IsReservedName(name) {
+		return 0, blackjack.ErrReservedName
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return 0, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.removed {
+		return 0, ErrUnknownTable
+	}
+
+	for _, w := range t.waitlist {
+		if w.conn == c {
+			return 0, ErrAlreadyWaiting
+		}
+	}
+
+	if t.hasName(name) {
+		return 0, ErrNameTaken
+	}
+
+	t.waitlist = append(t.waitlist, waiter{conn: c, name: name, token: token})
+	t.changeState()
+
+	position := len(t.waitlist)
+	c.write(Message{Type: MessageWaiting, Table: t.cfg.Name, Name: name, Position: position})
+
+	return position, nil
+}
+
+// Give the free seats to the waiting players in turn. t.mu is held
+func (t *table) seatWaiting() {
+	if t.playing {
+		return
+	}
+
+	for len(t.waitlist) > 0 {
+		w := t.waitlist[0]
+
+		free, err := t.freeSeat(w.name, 0)
+		if err != nil {
+			return
+		}
+
+		t.waitlist = t.waitlist[1:]
+		t.seat(free, w.conn, w.name, w.token)
+	}
+}
+
+// The player is gone: his seat stands in the game and is free before it, his place in the waitlist is free
+func (t *table) drop(c connection) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i, w := range t.waitlist {
+		if w.conn == c {
+			t.waitlist = append(t.waitlist[:i:i], t.waitlist[i+1:]...)
+			t.changeState()
+			return
+		}
+	}
+
+	for _, s := range t.seats {
+		if s.disconnect(c, !t.playing) {
+			t.changeState()
+			t.seatWaiting()
+			return
+		}
+	}
+}
+
+// Seat of the connection, nil if the player is waiting or gone
+func (t *table) seatOf(c connection) *seat {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, s := range t.seats {
+		if s.has(c) {
+			return s
+		}
+	}
+
+	return nil
+}
+
+func (t *table) isWaiting(c connection) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, w := range t.waitlist {
+		if w.conn == c {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Remove the table if nobody has used it for the idle timeout
+func (t *table) expire(now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.playing || len(t.waitlist) > 0 || len(t.spectators) > 0 || now.Sub(t.lastActive) < t.cfg.IdleTimeout {
+		return false
+	}
+
+	for _, s := range t.seats {
+		if s.name != "" || s.isReserved(now) {
+			return false
+		}
+	}
+
+	t.removed = true
+
+	return true
+}
+
+// Count the event in the metrics. t.mu is held
+func (t *table) countEvent(event blackjack.Event) {
+	switch event.Type {
+	case blackjack.EventRoundStart:
+		t.metrics.Rounds++
+	case blackjack.EventBet:
+		t.metrics.Wagered += event.Bet
+	case blackjack.EventSettlement:
+		t.metrics.Hands++
+		t.metrics.PaidOut += event.Payout
+	}
+}
+
+// Metrics with the current numbers. t.mu is held
+func (t *table) currentMetrics() TableMetrics {
+	metrics := t.metrics
+	metrics.Spectators = len(t.spectators)
+	metrics.Waiting = len(t.waitlist)
+	metrics.LastActive = t.lastActive
+
+	now := time.Now()
+	for _, s := range t.seats {
+		if s.name == "" && s.isReserved(now) {
+			metrics.Reserved++
+		}
+	}
+
+	return metrics
+}
@@ -0,0 +1,249 @@
+package server
+
+import (
+	"course/internal/blackjack"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func startTestLobby(t *testing.T, tables ...TableConfig) (*Server, string) {
+	s, err := New(tables...)
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	go s.Serve(listener)
+	t.Cleanup(func() {
+		require.NoError(t, s.Close())
+	})
+
+	return s, listener.Addr().String()
+}
+
+func tableInfo(t *testing.T, s *Server, name string) TableInfo {
+	for _, info := range s.Tables() {
+		if info.Name == name {
+			return info
+		}
+	}
+
+	t.Fatalf("no table %q", name)
+	return TableInfo{}
+}
+
+func TestServer_Reservation(t *testing.T) {
+	addr := startTestServer(t, getTestTable())
+
+	host := dialTestClient(t, addr)
+	require.NoError(t, host.Reserve("main", "Sam", 1))
+
+	reserved := receive(t, host)
+	require.Equal(t, MessageReserved, reserved.Type, reserved.Error)
+	require.Equal(t, 1, reserved.Seat)
+	require.NotNil(t, reserved.Expires)
+	require.True(t, reserved.Expires.After(time.Now()))
+
+	// Nobody else takes the reserved seat
+	alex := dialTestClient(t, addr)
+	require.NoError(t, alex.Send(Request{Type: RequestJoin, Table: "main", Name: "Alex", Seat: 1}))
+	require.Equal(t, ErrSeatReserved.Error(), receive(t, alex).Error)
+
+	require.NoError(t, host.Reserve("main", "Sam", 0))
+	require.Equal(t, ErrNameTaken.Error(), receive(t, host).Error)
+
+	require.Equal(t, 2, join(t, alex, "Alex").Seat)
+
+	sam := dialTestClient(t, addr)
+	require.Equal(t, 1, join(t, sam, "Sam").Seat)
+}
+
+func TestServer_ReservationExpires(t *testing.T) {
+	cfg := getTestTable()
+	cfg.ReservationTime = 50 * time.Millisecond
+
+	s, addr := startTestLobby(t, cfg)
+
+	host := dialTestClient(t, addr)
+	require.NoError(t, host.Reserve("main", "Sam", 0))
+	require.Equal(t, MessageReserved, receive(t, host).Type)
+	require.Equal(t, 1, tableInfo(t, s, "main").Metrics.Reserved)
+
+	time.Sleep(100 * time.Millisecond)
+	require.Zero(t, tableInfo(t, s, "main").Metrics.Reserved)
+
+	alex := dialTestClient(t, addr)
+	require.NoError(t, alex.Send(Request{Type: RequestJoin, Table: "main", Name: "Alex", Seat: 1}))
+
+	joined := receive(t, alex)
+	require.Equal(t, MessageJoined, joined.Type, joined.Error)
+	require.Equal(t, 1, joined.Seat)
+}
+
+func TestServer_Waitlist(t *testing.T) {
+	cfg := getTestTable()
+	cfg.Users = 1
+	cfg.Game.PlayersStartingMoney = 1
+
+	s, addr := startTestLobby(t, cfg)
+
+	alex := dialTestClient(t, addr)
+	join(t, alex, "Alex")
+
+	// The table is full, the players wait in turn
+	sam := dialTestClient(t, addr)
+	require.NoError(t, sam.Join("main", "Sam"))
+	require.Equal(t, ErrTableFull.Error(), receive(t, sam).Error)
+
+	require.NoError(t, sam.Wait("main", "Sam"))
+	waiting := receive(t, sam)
+	require.Equal(t, MessageWaiting, waiting.Type, waiting.Error)
+	require.Equal(t, 1, waiting.Position)
+
+	require.NoError(t, sam.Wait("main", "Sam"))
+	require.Equal(t, ErrAlreadyWaiting.Error(), receive(t, sam).Error)
+
+	kim := dialTestClient(t, addr)
+	require.NoError(t, kim.Wait("main", "Kim"))
+	require.Equal(t, 2, receive(t, kim).Position)
+
+	// The second player leaves the waitlist
+	require.NoError(t, kim.Close())
+
+	// The first waiting player takes the seat of the finished game
+	samMessages := autoPlay(sam, 1)
+	alexMessages := autoPlay(alex, 1)
+
+	waitFor(t, alexMessages, "Alex", func(m Message) bool {
+		return m.Type == MessageClosed
+	})
+	waitFor(t, samMessages, "Sam", func(m Message) bool {
+		return m.Type == MessageJoined && m.Seat == 1
+	})
+	waitFor(t, samMessages, "Sam", isEvent(blackjack.EventPrompt, "Sam"))
+
+	metrics := tableInfo(t, s, "main").Metrics
+	require.Equal(t, 2, metrics.Games)
+	require.Equal(t, 2, metrics.Joins)
+	require.Zero(t, metrics.Waiting)
+	require.Positive(t, metrics.Rounds)
+	require.Positive(t, metrics.Hands)
+	require.Positive(t, metrics.Wagered)
+}
+
+func TestServer_HTTPWaitlist(t *testing.T) {
+	cfg := getTestTable()
+	cfg.Users = 1
+	url := startTestHTTPServer(t, cfg)
+
+	var alex JoinResponse
+	require.Equal(t, http.StatusCreated, doRequest(t, http.MethodPost, url+"/tables/main/seats", "", JoinRequest{Name: "Alex"}, &alex))
+
+	var sam JoinResponse
+	require.Equal(t, http.StatusAccepted, doRequest(t, http.MethodPost, url+"/tables/main/seats", "", JoinRequest{Name: "Sam", Wait: true}, &sam))
+	require.Zero(t, sam.Seat)
+	require.Equal(t, 1, sam.Position)
+
+	// The waiting player has no seat to play
+	var response ErrorResponse
+	require.Equal(t, http.StatusUnauthorized, doRequest(t, http.MethodPost, url+"/tables/main/bets", sam.Token, BetRequest{Amount: 10, Version: -1}, &response))
+	require.Equal(t, ErrNotJoined.Error(), response.Error)
+
+	var info TableInfo
+	require.Equal(t, http.StatusOK, doRequest(t, http.MethodGet, url+"/tables/main", "", nil, &info))
+	require.Equal(t, 1, info.Metrics.Waiting)
+
+	// The waiting player leaves, his token is no longer valid
+	require.Equal(t, http.StatusNoContent, doRequest(t, http.MethodDelete, url+"/tables/main/seats", sam.Token, nil, nil))
+	require.Equal(t, http.StatusUnauthorized, doRequest(t, http.MethodDelete, url+"/tables/main/seats", sam.Token, nil, &response))
+
+	require.Equal(t, http.StatusOK, doRequest(t, http.MethodGet, url+"/tables/main", "", nil, &info))
+	require.Zero(t, info.Metrics.Waiting)
+
+	var reservation Reservation
+	require.Equal(t, http.StatusConflict, doRequest(t, http.MethodPost, url+"/tables/main/reservations", "", ReserveRequest{Name: "Sam"}, &response))
+	require.Equal(t, ErrTableFull.Error(), response.Error)
+
+	require.Equal(t, http.StatusCreated, doRequest(t, http.MethodPost, url+"/tables", "", CreateTableRequest{Name: "vip", Users: 2}, &info))
+	require.Equal(t, http.StatusCreated, doRequest(t, http.MethodPost, url+"/tables/vip/reservations", "", ReserveRequest{Name: "Sam", Seat: 2}, &reservation))
+	require.Equal(t, Reservation{Table: "vip", Name: "Sam", Seat: 2, Expires: reservation.Expires}, reservation)
+}
+
+func TestServer_IdleTable(t *testing.T) {
+	cfg := getTestTable()
+	cfg.IdleTimeout = 50 * time.Millisecond
+
+	s, addr := startTestLobby(t, cfg, TableConfig{Name: "vip", Users: 1, Game: cfg.Game})
+
+	// The table with a player stays
+	alex := dialTestClient(t, addr)
+	join(t, alex, "Alex")
+
+	time.Sleep(150 * time.Millisecond)
+	require.Len(t, s.Tables(), 2)
+
+	// Without players the table is removed, the table without the timeout stays
+	require.NoError(t, alex.Close())
+
+	require.Eventually(t, func() bool {
+		return len(s.Tables()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, "vip", s.Tables()[0].Name)
+
+	sam := dialTestClient(t, addr)
+	require.NoError(t, sam.Join("main", "Sam"))
+	require.Equal(t, ErrUnknownTable.Error(), receive(t, sam).Error)
+
+	// The name is free for a new table
+	require.NoError(t, s.AddTable(getTestTable()))
+}
+
+func TestServer_ConcurrentTables(t *testing.T) {
+	const tables = 4
+
+	cfgs := make([]TableConfig, tables)
+	for i := range cfgs {
+		cfgs[i] = getTestTable()
+		cfgs[i].Name = fmt.Sprintf("table-%d", i)
+		cfgs[i].Users = 1
+		cfgs[i].Game.Seed = int64(i + 1)
+	}
+
+	s, addr := startTestLobby(t, cfgs...)
+
+	var wg sync.WaitGroup
+
+	for i := range cfgs {
+		c := dialTestClient(t, addr)
+		require.NoError(t, c.Join(cfgs[i].Name, "Alex"))
+
+		messages := autoPlay(c, 5)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			rounds := 0
+			for message := range messages {
+				if isEvent(blackjack.EventSettlement, "Alex")(message) {
+					rounds++
+				}
+				if rounds == 3 {
+					c.Close()
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	for _, info := range s.Tables() {
+		require.Equal(t, 1, info.Metrics.Games, info.Name)
+		require.GreaterOrEqual(t, info.Metrics.Rounds, 3, info.Name)
+	}
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"course/internal/blackjack"
+	"errors"
+	"time"
+)
+
+// Types of the requests sent by the clients
+const (
+	// Take a user seat at the table
+	RequestJoin = "join"
+	// Answer the bet prompt
+	RequestBet = "bet"
+	// Answer the move prompt with blackjack.MoveHit or blackjack.MoveStand
+	RequestMove = "move"
+	// Leave the seat and close the connection. The seat stands until the player joins again
+	RequestLeave = "leave"
+	// Hold a free seat for a player, only he can take it until the reservation expires
+	RequestReserve = "reserve"
+)
+
+// Types of the messages sent by the server
+const (
+	// The client has taken the seat
+	MessageJoined = "joined"
+	// Event of the game at the table. The prompts are sent only to the player who is asked
+	MessageEvent = "event"
+	// The request was rejected
+	MessageError = "error"
+	// The game at the table is over, the seats are free again
+	MessageClosed = "closed"
+	// State of the table, the first message of an event stream
+	MessageState = "state"
+	// The client waits for a seat of the full table, he gets MessageJoined when a seat is free
+	MessageWaiting = "waiting"
+	// The seat is reserved
+	MessageReserved = "reserved"
+)
+
+var (
+	ErrInvalidRequest = errors.New("invalid request")
+	ErrUnknownRequest = errors.New("unknown request type")
+	ErrUnknownTable   = errors.New("unknown table")
+	ErrEmptyName      = errors.New("name is required")
+	ErrNameTaken      = errors.New("name is taken at the table")
+	ErrInvalidSeat    = errors.New("no such user seat")
+	ErrSeatTaken      = errors.New("seat is taken")
+	ErrTableFull      = errors.New("table is full")
+	ErrAlreadyJoined  = errors.New("already joined a table")
+	ErrNotJoined      = errors.New("not joined a table")
+	ErrNotYourTurn    = errors.New("the request was not asked for")
+	ErrInvalidMove    = errors.New("invalid move")
+	ErrReadOnly       = errors.New("the spectator stream is read-only")
+	ErrInvalidToken   = errors.New("reconnect token is missing or invalid")
+)
+
+// Request
+// A JSON line sent by a client.
+type Request struct {
+	Type  string `json:"type"`
+	Table string `json:"table,omitempty"`
+	// Name of the player, only for RequestJoin and RequestReserve
+	Name string `json:"name,omitempty"`
+	// Number of the user seat from 1, only for RequestJoin and RequestReserve. Any free seat is taken if 0
+	Seat int `json:"seat,omitempty"`
+	// Wait for a seat if the table is full, only for RequestJoin
+	Wait bool `json:"wait,omitempty"`
+	// Reconnect token of MessageJoined, only for RequestJoin of the player who takes his disconnected seat back
+	Token string `json:"token,omitempty"`
+	// Bet in coins, only for RequestBet
+	Amount int `json:"amount,omitempty"`
+	// Move of the player, only for RequestMove
+	Move blackjack.Move `json:"move,omitempty"`
+}
+
+// Message
+// A JSON line sent by the server.
+type Message struct {
+	Type  string `json:"type"`
+	Table string `json:"table,omitempty"`
+	// Name and number of the seat, only for MessageJoined, MessageWaiting and MessageReserved
+	Name string `json:"name,omitempty"`
+	Seat int    `json:"seat,omitempty"`
+	// Place in the waitlist from 1, only for MessageWaiting
+	Position int `json:"position,omitempty"`
+	// Secret of the player to take the seat back after a disconnect, only for MessageJoined
+	Token string `json:"token,omitempty"`
+	// End of the reservation, only for MessageReserved
+	Expires *time.Time       `json:"expires,omitempty"`
+	Event   *blackjack.Event `json:"event,omitempty"`
+	Error   string           `json:"error,omitempty"`
+	// Only for MessageState
+	State *TableState `json:"state,omitempty"`
+}
+
+func errorMessage(err error) Message {
+	return Message{
+		Type:  MessageError,
+		Error: err.Error(),
+	}
+}
@@ -0,0 +1,455 @@
+/*
+  This package hosts blackjack tables for the players connected over TCP or HTTP.
+
+  The TCP client and the server exchange JSON objects, one per line. A client joins a user seat of a table,
+  receives the events of the game and answers the prompts of his seat with bets and moves.
+  A disconnected player bets the smallest bet and stands until he joins the table again by the name
+  and the reconnect token he got when he took the seat.
+
+  The HTTP players poll the state of the table and answer the prompts with the version of the state
+  they have seen, see HTTPHandler.
+
+  The server is the lobby of the tables: every table plays its own game with its own deck and rules
+  in its own goroutine. The players may reserve a seat or wait for one at a full table, an idle table
+  is removed after its IdleTimeout, and every table counts its games, rounds and bets in TableMetrics.
+*/
+
+package server
+
+import (
+	"bufio"
+	"course/internal/blackjack"
+	"encoding/json"
+	"errors"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrDuplicateTable = errors.New("table names are not unique")
+	ErrServerClosed   = errors.New("server is closed")
+)
+
+// Messages queued for a client. A client that does not read them is disconnected
+const clientQueueSize = 256
+
+// Server
+// Accepts the players and plays the games of the tables.
+type Server struct {
+	done chan struct{}
+	wg   sync.WaitGroup
+
+	mu        sync.Mutex
+	tables    map[string]*table
+	listeners []net.Listener
+	clients   map[*client]struct{}
+	// Seats of the HTTP players by their tokens
+	sessions map[string]*session
+	// Origins of the other sites whose pages may open the event streams
+	allowedOrigins map[string]bool
+	closed         bool
+}
+
+// New
+// Server of the tables. More tables can be added with AddTable.
+func New(tables ...TableConfig) (*Server, error) {
+	s := &Server{
+		tables:   make(map[string]*table, len(tables)),
+		done:     make(chan struct{}),
+		clients:  make(map[*client]struct{}),
+		sessions: make(map[string]*session),
+	}
+
+	for _, cfg := range tables {
+		if err := s.AddTable(cfg); err != nil {
+			s.Close()
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// AllowOrigins
+// Let the pages of the origins, like "https://example.com", open the WebSocket event streams.
+// The pages served from the host of the server are always allowed.
+func (s *Server) AllowOrigins(origins ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.allowedOrigins == nil {
+		s.allowedOrigins = make(map[string]bool, len(origins))
+	}
+
+	for _, origin := range origins {
+		s.allowedOrigins[strings.ToLower(strings.TrimSuffix(origin, "/"))] = true
+	}
+}
+
+// AddTable
+// Validate the table and start waiting for its players.
+func (s *Server) AddTable(cfg TableConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrServerClosed
+	}
+
+	if _, ok := s.tables[cfg.Name]; ok {
+		return ErrDuplicateTable
+	}
+
+	t := newTable(cfg)
+	s.tables[cfg.Name] = t
+	s.wg.Add(1)
+
+	go func() {
+		defer s.wg.Done()
+		t.run(s.done, s.removeTable)
+	}()
+
+	return nil
+}
+
+// Tables
+// Summaries of the tables sorted by name.
+func (s *Server) Tables() []TableInfo {
+	s.mu.Lock()
+	tables := make([]*table, 0, len(s.tables))
+	for _, t := range s.tables {
+		tables = append(tables, t)
+	}
+	s.mu.Unlock()
+
+	infos := make([]TableInfo, 0, len(tables))
+	for _, t := range tables {
+		infos = append(infos, t.info())
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+
+	return infos
+}
+
+// Remove the idle table
+func (s *Server) removeTable(t *table) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tables[t.cfg.Name] == t {
+		delete(s.tables, t.cfg.Name)
+	}
+}
+
+func (s *Server) table(name string) (*table, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tables[name]
+	if !ok {
+		return nil, ErrUnknownTable
+	}
+
+	return t, nil
+}
+
+// ListenAndServe
+// Listen on the TCP address and serve the clients until the server is closed.
+func (s *Server) ListenAndServe(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	return s.Serve(listener)
+}
+
+// Serve
+// Accept the clients on the listener until the server is closed. The listener is closed with the server.
+func (s *Server) Serve(listener net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		listener.Close()
+		return nil
+	}
+	s.listeners = append(s.listeners, listener)
+	s.mu.Unlock()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			select {
+			case <-s.done:
+				return nil
+			default:
+				return err
+			}
+		}
+
+		c := newClient(newJSONLines(conn))
+
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			c.close()
+			return nil
+		}
+		s.clients[c] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+
+		go func() {
+			defer s.wg.Done()
+			s.handle(c, conn)
+		}()
+	}
+}
+
+// Close
+// Stop accepting the clients, disconnect them and wait for the games to end.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.done)
+
+	var closeErr error
+
+	for _, listener := range s.listeners {
+		if err := listener.Close(); err != nil && closeErr == nil {
+			closeErr = err
+		}
+	}
+
+	for c := range s.clients {
+		c.close()
+	}
+
+	for token, session := range s.sessions {
+		session.table.drop(session)
+		delete(s.sessions, token)
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+
+	return closeErr
+}
+
+// Read the requests of the client until he leaves or disconnects
+func (s *Server) handle(c *client, conn net.Conn) {
+	var (
+		// Table at which the client is seated or waiting
+		t    *table
+		seat *seat
+	)
+
+	defer func() {
+		if t != nil {
+			t.drop(c)
+		}
+		c.close()
+
+		s.mu.Lock()
+		delete(s.clients, c)
+		s.mu.Unlock()
+	}()
+
+	scanner := bufio.NewScanner(conn)
+
+	for scanner.Scan() {
+		var req Request
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			c.write(errorMessage(ErrInvalidRequest))
+			continue
+		}
+
+		// A waiting client may have been seated, the seat is freed when the game of the table is over
+		if t != nil {
+			seat = t.seatOf(c)
+			if seat == nil && !t.isWaiting(c) {
+				t = nil
+			}
+		}
+
+		var err error
+
+		switch req.Type {
+		case RequestJoin:
+			if seat != nil {
+				err = ErrAlreadyJoined
+			} else if t != nil {
+				err = ErrAlreadyWaiting
+			} else {
+				t, seat, err = s.join(c, req)
+			}
+
+		case RequestReserve:
+			err = s.reserve(c, req)
+
+		case RequestBet:
+			err = answer(c, seat, blackjack.PromptBet, strconv.Itoa(req.Amount), anyVersion)
+
+		case RequestMove:
+			err = answerMove(c, seat, req.Move, anyVersion)
+
+		case RequestLeave:
+			return
+
+		default:
+			err = ErrUnknownRequest
+		}
+
+		if err != nil {
+			c.write(errorMessage(err))
+		}
+	}
+}
+
+// Seat the client, or put him on the waitlist of the full table if he asks for it
+func (s *Server) join(c connection, req Request) (*table, *seat, error) {
+	t, err := s.table(req.Table)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	seat, err := t.join(c, req.Name, req.Seat, req.Token)
+	if errors.Is(err, ErrTableFull) && req.Wait {
+		_, err = t.wait(c, req.Name)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return t, seat, nil
+}
+
+func (s *Server) reserve(c connection, req Request) error {
+	t, err := s.table(req.Table)
+	if err != nil {
+		return err
+	}
+
+	reservation, err := t.reserve(req.Name, req.Seat)
+	if err != nil {
+		return err
+	}
+
+	c.write(Message{
+		Type:    MessageReserved,
+		Table:   reservation.Table,
+		Name:    reservation.Name,
+		Seat:    reservation.Seat,
+		Expires: &reservation.Expires,
+	})
+
+	return nil
+}
+
+func answerMove(c connection, seat *seat, move blackjack.Move, version int64) error {
+	switch move {
+	case blackjack.MoveHit:
+		return answer(c, seat, blackjack.PromptMove, string(blackjack.ActionTakeCard), version)
+	case blackjack.MoveStand:
+		return answer(c, seat, blackjack.PromptMove, string(blackjack.ActionPass), version)
+	default:
+		return ErrInvalidMove
+	}
+}
+
+func answer(c connection, seat *seat, prompt blackjack.PromptKind, line string, version int64) error {
+	if seat == nil {
+		return ErrNotJoined
+	}
+
+	return seat.table.answer(seat, c, prompt, line, version)
+}
+
+// Transport of the messages to a client
+type transport interface {
+	send(message Message) error
+	Close() error
+}
+
+// Messages of a TCP client, JSON objects one per line
+type jsonLines struct {
+	net.Conn
+	encoder *json.Encoder
+}
+
+func newJSONLines(conn net.Conn) *jsonLines {
+	return &jsonLines{
+		Conn:    conn,
+		encoder: json.NewEncoder(conn),
+	}
+}
+
+func (j *jsonLines) send(message Message) error {
+	return j.encoder.Encode(message)
+}
+
+// Connection of a client with the queue of the messages to it
+type client struct {
+	conn      transport
+	queue     chan Message
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newClient(conn transport) *client {
+	c := &client{
+		conn:  conn,
+		queue: make(chan Message, clientQueueSize),
+		done:  make(chan struct{}),
+	}
+
+	go c.writeLoop()
+
+	return c
+}
+
+// Queue the message without blocking the game
+func (c *client) write(message Message) {
+	select {
+	case <-c.done:
+	case c.queue <- message:
+	default:
+		c.close()
+	}
+}
+
+func (c *client) writeLoop() {
+	for {
+		select {
+		case <-c.done:
+			return
+		case message := <-c.queue:
+			if err := c.conn.send(message); err != nil {
+				c.close()
+				return
+			}
+		}
+	}
+}
+
+func (c *client) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.conn.Close()
+	})
+}
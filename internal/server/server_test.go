@@ -0,0 +1,362 @@
+package server
+
+import (
+	"course/internal/blackjack"
+	"github.com/stretchr/testify/require"
+	"net"
+	"testing"
+	"time"
+)
+
+func getTestTable() TableConfig {
+	return TableConfig{
+		Name:  "main",
+		Users: 2,
+		Game: blackjack.Config{
+			PlayersStartingMoney: 1000,
+			BotsNumber:           1,
+			NoDelay:              true,
+		},
+	}
+}
+
+func startTestServer(t *testing.T, tables ...TableConfig) string {
+	s, err := New(tables...)
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	go s.Serve(listener)
+	t.Cleanup(func() {
+		require.NoError(t, s.Close())
+	})
+
+	return listener.Addr().String()
+}
+
+func dialTestClient(t *testing.T, addr string) *Client {
+	c, err := Dial(addr)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		c.Close()
+	})
+
+	return c
+}
+
+func receive(t *testing.T, c *Client) Message {
+	require.NoError(t, c.conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	message, err := c.Receive()
+	require.NoError(t, err)
+
+	return message
+}
+
+func join(t *testing.T, c *Client, name string) Message {
+	require.NoError(t, c.Join("main", name))
+
+	message := receive(t, c)
+	require.Equal(t, MessageJoined, message.Type, message.Error)
+	require.Equal(t, name, message.Name)
+
+	return message
+}
+
+// Answer the prompts with the bet and stand, pass the messages to the channel
+func autoPlay(c *Client, bet int) <-chan Message {
+	messages := make(chan Message, 10000)
+
+	go func() {
+		defer close(messages)
+
+		for {
+			message, err := c.Receive()
+			if err != nil {
+				return
+			}
+
+			if message.Type == MessageEvent && message.Event.Type == blackjack.EventPrompt {
+				if message.Event.Prompt == blackjack.PromptBet {
+					err = c.Bet(bet)
+				} else {
+					err = c.Move(blackjack.MoveStand)
+				}
+
+				if err != nil {
+					return
+				}
+			}
+
+			messages <- message
+		}
+	}()
+
+	return messages
+}
+
+// Read the messages of the player until the condition, checking that the private information stays private
+func waitFor(t *testing.T, messages <-chan Message, name string, condition func(m Message) bool) {
+	timeout := time.After(5 * time.Second)
+
+	for {
+		select {
+		case message, ok := <-messages:
+			require.True(t, ok, "connection closed")
+
+			if event := message.Event; event != nil {
+				if event.Type == blackjack.EventPrompt {
+					require.Equal(t, name, event.Name)
+				}
+
+				if event.Type == blackjack.EventDeal && event.Name == "dealer" {
+					require.Len(t, event.Cards, 1)
+				}
+			}
+
+			if condition(message) {
+				return
+			}
+
+		case <-timeout:
+			t.Fatal("timeout")
+		}
+	}
+}
+
+func isEvent(eventType blackjack.EventType, name string) func(m Message) bool {
+	return func(m Message) bool {
+		return m.Type == MessageEvent && m.Event.Type == eventType && m.Event.Name == name
+	}
+}
+
+func TestServer_Game(t *testing.T) {
+	addr := startTestServer(t, getTestTable())
+
+	alex := dialTestClient(t, addr)
+	alexJoined := join(t, alex, "Alex")
+	require.Equal(t, 1, alexJoined.Seat)
+
+	sam := dialTestClient(t, addr)
+	samJoined := join(t, sam, "Sam")
+	require.Equal(t, 2, samJoined.Seat)
+	require.NotEmpty(t, samJoined.Token)
+	require.NotEqual(t, alexJoined.Token, samJoined.Token)
+
+	alexMessages := autoPlay(alex, 2)
+	samMessages := autoPlay(sam, 2)
+
+	// Both players see the whole round
+	waitFor(t, alexMessages, "Alex", isEvent(blackjack.EventSettlement, "Alex"))
+	waitFor(t, samMessages, "Sam", isEvent(blackjack.EventSettlement, "Alex"))
+
+	// The seat of the disconnected player bets the smallest bet and stands
+	require.NoError(t, sam.Close())
+
+	waitFor(t, alexMessages, "Alex", func(m Message) bool {
+		return isEvent(blackjack.EventBet, "Sam")(m) && m.Event.Bet == autoBet
+	})
+	waitFor(t, alexMessages, "Alex", isEvent(blackjack.EventStand, "Sam"))
+
+	// Only the player with the reconnect token of the seat takes it back
+	impostor := dialTestClient(t, addr)
+	for _, token := range []string{"", alexJoined.Token} {
+		require.NoError(t, impostor.Rejoin("main", "Sam", token))
+		message := receive(t, impostor)
+		require.Equal(t, MessageError, message.Type)
+		require.Equal(t, ErrInvalidToken.Error(), message.Error)
+	}
+
+	samAgain := dialTestClient(t, addr)
+	require.NoError(t, samAgain.Rejoin("main", "Sam", samJoined.Token))
+	rejoined := receive(t, samAgain)
+	require.Equal(t, MessageJoined, rejoined.Type, rejoined.Error)
+	require.Equal(t, 2, rejoined.Seat)
+
+	samAgainMessages := autoPlay(samAgain, 2)
+	waitFor(t, samAgainMessages, "Sam", isEvent(blackjack.EventPrompt, "Sam"))
+	waitFor(t, alexMessages, "Alex", func(m Message) bool {
+		return isEvent(blackjack.EventBet, "Sam")(m) && m.Event.Bet == 2
+	})
+}
+
+func TestServer_GameOver(t *testing.T) {
+	cfg := getTestTable()
+	cfg.Users = 1
+	cfg.Game.PlayersStartingMoney = 1
+
+	addr := startTestServer(t, cfg)
+
+	alex := dialTestClient(t, addr)
+	join(t, alex, "Alex")
+
+	messages := autoPlay(alex, 1)
+	waitFor(t, messages, "Alex", func(m Message) bool {
+		return m.Type == MessageClosed
+	})
+
+	// The seats are free for the next game
+	require.NoError(t, alex.Join("main", "Alex"))
+	waitFor(t, messages, "Alex", func(m Message) bool {
+		return m.Type == MessageJoined
+	})
+}
+
+func TestServer_Errors(t *testing.T) {
+	addr := startTestServer(t, getTestTable())
+
+	alex := dialTestClient(t, addr)
+	join(t, alex, "Alex")
+
+	testCases := []struct {
+		name    string
+		request string
+		err     error
+	}{
+		{
+			name:    "Invalid JSON",
+			request: "hello",
+			err:     ErrInvalidRequest,
+		},
+		{
+			name:    "Unknown Request",
+			request: `{"type": "split"}`,
+			err:     ErrUnknownRequest,
+		},
+		{
+			name:    "Unknown Table",
+			request: `{"type": "join", "table": "vip", "name": "Sam"}`,
+			err:     ErrUnknownTable,
+		},
+		{
+			name:    "Empty Name",
+			request: `{"type": "join", "table": "main"}`,
+			err:     ErrEmptyName,
+		},
+		{
+			name:    "Dealer Name",
+			request: `{"type": "join", "table": "main", "name": "Dealer"}`,
+			err:     blackjack.ErrReservedName,
+		},
+		{
+			name:    "Invalid Seat",
+			request: `{"type": "join", "table": "main", "name": "Sam", "seat": 3}`,
+			err:     ErrInvalidSeat,
+		},
+		{
+			name:    "Seat Taken",
+			request: `{"type": "join", "table": "main", "name": "Sam", "seat": 1}`,
+			err:     ErrSeatTaken,
+		},
+		{
+			name:    "Name Taken",
+			request: `{"type": "join", "table": "main", "name": "Alex"}`,
+			err:     ErrNameTaken,
+		},
+		{
+			name:    "Bet Without Seat",
+			request: `{"type": "bet", "amount": 10}`,
+			err:     ErrNotJoined,
+		},
+		{
+			name:    "Invalid Move",
+			request: `{"type": "move", "move": "split"}`,
+			err:     ErrInvalidMove,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			c := dialTestClient(t, addr)
+
+			_, err := c.conn.Write([]byte(tc.request + "\n"))
+			require.NoError(t, err)
+
+			message := receive(t, c)
+			require.Equal(t, MessageError, message.Type)
+			require.Equal(t, tc.err.Error(), message.Error)
+		})
+	}
+
+	t.Run("Already Joined", func(t *testing.T) {
+		require.NoError(t, alex.Join("main", "Alex"))
+		require.Equal(t, ErrAlreadyJoined.Error(), receive(t, alex).Error)
+	})
+
+	t.Run("Not Asked", func(t *testing.T) {
+		// The game waits for the second player
+		require.NoError(t, alex.Bet(10))
+		require.Equal(t, ErrNotYourTurn.Error(), receive(t, alex).Error)
+	})
+}
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		name   string
+		tables func() []TableConfig
+		err    error
+	}{
+		{
+			name:   "Ok",
+			tables: func() []TableConfig { return []TableConfig{getTestTable()} },
+			err:    nil,
+		},
+		{
+			name:   "No Tables",
+			tables: func() []TableConfig { return nil },
+			err:    nil,
+		},
+		{
+			name: "Duplicate Tables",
+			tables: func() []TableConfig {
+				return []TableConfig{getTestTable(), getTestTable()}
+			},
+			err: ErrDuplicateTable,
+		},
+		{
+			name: "Empty Table Name",
+			tables: func() []TableConfig {
+				cfg := getTestTable()
+				cfg.Name = ""
+				return []TableConfig{cfg}
+			},
+			err: ErrEmptyTableName,
+		},
+		{
+			name: "No User Seats",
+			tables: func() []TableConfig {
+				cfg := getTestTable()
+				cfg.Users = 0
+				return []TableConfig{cfg}
+			},
+			err: ErrInvalidUsers,
+		},
+		{
+			name: "Invalid Game",
+			tables: func() []TableConfig {
+				cfg := getTestTable()
+				cfg.Game.PlayersStartingMoney = 0
+				return []TableConfig{cfg}
+			},
+			err: blackjack.ErrInvalidPlayersStartingMoney,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := New(tc.tables()...)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.NoError(t, s.Close())
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package server
+
+import (
+	"course/internal/blackjack"
+	"course/internal/deck"
+)
+
+// TableState
+// Public state of a table, built from the game events. The hidden dealer cards are not known to it
+// until the dealer reveals them. Version grows with every change of the table.
+type TableState struct {
+	Name    string `json:"name"`
+	Version int64  `json:"version"`
+	// The game is played, otherwise the table waits for the players
+	Playing bool `json:"playing"`
+	Round   int  `json:"round"`
+	// Seats in the dealing order. Before the game only the user seats are known
+	Seats  []SeatState `json:"seats"`
+	Dealer DealerState `json:"dealer"`
+	// Name of the player who is asked for a bet or a move, empty if nobody is asked
+	Turn   string               `json:"turn,omitempty"`
+	Prompt blackjack.PromptKind `json:"prompt,omitempty"`
+}
+
+type SeatState struct {
+	// Name of the player, empty for a free seat
+	Name   string `json:"name"`
+	Bot    bool   `json:"bot"`
+	Money  int    `json:"money"`
+	Bet    int    `json:"bet"`
+	Cards  []Card `json:"cards"`
+	Points int    `json:"points"`
+	// The player has finished the round
+	Standing bool                  `json:"standing"`
+	Result   blackjack.RoundResult `json:"result,omitempty"`
+	Payout   int                   `json:"payout"`
+}
+
+type DealerState struct {
+	// Opened cards of the dealer
+	Cards  []Card `json:"cards"`
+	Points int    `json:"points"`
+	// Number of the cards the dealer has not revealed
+	HiddenCards int `json:"hiddenCards"`
+}
+
+type Card struct {
+	Suit deck.CardSuit `json:"suit"`
+	Rank deck.Rank     `json:"value"`
+}
+
+func newCards(cards []*deck.Card) []Card {
+	result := make([]Card, 0, len(cards))
+
+	for _, card := range cards {
+		result = append(result, Card{
+			Suit: card.Suit(),
+			Rank: card.Rank(),
+		})
+	}
+
+	return result
+}
+
+// Apply the game event to the state
+func (s *TableState) apply(event blackjack.Event, rules blackjack.Rules) {
+	if event.Type != blackjack.EventPrompt {
+		s.Turn, s.Prompt = "", ""
+	}
+
+	switch event.Type {
+	case blackjack.EventRoundStart:
+		s.Playing = true
+		s.Round = event.Round
+		s.Dealer = DealerState{Cards: []Card{}}
+		s.Seats = make([]SeatState, 0, len(event.Seats))
+
+		for _, seat := range event.Seats {
+			s.Seats = append(s.Seats, SeatState{
+				Name:  seat.Name,
+				Bot:   seat.Bot,
+				Money: seat.Money,
+				Cards: []Card{},
+			})
+		}
+
+	case blackjack.EventPrompt:
+		s.Turn, s.Prompt = event.Name, event.Prompt
+
+	case blackjack.EventDealerReveal:
+		s.Dealer.Cards = append(s.Dealer.Cards, newCards(event.Cards)...)
+		s.Dealer.Points = event.Points
+		s.Dealer.HiddenCards = 0
+
+	case blackjack.EventDealerHit, blackjack.EventDealerStand:
+		s.Dealer.Cards = append(s.Dealer.Cards, newCards(event.Cards)...)
+		s.Dealer.Points = event.Points
+
+	case blackjack.EventDeal:
+		// The dealer is the only participant of the deal without a seat
+		if s.seat(event.Name) == nil {
+			s.Dealer.Cards = append(s.Dealer.Cards, newCards(event.Cards)...)
+			s.Dealer.Points = event.Points
+
+			// The hole card is dealt closed
+			if !rules.NoHoleCard {
+				s.Dealer.HiddenCards = 1
+			}
+			return
+		}
+
+		s.applySeatEvent(event)
+
+	default:
+		s.applySeatEvent(event)
+	}
+}
+
+func (s *TableState) applySeatEvent(event blackjack.Event) {
+	seat := s.seat(event.Name)
+	if seat == nil {
+		return
+	}
+
+	switch event.Type {
+	case blackjack.EventBet:
+		seat.Bet = event.Bet
+		seat.Money = event.Money
+
+	case blackjack.EventDeal, blackjack.EventHit:
+		seat.Cards = append(seat.Cards, newCards(event.Cards)...)
+		seat.Points = event.Points
+
+	case blackjack.EventStand:
+		seat.Standing = true
+
+	case blackjack.EventSettlement:
+		seat.Standing = true
+		seat.Result = event.Result
+		seat.Payout = event.Payout
+		seat.Money = event.Money
+	}
+}
+
+func (s *TableState) seat(name string) *SeatState {
+	for i := range s.Seats {
+		if s.Seats[i].Name == name {
+			return &s.Seats[i]
+		}
+	}
+
+	return nil
+}
+
+// Copy of the state that does not share the slices
+func (s *TableState) copy() TableState {
+	state := *s
+	state.Seats = make([]SeatState, len(s.Seats))
+
+	for i, seat := range s.Seats {
+		seat.Cards = append([]Card{}, seat.Cards...)
+		state.Seats[i] = seat
+	}
+
+	state.Dealer.Cards = append([]Card{}, s.Dealer.Cards...)
+
+	return state
+}
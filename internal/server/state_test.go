@@ -0,0 +1,89 @@
+package server
+
+import (
+	"course/internal/blackjack"
+	"course/internal/deck"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestTableState_Apply(t *testing.T) {
+	ace := deck.MustNewCard(deck.Spade, deck.Ace)
+	king := deck.MustNewCard(deck.Heart, deck.King)
+
+	events := []blackjack.Event{
+		{Type: blackjack.EventRoundStart, Round: 1, Seats: []blackjack.Seat{{Name: "Alex", Money: 100}}},
+		{Type: blackjack.EventPrompt, Name: "Alex", Prompt: blackjack.PromptBet},
+		{Type: blackjack.EventBet, Name: "Alex", Bet: 10, Money: 90},
+		{Type: blackjack.EventDeal, Name: "Alex", Cards: []*deck.Card{ace, king}, Points: 21},
+		// The dealer event has only the open card
+		{Type: blackjack.EventDeal, Name: "dealer", Cards: []*deck.Card{king}, Points: 10},
+	}
+
+	testCases := []struct {
+		name  string
+		rules blackjack.Rules
+		after []blackjack.Event
+		check func(t *testing.T, state TableState)
+	}{
+		{
+			name: "Hole Card",
+			check: func(t *testing.T, state TableState) {
+				require.Equal(t, 1, state.Dealer.HiddenCards)
+				require.Equal(t, []Card{{Suit: deck.Heart, Rank: deck.King}}, state.Dealer.Cards)
+				require.Equal(t, SeatState{
+					Name:   "Alex",
+					Money:  90,
+					Bet:    10,
+					Cards:  []Card{{Suit: deck.Spade, Rank: deck.Ace}, {Suit: deck.Heart, Rank: deck.King}},
+					Points: 21,
+				}, state.Seats[0])
+				require.Empty(t, state.Turn)
+			},
+		},
+		{
+			name:  "No Hole Card",
+			rules: blackjack.Rules{NoHoleCard: true},
+			check: func(t *testing.T, state TableState) {
+				require.Zero(t, state.Dealer.HiddenCards)
+			},
+		},
+		{
+			name: "Reveal",
+			after: []blackjack.Event{
+				{Type: blackjack.EventDealerReveal, Name: "dealer", Cards: []*deck.Card{ace}, Points: 21},
+				{Type: blackjack.EventSettlement, Name: "Alex", Result: blackjack.ResultDraw, Payout: 10, Money: 100},
+			},
+			check: func(t *testing.T, state TableState) {
+				require.Zero(t, state.Dealer.HiddenCards)
+				require.Len(t, state.Dealer.Cards, 2)
+				require.Equal(t, 21, state.Dealer.Points)
+				require.Equal(t, blackjack.ResultDraw, state.Seats[0].Result)
+				require.Equal(t, 100, state.Seats[0].Money)
+				require.True(t, state.Seats[0].Standing)
+			},
+		},
+		{
+			name:  "Prompt",
+			after: []blackjack.Event{{Type: blackjack.EventPrompt, Name: "Alex", Prompt: blackjack.PromptMove}},
+			check: func(t *testing.T, state TableState) {
+				require.Equal(t, "Alex", state.Turn)
+				require.Equal(t, blackjack.PromptMove, state.Prompt)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			var state TableState
+
+			for _, event := range append(append([]blackjack.Event{}, events...), tc.after...) {
+				state.apply(event, tc.rules)
+			}
+
+			tc.check(t, state)
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"course/internal/blackjack"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Stream the events of the table over a WebSocket until the client closes it.
+// A browser page opens the stream only if it is served from the host of the server or from an allowed origin.
+//
+// The first message is the state of the table, then the messages of the TCP protocol follow.
+// Without a token the stream is a read-only spectator feed with the public events. With the token
+// of a seat, in the Authorization header or in the token query parameter for the browsers, the stream
+// is the private channel of the player: it also has his prompts, and the player sends the bet, move
+// and leave requests of the TCP protocol over it.
+func (s *Server) streamEvents(w http.ResponseWriter, r *http.Request, t *table) {
+	s.mu.Lock()
+	err := checkOrigin(r, s.allowedOrigins)
+	s.mu.Unlock()
+	if err != nil {
+		writeError(w, http.StatusForbidden, err)
+		return
+	}
+
+	var player *session
+
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		token = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	}
+
+	if token != "" {
+		if player, _, err = s.sessionByToken(token, t); err != nil {
+			writeAPIError(w, err)
+			return
+		}
+	}
+
+	ws, err := upgradeWebSocket(w, r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	c := newClient(ws)
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		c.close()
+		return
+	}
+	s.clients[c] = struct{}{}
+	s.mu.Unlock()
+
+	defer func() {
+		t.unsubscribe(c, player)
+		c.close()
+
+		s.mu.Lock()
+		delete(s.clients, c)
+		s.mu.Unlock()
+	}()
+
+	if err = t.subscribe(c, player); err != nil {
+		c.write(errorMessage(err))
+		return
+	}
+
+	for {
+		data, err := ws.readMessage()
+		if err != nil {
+			return
+		}
+
+		if player == nil {
+			c.write(errorMessage(ErrReadOnly))
+			continue
+		}
+
+		var req Request
+		if err = json.Unmarshal(data, &req); err != nil {
+			c.write(errorMessage(ErrInvalidRequest))
+			continue
+		}
+
+		// A waiting player may have been seated
+		seat := t.seatOf(player)
+
+		switch req.Type {
+		case RequestJoin:
+			err = ErrAlreadyJoined
+
+		case RequestBet:
+			err = answer(player, seat, blackjack.PromptBet, strconv.Itoa(req.Amount), anyVersion)
+
+		case RequestMove:
+			err = answerMove(player, seat, req.Move, anyVersion)
+
+		case RequestLeave:
+			s.leave(player)
+			return
+
+		default:
+			err = ErrUnknownRequest
+		}
+
+		if err != nil {
+			c.write(errorMessage(err))
+		}
+	}
+}
@@ -0,0 +1,692 @@
+package server
+
+import (
+	"course/internal/blackjack"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Bet of a disconnected player, the smallest one
+const autoBet = 1
+
+// Answers that are not checked against the table version
+const anyVersion = -1
+
+var (
+	ErrEmptyTableName  = errors.New("table name is required")
+	ErrInvalidUsers    = errors.New("table has no user seats")
+	ErrVersionConflict = errors.New("table state has changed")
+	ErrNegativeTimeout = errors.New("table timeout is negative")
+)
+
+// Longest pause between the checks of the expired reservations and the idle table
+const tickInterval = time.Second
+
+// TableConfig
+// A table of the server. The clients take the user seats, the bots sit after them.
+type TableConfig struct {
+	Name string
+	// Number of the user seats. The game starts when all of them are taken
+	Users int
+	// Game of the table. The seats are made of Users and BotsNumber, Username, BotsOnly and Seats are not used.
+	// The game text is written to Output, it is discarded if nil
+	Game blackjack.Config
+	// The table is removed when nobody has used it for this time. It is never removed if 0
+	IdleTimeout time.Duration
+	// Time a reserved seat is held, a minute if 0
+	ReservationTime time.Duration
+}
+
+func (cfg TableConfig) Validate() error {
+	if cfg.Name == "" {
+		return ErrEmptyTableName
+	}
+
+	if cfg.Users < 1 {
+		return ErrInvalidUsers
+	}
+
+	if cfg.IdleTimeout < 0 || cfg.ReservationTime < 0 {
+		return ErrNegativeTimeout
+	}
+
+	seats := make([]blackjack.SeatConfig, cfg.Users)
+	for i := range seats {
+		seats[i].Name = fmt.Sprintf("seat %d", i+1)
+	}
+
+	return cfg.game(seats).Validate()
+}
+
+// Game config with the user seats
+func (cfg TableConfig) game(users []blackjack.SeatConfig) blackjack.Config {
+	game := cfg.Game
+	game.Seats = append([]blackjack.SeatConfig{}, users...)
+
+	for i := 0; i < cfg.Game.BotsNumber; i++ {
+		game.Seats = append(game.Seats, blackjack.SeatConfig{Bot: true})
+	}
+
+	// Nobody answers the questions between the rounds at a server table
+	game.Input = continueInput{}
+	game.CountQuiz = false
+	game.Trainer = false
+
+	if game.Output == nil {
+		game.Output = io.Discard
+	}
+
+	return game
+}
+
+// Input of the pauses between the rounds, the next round starts at once
+type continueInput struct{}
+
+func (continueInput) Input() string {
+	return ""
+}
+
+// TableInfo
+// Summary of a table for the list of the tables.
+type TableInfo struct {
+	Name    string       `json:"name"`
+	Users   int          `json:"users"`
+	Bots    int          `json:"bots"`
+	Free    int          `json:"freeSeats"`
+	Playing bool         `json:"playing"`
+	Version int64        `json:"version"`
+	Metrics TableMetrics `json:"metrics"`
+}
+
+// Connection of a player to his seat: a TCP client or an HTTP session
+type connection interface {
+	// Send the message without blocking the game
+	write(message Message)
+}
+
+// A table with its game. The game is played when all the user seats are taken,
+// after the game is over the seats are free again
+type table struct {
+	cfg TableConfig
+	// Signals that all the seats are taken
+	start chan struct{}
+
+	mu      sync.Mutex
+	seats   []*seat
+	playing bool
+	state   TableState
+	// Closed and replaced when the version of the state grows
+	changed chan struct{}
+	// Streams of the spectators, they get every event except the prompts
+	spectators map[*client]struct{}
+	// Players waiting for a seat in turn
+	waitlist []waiter
+	metrics  TableMetrics
+	// Time of the last change of the state
+	lastActive time.Time
+	// The idle table was removed from the server
+	removed bool
+}
+
+func newTable(cfg TableConfig) *table {
+	t := &table{
+		cfg:        cfg,
+		start:      make(chan struct{}, 1),
+		state:      TableState{Name: cfg.Name},
+		changed:    make(chan struct{}),
+		spectators: make(map[*client]struct{}),
+		lastActive: time.Now(),
+	}
+
+	for i := 0; i < cfg.Users; i++ {
+		t.seats = append(t.seats, &seat{
+			number:  i + 1,
+			table:   t,
+			answers: make(chan string, 1),
+		})
+	}
+
+	return t
+}
+
+// Play the games until the server is closed or the table is removed as idle
+func (t *table) run(done <-chan struct{}, remove func(t *table)) {
+	ticker := time.NewTicker(t.tickInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+
+		case now := <-ticker.C:
+			if t.cfg.IdleTimeout > 0 && t.expire(now) {
+				remove(t)
+				return
+			}
+
+			// The seats of the expired reservations are given to the waiting players
+			t.mu.Lock()
+			t.seatWaiting()
+			t.mu.Unlock()
+			continue
+
+		case <-t.start:
+		}
+
+		if err := t.play(); err != nil {
+			log.Printf("table %s: %v", t.cfg.Name, err)
+		}
+	}
+}
+
+// Pause between the checks, short enough for the idle timeout and the reservations
+func (t *table) tickInterval() time.Duration {
+	interval := tickInterval
+
+	for _, timeout := range []time.Duration{t.cfg.IdleTimeout, t.cfg.ReservationTime} {
+		if timeout > 0 && timeout/2 < interval {
+			interval = timeout / 2
+		}
+	}
+
+	return interval
+}
+
+func (t *table) play() error {
+	t.mu.Lock()
+	users := make([]blackjack.SeatConfig, 0, len(t.seats))
+	for _, s := range t.seats {
+		// A player has left before the game started
+		if s.name == "" {
+			t.mu.Unlock()
+			return nil
+		}
+		users = append(users, blackjack.SeatConfig{Name: s.name, Input: s})
+	}
+	t.playing = true
+	t.metrics.Games++
+	t.mu.Unlock()
+
+	cfg := t.cfg.game(users)
+	cfg.Observers = append(append([]blackjack.Observer{}, cfg.Observers...), t)
+
+	bj, err := blackjack.NewBlackjack(cfg)
+	if err == nil {
+		err = bj.Run()
+		if closeErr := bj.Close(); err == nil {
+			err = closeErr
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.playing = false
+	t.state = TableState{Name: t.cfg.Name, Version: t.state.Version}
+	t.changeState()
+
+	closed := Message{Type: MessageClosed, Table: t.cfg.Name}
+
+	for _, s := range t.seats {
+		if conn := s.reset(); conn != nil {
+			conn.write(closed)
+		}
+	}
+
+	for c := range t.spectators {
+		c.write(closed)
+	}
+
+	t.seatWaiting()
+
+	return err
+}
+
+// Seat the player. The player of a disconnected seat takes it back by the name and the reconnect token
+// of the seat while the game is played
+func (t *table) join(c connection, name string, number int, token string) (*seat, error) {
+	if name == "" {
+		return nil, ErrEmptyName
+	}
+
+	if blackjack.IsReservedName(name) {
+		return nil, blackjack.ErrReservedName
+	}
+
+	if number < 0 || number > len(t.seats) {
+		return nil, ErrInvalidSeat
+	}
+
+	// Reconnect token of the seat if the player takes a free one
+	seatToken, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.removed {
+		return nil, ErrUnknownTable
+	}
+
+	for _, s := range t.seats {
+		if s.name != name {
+			continue
+		}
+
+		if number != 0 && number != s.number {
+			return nil, ErrNameTaken
+		}
+
+		if err = s.reconnect(c, token); err != nil {
+			return nil, err
+		}
+
+		t.metrics.Joins++
+		t.changeState()
+		c.write(s.joinedMessage())
+
+		return s, nil
+	}
+
+	if t.hasName(name) {
+		return nil, ErrNameTaken
+	}
+
+	if t.playing {
+		return nil, ErrTableFull
+	}
+
+	free, err := t.freeSeat(name, number)
+	if err != nil {
+		return nil, err
+	}
+
+	t.seat(free, c, name, seatToken)
+
+	return free, nil
+}
+
+// Give the free seat to the player and start the game if all the seats are taken. t.mu is held
+func (t *table) seat(free *seat, c connection, name string, token string) {
+	free.take(c, name, token)
+	free.reservedFor = ""
+	t.metrics.Joins++
+	t.changeState()
+	// The player learns the seat before the events of the game
+	c.write(free.joinedMessage())
+
+	for _, s := range t.seats {
+		if s.name == "" {
+			return
+		}
+	}
+
+	select {
+	case t.start <- struct{}{}:
+	default:
+	}
+}
+
+// Pass the answer of the player to the game. The answer is rejected if the version is not the current one,
+// unless it is anyVersion
+func (t *table) answer(s *seat, c connection, prompt blackjack.PromptKind, line string, version int64) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if version != anyVersion && version != t.state.Version {
+		return ErrVersionConflict
+	}
+
+	if err := s.answer(c, prompt, line); err != nil {
+		return err
+	}
+
+	t.state.Turn, t.state.Prompt = "", ""
+	t.changeState()
+
+	return nil
+}
+
+// Answer of a disconnected player. When every player is gone the game is left
+func (t *table) autoAnswer(prompt blackjack.PromptKind) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	connected := false
+	for _, s := range t.seats {
+		connected = connected || s.isConnected()
+	}
+
+	if !connected {
+		return string(blackjack.ActionExit)
+	}
+
+	if prompt == blackjack.PromptBet {
+		return strconv.Itoa(autoBet)
+	}
+
+	return string(blackjack.ActionPass)
+}
+
+// OnEvent
+// Apply the event to the state and send it to the players. A prompt is sent only to the player who is asked.
+func (t *table) OnEvent(event blackjack.Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.state.apply(event, t.cfg.Game.Rules)
+	t.countEvent(event)
+	t.changeState()
+
+	message := Message{
+		Type:  MessageEvent,
+		Table: t.cfg.Name,
+		Event: &event,
+	}
+
+	for _, s := range t.seats {
+		if event.Type == blackjack.EventPrompt && s.name != event.Name {
+			continue
+		}
+
+		if conn := s.notify(event); conn != nil {
+			conn.write(message)
+		}
+	}
+
+	if event.Type == blackjack.EventPrompt {
+		return
+	}
+
+	for c := range t.spectators {
+		c.write(message)
+	}
+}
+
+// Start the event stream with the state of the table. The stream of a player gets the messages of his seat,
+// the stream of a spectator gets the public events
+func (t *table) subscribe(c *client, player *session) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.removed {
+		return ErrUnknownTable
+	}
+
+	state := t.stateLocked()
+	c.write(Message{Type: MessageState, Table: t.cfg.Name, State: &state})
+
+	if player != nil {
+		player.attach(c)
+	} else {
+		t.spectators[c] = struct{}{}
+	}
+
+	return nil
+}
+
+func (t *table) unsubscribe(c *client, player *session) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if player != nil {
+		player.detach(c)
+	} else {
+		delete(t.spectators, c)
+	}
+}
+
+// Increase the version of the state and wake up the waiting readers
+func (t *table) changeState() {
+	t.lastActive = time.Now()
+	t.state.Version++
+	close(t.changed)
+	t.changed = make(chan struct{})
+}
+
+// Snapshot of the state and the channel closed when it changes
+func (t *table) snapshot() (TableState, <-chan struct{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.stateLocked(), t.changed
+}
+
+// State of the table with the seats of the players before the game. t.mu is held
+func (t *table) stateLocked() TableState {
+	state := t.state.copy()
+
+	if !t.playing {
+		state.Seats = make([]SeatState, 0, len(t.seats))
+
+		for _, s := range t.seats {
+			state.Seats = append(state.Seats, SeatState{
+				Name:  s.name,
+				Money: t.cfg.Game.PlayersStartingMoney,
+				Cards: []Card{},
+			})
+		}
+	}
+
+	return state
+}
+
+func (t *table) info() TableInfo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	free := 0
+	for _, s := range t.seats {
+		if s.name == "" {
+			free++
+		}
+	}
+
+	return TableInfo{
+		Name:    t.cfg.Name,
+		Users:   t.cfg.Users,
+		Bots:    t.cfg.Game.BotsNumber,
+		Free:    free,
+		Playing: t.playing,
+		Version: t.state.Version,
+		Metrics: t.currentMetrics(),
+	}
+}
+
+// A user seat of the table. It is the input of the player in the game
+type seat struct {
+	number int
+	table  *table
+	// Answers of the player to the prompt
+	answers chan string
+
+	mu   sync.Mutex
+	name string
+	// Reconnect token of the player, the disconnected seat is given back only with it
+	token string
+	// nil if the player has disconnected
+	conn connection
+	// Closed when the player disconnects
+	gone chan struct{}
+	// What the player is asked for, empty if nothing
+	prompt blackjack.PromptKind
+
+	// The free seat is held for the player until the time. Guarded by the table mutex
+	reservedFor   string
+	reservedUntil time.Time
+}
+
+func (s *seat) isReserved(now time.Time) bool {
+	return s.reservedFor != "" && now.Before(s.reservedUntil)
+}
+
+func (s *seat) isReservedFor(name string, now time.Time) bool {
+	return s.reservedFor == name && s.isReserved(now)
+}
+
+func (s *seat) joinedMessage() Message {
+	return Message{
+		Type:  MessageJoined,
+		Table: s.table.cfg.Name,
+		Name:  s.name,
+		Seat:  s.number,
+		Token: s.token,
+	}
+}
+
+func (s *seat) take(c connection, name string, token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.name = name
+	s.token = token
+	s.conn = c
+	s.gone = make(chan struct{})
+}
+
+// Give the disconnected seat back to its player, who proves it with the reconnect token of the seat
+func (s *seat) reconnect(c connection, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn != nil {
+		return ErrNameTaken
+	}
+
+	if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+		return ErrInvalidToken
+	}
+
+	s.conn = c
+	s.gone = make(chan struct{})
+
+	return nil
+}
+
+func (s *seat) reconnectToken() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.token
+}
+
+// Disconnect the player, false if the seat has another player
+func (s *seat) disconnect(c connection, free bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn != c {
+		return false
+	}
+
+	s.conn = nil
+	close(s.gone)
+
+	if free {
+		s.name = ""
+		s.token = ""
+	}
+
+	return true
+}
+
+// Free the seat after the game and return its connection
+func (s *seat) reset() connection {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conn := s.conn
+	s.name = ""
+	s.token = ""
+	s.conn = nil
+	s.prompt = ""
+
+	select {
+	case <-s.answers:
+	default:
+	}
+
+	return conn
+}
+
+func (s *seat) has(c connection) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.conn == c
+}
+
+func (s *seat) isConnected() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.conn != nil
+}
+
+// Remember the prompt of the player and return the connection to send the event to
+func (s *seat) notify(event blackjack.Event) connection {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if event.Type == blackjack.EventPrompt {
+		s.prompt = event.Prompt
+
+		// An answer that came too late for the previous prompt
+		select {
+		case <-s.answers:
+		default:
+		}
+	}
+
+	return s.conn
+}
+
+// Pass the answer of the player to the game if it was asked for
+func (s *seat) answer(c connection, prompt blackjack.PromptKind, line string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn != c {
+		return ErrNotJoined
+	}
+
+	if s.prompt != prompt {
+		return ErrNotYourTurn
+	}
+
+	s.prompt = ""
+	s.answers <- line
+
+	return nil
+}
+
+// Input
+// Answer of the player to the prompt. A disconnected player bets the smallest bet and stands.
+func (s *seat) Input() string {
+	s.mu.Lock()
+	prompt, gone, connected := s.prompt, s.gone, s.conn != nil
+	s.mu.Unlock()
+
+	if connected {
+		select {
+		case line := <-s.answers:
+			return line
+		case <-gone:
+		}
+	}
+
+	s.mu.Lock()
+	s.prompt = ""
+	s.mu.Unlock()
+
+	return s.table.autoAnswer(prompt)
+}
@@ -0,0 +1,353 @@
+package server
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// GUID of the handshake, RFC 6455 section 1.3
+const webSocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// Largest message accepted from a client
+const maxWebSocketMessage = 64 << 10
+
+// Time to send a close frame to a client that does not read
+const webSocketCloseTimeout = time.Second
+
+// Opcodes of the frames
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+// Status codes of the close frames
+const (
+	closeNormal         = 1000
+	closeGoingAway      = 1001
+	closeProtocolError  = 1002
+	closeUnsupported    = 1003
+	closeInvalidPayload = 1007
+	closeTooBig         = 1009
+)
+
+var (
+	ErrNotWebSocket          = errors.New("not a websocket handshake")
+	ErrWebSocketProtocol     = errors.New("websocket protocol error")
+	ErrWebSocketTooBig       = errors.New("websocket message is too big")
+	ErrWebSocketUnsupported  = errors.New("websocket message is not text")
+	ErrWebSocketInvalidUTF8  = errors.New("websocket text is not valid UTF-8")
+	ErrOriginNotAllowed      = errors.New("websocket origin is not allowed")
+	errWebSocketClosedByPeer = errors.New("websocket closed by the peer")
+)
+
+// Server side of a WebSocket connection. The messages are JSON objects in text frames
+type webSocket struct {
+	conn   net.Conn
+	reader *bufio.Reader
+
+	// Frames are written by the client queue and by the reader (pongs and close)
+	writeMu sync.Mutex
+	closed  bool
+}
+
+// Complete the opening handshake and take the connection from the HTTP server
+func upgradeWebSocket(w http.ResponseWriter, r *http.Request) (*webSocket, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+
+	if r.Method != http.MethodGet ||
+		!headerContains(r.Header, "Connection", "upgrade") ||
+		!headerContains(r.Header, "Upgrade", "websocket") ||
+		key == "" {
+		return nil, ErrNotWebSocket
+	}
+
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		return nil, ErrNotWebSocket
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, errors.New("the connection cannot be taken over")
+	}
+
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + webSocketAccept(key) + "\r\n\r\n"
+
+	if _, err = rw.WriteString(response); err == nil {
+		err = rw.Flush()
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &webSocket{conn: conn, reader: rw.Reader}, nil
+}
+
+// The browser page that opens the WebSocket is served from the host of the request or from an allowed origin.
+// The clients that are not browsers send no Origin and are allowed
+func checkOrigin(r *http.Request, allowed map[string]bool) error {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+
+	u, err := url.Parse(origin)
+	if err == nil && u.Host != "" && strings.EqualFold(u.Host, r.Host) {
+		return nil
+	}
+
+	if allowed[strings.ToLower(origin)] {
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", ErrOriginNotAllowed, origin)
+}
+
+// Value of Sec-WebSocket-Accept for the key of the client
+func webSocketAccept(key string) string {
+	hash := sha1.Sum([]byte(key + webSocketGUID))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// The comma separated header has the token, case insensitive
+func headerContains(header http.Header, name string, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (ws *webSocket) send(message Message) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	return ws.writeFrame(opText, data)
+}
+
+// Close
+// Send the close frame and close the connection. The frame may wait for a write of the client queue,
+// so it is sent in the background and the game is not blocked.
+func (ws *webSocket) Close() error {
+	ws.conn.SetWriteDeadline(time.Now().Add(webSocketCloseTimeout))
+	go ws.closeWith(closeGoingAway)
+
+	return nil
+}
+
+func (ws *webSocket) closeWith(code uint16) error {
+	payload := make([]byte, 2)
+	binary.BigEndian.PutUint16(payload, code)
+
+	ws.conn.SetWriteDeadline(time.Now().Add(webSocketCloseTimeout))
+	ws.writeFrame(opClose, payload)
+
+	ws.writeMu.Lock()
+	ws.closed = true
+	ws.writeMu.Unlock()
+
+	return ws.conn.Close()
+}
+
+// Write a single unmasked frame, the server never masks
+func (ws *webSocket) writeFrame(opcode byte, payload []byte) error {
+	ws.writeMu.Lock()
+	defer ws.writeMu.Unlock()
+
+	if ws.closed {
+		return net.ErrClosed
+	}
+
+	header := make([]byte, 2, 10)
+	header[0] = 0x80 | opcode
+
+	switch length := len(payload); {
+	case length <= 125:
+		header[1] = byte(length)
+	case length <= 0xFFFF:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(length))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(length))
+	}
+
+	if _, err := ws.conn.Write(header); err != nil {
+		return err
+	}
+
+	_, err := ws.conn.Write(payload)
+
+	return err
+}
+
+// Read the next text message. The pings are answered, a close frame is answered and ends the reading
+func (ws *webSocket) readMessage() ([]byte, error) {
+	var (
+		message    []byte
+		fragmented bool
+	)
+
+	for {
+		fin, opcode, payload, err := ws.readFrame()
+		if err != nil {
+			return nil, ws.fail(err)
+		}
+
+		switch opcode {
+		case opPing:
+			if err = ws.writeFrame(opPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+
+		case opPong:
+			continue
+
+		case opClose:
+			ws.closeWith(closeNormal)
+			return nil, errWebSocketClosedByPeer
+
+		case opText, opBinary:
+			if fragmented {
+				return nil, ws.fail(ErrWebSocketProtocol)
+			}
+			if opcode == opBinary {
+				return nil, ws.fail(ErrWebSocketUnsupported)
+			}
+			message = payload
+
+		case opContinuation:
+			if !fragmented {
+				return nil, ws.fail(ErrWebSocketProtocol)
+			}
+			if len(message)+len(payload) > maxWebSocketMessage {
+				return nil, ws.fail(ErrWebSocketTooBig)
+			}
+			message = append(message, payload...)
+
+		default:
+			return nil, ws.fail(ErrWebSocketProtocol)
+		}
+
+		if !fin {
+			fragmented = true
+			continue
+		}
+
+		if !utf8.Valid(message) {
+			return nil, ws.fail(ErrWebSocketInvalidUTF8)
+		}
+
+		return message, nil
+	}
+}
+
+// Read a frame of the client and unmask its payload
+func (ws *webSocket) readFrame() (bool, byte, []byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(ws.reader, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+
+	fin := header[0]&0x80 != 0
+	opcode := header[0] & 0x0F
+	masked := header[1]&0x80 != 0
+	length := uint64(header[1] & 0x7F)
+
+	// No extensions are negotiated, the clients must mask their frames
+	if header[0]&0x70 != 0 || !masked {
+		return false, 0, nil, ErrWebSocketProtocol
+	}
+
+	isControl := opcode&0x08 != 0
+	if isControl && (!fin || length > 125) {
+		return false, 0, nil, ErrWebSocketProtocol
+	}
+
+	switch length {
+	case 126:
+		var extended [2]byte
+		if _, err := io.ReadFull(ws.reader, extended[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(extended[:]))
+
+	case 127:
+		var extended [8]byte
+		if _, err := io.ReadFull(ws.reader, extended[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(extended[:])
+	}
+
+	if length > maxWebSocketMessage {
+		return false, 0, nil, ErrWebSocketTooBig
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(ws.reader, mask[:]); err != nil {
+		return false, 0, nil, err
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(ws.reader, payload); err != nil {
+		return false, 0, nil, err
+	}
+
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+
+	return fin, opcode, payload, nil
+}
+
+// Close the connection with the status of the error
+func (ws *webSocket) fail(err error) error {
+	switch {
+	case errors.Is(err, ErrWebSocketProtocol):
+		ws.closeWith(closeProtocolError)
+	case errors.Is(err, ErrWebSocketTooBig):
+		ws.closeWith(closeTooBig)
+	case errors.Is(err, ErrWebSocketUnsupported):
+		ws.closeWith(closeUnsupported)
+	case errors.Is(err, ErrWebSocketInvalidUTF8):
+		ws.closeWith(closeInvalidPayload)
+	default:
+		ws.conn.Close()
+	}
+
+	return err
+}
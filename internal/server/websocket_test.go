@@ -0,0 +1,402 @@
+package server
+
+import (
+	"bufio"
+	"course/internal/blackjack"
+	"encoding/binary"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Client side of a WebSocket for the tests
+type testWebSocket struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func dialWebSocket(t *testing.T, url string, header http.Header) (*testWebSocket, *http.Response) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	conn, err := net.Dial("tcp", req.URL.Host)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	require.NoError(t, req.Write(conn))
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	require.NoError(t, err)
+
+	return &testWebSocket{conn: conn, reader: reader}, resp
+}
+
+func openWebSocket(t *testing.T, url string) *testWebSocket {
+	ws, resp := dialWebSocket(t, url, nil)
+	require.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	require.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", resp.Header.Get("Sec-WebSocket-Accept"))
+
+	return ws
+}
+
+func (ws *testWebSocket) writeFrame(t *testing.T, fin bool, opcode byte, payload []byte, masked bool) {
+	header := []byte{opcode, byte(len(payload))}
+	if fin {
+		header[0] |= 0x80
+	}
+
+	if len(payload) > 125 {
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(len(payload)))
+	}
+
+	data := append([]byte{}, payload...)
+
+	if masked {
+		header[1] |= 0x80
+		mask := []byte{1, 2, 3, 4}
+		header = append(header, mask...)
+
+		for i := range data {
+			data[i] ^= mask[i%4]
+		}
+	}
+
+	_, err := ws.conn.Write(append(header, data...))
+	require.NoError(t, err)
+}
+
+func (ws *testWebSocket) send(t *testing.T, req Request) {
+	data, err := json.Marshal(req)
+	require.NoError(t, err)
+
+	ws.writeFrame(t, true, opText, data, true)
+}
+
+func (ws *testWebSocket) readFrame(t *testing.T) (byte, []byte) {
+	require.NoError(t, ws.conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	header := make([]byte, 2)
+	_, err := io.ReadFull(ws.reader, header)
+	require.NoError(t, err)
+
+	// The server never masks
+	require.Zero(t, header[1]&0x80)
+
+	length := int(header[1] & 0x7F)
+	switch length {
+	case 126:
+		extended := make([]byte, 2)
+		_, err = io.ReadFull(ws.reader, extended)
+		require.NoError(t, err)
+		length = int(binary.BigEndian.Uint16(extended))
+	case 127:
+		extended := make([]byte, 8)
+		_, err = io.ReadFull(ws.reader, extended)
+		require.NoError(t, err)
+		length = int(binary.BigEndian.Uint64(extended))
+	}
+
+	payload := make([]byte, length)
+	_, err = io.ReadFull(ws.reader, payload)
+	require.NoError(t, err)
+
+	return header[0] & 0x0F, payload
+}
+
+func (ws *testWebSocket) receive(t *testing.T) Message {
+	opcode, payload := ws.readFrame(t)
+	require.Equal(t, byte(opText), opcode)
+
+	var message Message
+	require.NoError(t, json.Unmarshal(payload, &message))
+
+	return message
+}
+
+func (ws *testWebSocket) closeCode(t *testing.T) uint16 {
+	opcode, payload := ws.readFrame(t)
+	require.Equal(t, byte(opClose), opcode)
+	require.Len(t, payload, 2)
+
+	return binary.BigEndian.Uint16(payload)
+}
+
+func TestServer_WebSocketGame(t *testing.T) {
+	cfg := getTestTable()
+	cfg.Users = 1
+	url := startTestHTTPServer(t, cfg)
+
+	spectator := openWebSocket(t, url+"/tables/main/events")
+	state := spectator.receive(t)
+	require.Equal(t, MessageState, state.Type)
+	require.False(t, state.State.Playing)
+
+	var joined JoinResponse
+	require.Equal(t, http.StatusCreated, doRequest(t, http.MethodPost, url+"/tables/main/seats", "", JoinRequest{Name: "Alex"}, &joined))
+
+	player := openWebSocket(t, url+"/tables/main/events?token="+joined.Token)
+	state = player.receive(t)
+	require.Equal(t, MessageState, state.Type)
+
+	// The prompt may be sent before the stream is opened, then it is in the state
+	prompt := state.State.Prompt
+	if state.State.Turn != "Alex" {
+		prompt = ""
+	}
+
+	for {
+		switch prompt {
+		case blackjack.PromptBet:
+			player.send(t, Request{Type: RequestBet, Amount: 10})
+		case blackjack.PromptMove:
+			player.send(t, Request{Type: RequestMove, Move: blackjack.MoveStand})
+		}
+		prompt = ""
+
+		message := player.receive(t)
+		require.NotEqual(t, MessageError, message.Type, message.Error)
+
+		if message.Type != MessageEvent {
+			continue
+		}
+
+		event := message.Event
+		if event.Type == blackjack.EventPrompt {
+			require.Equal(t, "Alex", event.Name)
+			prompt = event.Prompt
+		}
+
+		if event.Type == blackjack.EventSettlement && event.Name == "Alex" {
+			break
+		}
+	}
+
+	// The spectator sees the round without the prompts and the hole card
+	bet := false
+
+	for {
+		message := spectator.receive(t)
+		require.Equal(t, MessageEvent, message.Type)
+
+		event := message.Event
+		require.NotEqual(t, blackjack.EventPrompt, event.Type)
+
+		if event.Type == blackjack.EventDeal && event.Name == "dealer" {
+			require.Len(t, event.Cards, 1)
+		}
+
+		if event.Type == blackjack.EventBet && event.Name == "Alex" {
+			require.Equal(t, 10, event.Bet)
+			bet = true
+		}
+
+		if event.Type == blackjack.EventSettlement && event.Name == "Alex" {
+			break
+		}
+	}
+	require.True(t, bet)
+
+	// The spectator cannot play
+	spectator.send(t, Request{Type: RequestBet, Amount: 10})
+	for {
+		message := spectator.receive(t)
+		if message.Type == MessageError {
+			require.Equal(t, ErrReadOnly.Error(), message.Error)
+			break
+		}
+	}
+
+	// The player leaves over the stream, without players the game is over
+	player.send(t, Request{Type: RequestLeave})
+	for {
+		message := spectator.receive(t)
+		if message.Type == MessageClosed {
+			break
+		}
+	}
+}
+
+func TestServer_WebSocketOrigin(t *testing.T) {
+	s, err := New(getTestTable())
+	require.NoError(t, err)
+	s.AllowOrigins("https://Blackjack.example.com/")
+
+	httpServer := httptest.NewServer(s.HTTPHandler())
+	t.Cleanup(func() {
+		require.NoError(t, s.Close())
+		httpServer.Close()
+	})
+
+	testCases := []struct {
+		name   string
+		origin string
+		status int
+	}{
+		{name: "No Origin", status: http.StatusSwitchingProtocols},
+		{name: "Same Host", origin: httpServer.URL, status: http.StatusSwitchingProtocols},
+		{name: "Allowed Origin", origin: "https://blackjack.example.com", status: http.StatusSwitchingProtocols},
+		{name: "Other Site", origin: "https://evil.example.com", status: http.StatusForbidden},
+		{name: "Other Port Of The Allowed Host", origin: "https://blackjack.example.com:8443", status: http.StatusForbidden},
+		{name: "Not URL", origin: "null", status: http.StatusForbidden},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			var header http.Header
+			if tc.origin != "" {
+				header = http.Header{"Origin": {tc.origin}}
+			}
+
+			_, resp := dialWebSocket(t, httpServer.URL+"/tables/main/events", header)
+			require.Equal(t, tc.status, resp.StatusCode)
+		})
+	}
+}
+
+func TestServer_WebSocketErrors(t *testing.T) {
+	url := startTestHTTPServer(t, getTestTable())
+
+	t.Run("Invalid Token", func(t *testing.T) {
+		_, resp := dialWebSocket(t, url+"/tables/main/events?token=0123", nil)
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("Not WebSocket", func(t *testing.T) {
+		var response ErrorResponse
+		require.Equal(t, http.StatusBadRequest, doRequest(t, http.MethodGet, url+"/tables/main/events", "", nil, &response))
+		require.Equal(t, ErrNotWebSocket.Error(), response.Error)
+	})
+
+	t.Run("Unsupported Version", func(t *testing.T) {
+		_, resp := dialWebSocket(t, url+"/tables/main/events", http.Header{"Sec-Websocket-Version": {"8"}})
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Equal(t, "13", resp.Header.Get("Sec-WebSocket-Version"))
+	})
+
+	testCases := []struct {
+		name  string
+		write func(t *testing.T, ws *testWebSocket)
+		code  uint16
+	}{
+		{
+			name: "Close",
+			write: func(t *testing.T, ws *testWebSocket) {
+				ws.writeFrame(t, true, opClose, []byte{0x03, 0xE8}, true)
+			},
+			code: closeNormal,
+		},
+		{
+			name: "Unmasked Frame",
+			write: func(t *testing.T, ws *testWebSocket) {
+				ws.writeFrame(t, true, opText, []byte("{}"), false)
+			},
+			code: closeProtocolError,
+		},
+		{
+			name: "Binary Message",
+			write: func(t *testing.T, ws *testWebSocket) {
+				ws.writeFrame(t, true, opBinary, []byte{1, 2}, true)
+			},
+			code: closeUnsupported,
+		},
+		{
+			name: "Invalid UTF-8",
+			write: func(t *testing.T, ws *testWebSocket) {
+				ws.writeFrame(t, true, opText, []byte{0xFF, 0xFE}, true)
+			},
+			code: closeInvalidPayload,
+		},
+		{
+			name: "Unexpected Continuation",
+			write: func(t *testing.T, ws *testWebSocket) {
+				ws.writeFrame(t, true, opContinuation, []byte("{}"), true)
+			},
+			code: closeProtocolError,
+		},
+		{
+			name: "Fragmented Control Frame",
+			write: func(t *testing.T, ws *testWebSocket) {
+				ws.writeFrame(t, false, opPing, nil, true)
+			},
+			code: closeProtocolError,
+		},
+		{
+			name: "Too Big",
+			write: func(t *testing.T, ws *testWebSocket) {
+				header := []byte{0x80 | opText, 0x80 | 127}
+				header = binary.BigEndian.AppendUint64(header, maxWebSocketMessage+1)
+				_, err := ws.conn.Write(header)
+				require.NoError(t, err)
+			},
+			code: closeTooBig,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ws := openWebSocket(t, url+"/tables/main/events")
+			require.Equal(t, MessageState, ws.receive(t).Type)
+
+			tc.write(t, ws)
+			require.Equal(t, tc.code, ws.closeCode(t))
+		})
+	}
+
+	t.Run("Ping And Fragments", func(t *testing.T) {
+		var joined JoinResponse
+		require.Equal(t, http.StatusCreated, doRequest(t, http.MethodPost, url+"/tables/main/seats", "", JoinRequest{Name: "Alex"}, &joined))
+
+		ws, resp := dialWebSocket(t, url+"/tables/main/events", http.Header{"Authorization": {"Bearer " + joined.Token}})
+		require.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+		require.Equal(t, MessageState, ws.receive(t).Type)
+
+		ws.writeFrame(t, true, opPing, []byte("hi"), true)
+		opcode, payload := ws.readFrame(t)
+		require.Equal(t, byte(opPong), opcode)
+		require.Equal(t, "hi", string(payload))
+
+		// A request in two fragments with a ping between them
+		request := `{"type": "move", "move": "split"}`
+		ws.writeFrame(t, false, opText, []byte(request[:10]), true)
+		ws.writeFrame(t, true, opPing, nil, true)
+		ws.writeFrame(t, true, opContinuation, []byte(request[10:]), true)
+
+		opcode, _ = ws.readFrame(t)
+		require.Equal(t, byte(opPong), opcode)
+
+		message := ws.receive(t)
+		require.Equal(t, MessageError, message.Type)
+		require.Equal(t, ErrInvalidMove.Error(), message.Error)
+	})
+
+	t.Run("Long Message", func(t *testing.T) {
+		ws := openWebSocket(t, url+"/tables/main/events")
+		require.Equal(t, MessageState, ws.receive(t).Type)
+
+		ws.writeFrame(t, true, opText, []byte(strings.Repeat(" ", 200)+"{}"), true)
+		require.Equal(t, ErrReadOnly.Error(), ws.receive(t).Error)
+	})
+}
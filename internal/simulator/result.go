@@ -0,0 +1,150 @@
+package simulator
+
+import (
+	"course/internal/blackjack"
+	"fmt"
+	"io"
+	"math"
+	"text/tabwriter"
+)
+
+// Z95
+// Normal quantile of the 95% confidence interval.
+const Z95 = 1.96
+
+// Result
+// Outcomes of the rounds played with a strategy. The money values are measured in bets.
+type Result struct {
+	Strategy   string
+	Rounds     int64
+	Wins       int64
+	Pushes     int64
+	Losses     int64
+	Busts      int64
+	Blackjacks int64
+	// Rounds the dealer busted in, the dealer plays every round
+	DealerBusts int64
+	// Sum of the money won in the rounds and of its squares, in bets of the rounds
+	net        float64
+	netSquares float64
+}
+
+// The money won is measured in the bet of the round, a round without a bet wins nothing
+func (r *Result) addRound(result blackjack.RoundResult, net int, bet int) {
+	r.Rounds++
+
+	if bet > 0 {
+		netBets := float64(net) / float64(bet)
+		r.net += netBets
+		r.netSquares += netBets * netBets
+	}
+
+	switch result {
+	case blackjack.ResultWin:
+		r.Wins++
+	case blackjack.ResultDraw:
+		r.Pushes++
+	default:
+		r.Losses++
+	}
+}
+
+func (r *Result) add(other Result) {
+	r.Rounds += other.Rounds
+	r.Wins += other.Wins
+	r.Pushes += other.Pushes
+	r.Losses += other.Losses
+	r.Busts += other.Busts
+	r.Blackjacks += other.Blackjacks
+	r.DealerBusts += other.DealerBusts
+	r.net += other.net
+	r.netSquares += other.netSquares
+}
+
+// EV
+// Expected money won in a round, in bets. The house edge is -EV.
+func (r Result) EV() float64 {
+	if r.Rounds == 0 {
+		return 0
+	}
+
+	return r.net / float64(r.Rounds)
+}
+
+// Variance
+// Sample variance of the money won in a round, in squared bets.
+func (r Result) Variance() float64 {
+	if r.Rounds < 2 {
+		return 0
+	}
+
+	n := float64(r.Rounds)
+	mean := r.net / n
+
+	return (r.netSquares - n*mean*mean) / (n - 1)
+}
+
+func (r Result) StdDev() float64 {
+	return math.Sqrt(r.Variance())
+}
+
+// ConfidenceInterval
+// Interval of the EV for the normal quantile z, Z95 gives the 95% interval.
+func (r Result) ConfidenceInterval(z float64) (float64, float64) {
+	if r.Rounds == 0 {
+		return 0, 0
+	}
+
+	margin := z * r.StdDev() / math.Sqrt(float64(r.Rounds))
+	return r.EV() - margin, r.EV() + margin
+}
+
+func (r Result) WinRate() float64 {
+	return r.rate(r.Wins)
+}
+
+func (r Result) PushRate() float64 {
+	return r.rate(r.Pushes)
+}
+
+func (r Result) LossRate() float64 {
+	return r.rate(r.Losses)
+}
+
+func (r Result) BustRate() float64 {
+	return r.rate(r.Busts)
+}
+
+func (r Result) BlackjackRate() float64 {
+	return r.rate(r.Blackjacks)
+}
+
+func (r Result) DealerBustRate() float64 {
+	return r.rate(r.DealerBusts)
+}
+
+func (r Result) rate(count int64) float64 {
+	if r.Rounds == 0 {
+		return 0
+	}
+
+	return float64(count) / float64(r.Rounds)
+}
+
+// Report
+// Print a table with the results of the strategies.
+func Report(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Strategy\tRounds\tEV\t95%% CI\tStd dev\tWin\tPush\tLoss\tBust\tBlackjack\tDealer bust\n")
+
+	for _, r := range results {
+		low, high := r.ConfidenceInterval(Z95)
+
+		fmt.Fprintf(tw, "%s\t%d\t%+.2f%%\t%+.2f%% .. %+.2f%%\t%.3f\t%.2f%%\t%.2f%%\t%.2f%%\t%.2f%%\t%.2f%%\t%.2f%%\n",
+			r.Strategy, r.Rounds, r.EV()*100, low*100, high*100, r.StdDev(),
+			r.WinRate()*100, r.PushRate()*100, r.LossRate()*100, r.BustRate()*100, r.BlackjackRate()*100, r.DealerBustRate()*100)
+	}
+
+	return tw.Flush()
+}
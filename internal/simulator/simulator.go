@@ -0,0 +1,281 @@
+/*
+  This package plays headless games of blackjack to measure the expected value of strategies.
+*/
+
+package simulator
+
+import (
+	"course/internal/blackjack"
+	"course/internal/odds"
+	"course/pkg/random"
+	"errors"
+	"io"
+	"math"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Number of rounds played with one random source. The results do not depend on the number of workers,
+// because the rounds are split into the same chunks with the same seeds.
+const chunkRounds = 10000
+
+// Money of the bot at a table. The bets are made by the strategy, a new table is taken when the money is lost
+const startingMoney = 1000
+
+var (
+	ErrInvalidRounds  = errors.New("rounds number less than 1")
+	ErrInvalidWorkers = errors.New("workers number is negative")
+	ErrNoStrategies   = errors.New("no strategies to simulate")
+)
+
+type Config struct {
+	Rules blackjack.Rules
+	// Number of decks, 1 deck is used if 0
+	DecksNumber int
+	// Part of the shoe dealt before it is reshuffled, see blackjack.Config.Penetration.
+	// Every round is dealt from a new shoe if 0
+	Penetration float64
+	// Number of rounds played with every strategy
+	Rounds int
+	// Number of goroutines, runtime.NumCPU is used if 0
+	Workers int
+	// Seed of the random sources, used if greater than 0.
+	// The same seed gives the same results
+	Seed int64
+	// Names of the built-in strategies, every job creates its own strategies
+	// with the random source of the job
+	Strategies []string
+}
+
+func (cfg Config) Validate() error {
+	if cfg.Rounds < 1 {
+		return ErrInvalidRounds
+	}
+
+	if cfg.Workers < 0 {
+		return ErrInvalidWorkers
+	}
+
+	if cfg.DecksNumber != 0 && (cfg.DecksNumber < blackjack.MinDecksNumber || cfg.DecksNumber > blackjack.MaxDecksNumber) {
+		return blackjack.ErrInvalidDecksNumber
+	}
+
+	if cfg.Penetration < 0 || cfg.Penetration > blackjack.MaxPenetration {
+		return blackjack.ErrInvalidPenetration
+	}
+
+	if len(cfg.Strategies) == 0 {
+		return ErrNoStrategies
+	}
+
+	for _, name := range cfg.Strategies {
+		if _, err := blackjack.NewStrategy(name, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Rounds played with one random source
+type job struct {
+	strategy int
+	rounds   int
+	seed     int64
+}
+
+// Run
+// Play the rounds of every strategy in parallel and return the results in the order of the strategies.
+func Run(cfg Config) ([]Result, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	workers := cfg.Workers
+	if workers == 0 {
+		workers = runtime.NumCPU()
+	}
+
+	seed := cfg.Seed
+	if seed <= 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	var jobs []job
+
+	for i := range cfg.Strategies {
+		for rounds := cfg.Rounds; rounds > 0; rounds -= chunkRounds {
+			jobRounds := rounds
+			if jobRounds > chunkRounds {
+				jobRounds = chunkRounds
+			}
+
+			jobs = append(jobs, job{
+				strategy: i,
+				rounds:   jobRounds,
+				seed:     seed + int64(len(jobs)),
+			})
+		}
+	}
+
+	jobResults := make([]Result, len(jobs))
+	jobErrors := make([]error, len(jobs))
+	queue := make(chan int)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := range queue {
+				jobResults[j], jobErrors[j] = playJob(cfg, jobs[j])
+			}
+		}()
+	}
+
+	for j := range jobs {
+		queue <- j
+	}
+	close(queue)
+	wg.Wait()
+
+	results := make([]Result, len(cfg.Strategies))
+	for i, name := range cfg.Strategies {
+		results[i].Strategy = name
+	}
+
+	for j, job := range jobs {
+		if jobErrors[j] != nil {
+			return nil, jobErrors[j]
+		}
+		results[job.strategy].add(jobResults[j])
+	}
+
+	return results, nil
+}
+
+func playJob(cfg Config, job job) (Result, error) {
+	r := random.New(random.Config{Seed: job.seed})
+	recorder := &roundRecorder{}
+
+	var game *blackjack.Blackjack
+
+	for recorder.result.Rounds < int64(job.rounds) {
+		// A new table is taken when the bot has lost the money or makes no bet with the rest of it,
+		// the seeds of the tables come from the job
+		if game == nil || !recorder.played {
+			var err error
+			if game, err = newTable(cfg, cfg.Strategies[job.strategy], r, recorder); err != nil {
+				return Result{}, err
+			}
+		}
+
+		recorder.played = false
+		if err := game.Simulate(1); err != nil {
+			return Result{}, err
+		}
+	}
+
+	return recorder.result, nil
+}
+
+// Game of one bot seat with the strategy
+func newTable(cfg Config, strategyName string, r *random.Random, recorder *roundRecorder) (*blackjack.Blackjack, error) {
+	strategy, err := blackjack.NewStrategy(strategyName, r)
+	if err != nil {
+		return nil, err
+	}
+
+	return blackjack.NewBlackjack(blackjack.Config{
+		PlayersStartingMoney: startingMoney,
+		Seats:                []blackjack.SeatConfig{{Bot: true}},
+		BotStrategies:        []blackjack.Strategy{strategy},
+		DecksNumber:          cfg.DecksNumber,
+		Penetration:          cfg.Penetration,
+		Rules:                cfg.Rules,
+		Seed:                 int64(r.RandInt(1, math.MaxInt32)),
+		NoDelay:              true,
+		Output:               io.Discard,
+		Observers:            []blackjack.Observer{recorder},
+	})
+}
+
+// Result of the rounds by the events of the game, the table has one bot seat.
+// The rounds without a bet are not counted
+type roundRecorder struct {
+	result Result
+	// A round with a bet was settled
+	played bool
+	// Outcomes of the current round, counted on its settlement
+	natural    bool
+	dealerBust bool
+}
+
+func (r *roundRecorder) OnEvent(event blackjack.Event) {
+	switch event.Type {
+	case blackjack.EventRoundStart:
+		r.natural = false
+		r.dealerBust = false
+
+	case blackjack.EventDeal:
+		if len(event.Cards) == 2 && event.Points == blackjack.MaxPoints {
+			r.natural = true
+		}
+
+	case blackjack.EventDealerStand:
+		r.dealerBust = event.Points > blackjack.MaxPoints
+
+	case blackjack.EventSettlement:
+		if event.Bet == 0 {
+			return
+		}
+
+		if r.natural {
+			r.result.Blackjacks++
+		}
+		if r.dealerBust {
+			r.result.DealerBusts++
+		}
+		if event.Points > blackjack.MaxPoints {
+			r.result.Busts++
+		}
+		r.result.addRound(event.Result, event.Payout-event.Bet, event.Bet)
+		r.played = true
+	}
+}
+
+// DealerBustProbability
+// Exact probability that the dealer busts in a round dealt from a full shoe, the simulated
+// Result.DealerBustRate comes close to it with the number of rounds.
+func DealerBustProbability(rules blackjack.Rules, decksNumber int) (float64, error) {
+	if decksNumber == 0 {
+		decksNumber = blackjack.MinDecksNumber
+	}
+
+	calculator := odds.NewCalculator(odds.Rules{
+		DealerHitsSoft17: rules.DealerHitsSoft17,
+		NaturalBeats21:   rules.BlackjackPays3To2,
+	})
+	shoe := odds.NewShoe(decksNumber)
+	bust := 0.0
+
+	for upValue := 1; upValue <= odds.CardValues; upValue++ {
+		rest, err := shoe.Without(upValue)
+		if err != nil {
+			return 0, err
+		}
+
+		outcomes, err := calculator.Outcomes(upValue, rest)
+		if err != nil {
+			return 0, err
+		}
+
+		bust += shoe.Probability(upValue) * outcomes.Bust
+	}
+
+	return bust, nil
+}
@@ -0,0 +1,257 @@
+package simulator
+
+import (
+	"bytes"
+	"course/internal/blackjack"
+	"course/internal/deck"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func getValidTestCfg() Config {
+	return Config{
+		DecksNumber: 6,
+		Rounds:      25000,
+		Workers:     4,
+		Seed:        42,
+		Strategies:  []string{blackjack.StrategyNaive, blackjack.StrategyDealer, blackjack.StrategyBasic, blackjack.StrategyRandom},
+	}
+}
+
+func TestRun(t *testing.T) {
+	cfg := getValidTestCfg()
+
+	testCases := []struct {
+		name   string
+		config func() Config
+		check  func(results []Result, err error)
+	}{
+		{
+			name:   "Ok",
+			config: func() Config { return cfg },
+			check: func(results []Result, err error) {
+				require.NoError(t, err)
+				require.Len(t, results, 4)
+
+				for i, r := range results {
+					require.Equal(t, cfg.Strategies[i], r.Strategy)
+					require.Equal(t, int64(cfg.Rounds), r.Rounds)
+					require.Equal(t, r.Rounds, r.Wins+r.Pushes+r.Losses)
+					require.Less(t, r.EV(), 0.0)
+					require.Greater(t, r.EV(), -0.5)
+
+					low, high := r.ConfidenceInterval(Z95)
+					require.Less(t, low, r.EV())
+					require.Greater(t, high, r.EV())
+				}
+
+				// The naive bot stands earlier than the dealer, the basic strategy is the best one
+				require.Less(t, results[0].BustRate(), results[1].BustRate())
+				require.Greater(t, results[2].EV(), results[0].EV())
+				require.Greater(t, results[2].EV(), results[3].EV())
+			},
+		},
+		{
+			name: "Same Results With One Worker",
+			config: func() Config {
+				c := cfg
+				c.Workers = 1
+				return c
+			},
+			check: func(results []Result, err error) {
+				require.NoError(t, err)
+
+				expected, err := Run(cfg)
+				require.NoError(t, err)
+				require.Equal(t, expected, results)
+			},
+		},
+		{
+			name: "Natural Pays 3 To 2",
+			config: func() Config {
+				c := cfg
+				c.Rules = blackjack.Rules{BlackjackPays3To2: true}
+				return c
+			},
+			check: func(results []Result, err error) {
+				require.NoError(t, err)
+
+				evenMoney, err := Run(cfg)
+				require.NoError(t, err)
+				require.Greater(t, results[1].EV(), evenMoney[1].EV())
+			},
+		},
+		{
+			name: "Penetration",
+			config: func() Config {
+				c := cfg
+				c.Penetration = 0.75
+				return c
+			},
+			check: func(results []Result, err error) {
+				require.NoError(t, err)
+
+				newShoes, err := Run(cfg)
+				require.NoError(t, err)
+				require.Equal(t, newShoes[0].Rounds, results[0].Rounds)
+				require.NotEqual(t, newShoes, results)
+			},
+		},
+		{
+			name: "Invalid Penetration",
+			config: func() Config {
+				c := cfg
+				c.Penetration = 0.95
+				return c
+			},
+			check: func(results []Result, err error) {
+				require.ErrorIs(t, err, blackjack.ErrInvalidPenetration)
+			},
+		},
+		{
+			name: "No Rounds",
+			config: func() Config {
+				c := cfg
+				c.Rounds = 0
+				return c
+			},
+			check: func(results []Result, err error) {
+				require.ErrorIs(t, err, ErrInvalidRounds)
+			},
+		},
+		{
+			name: "No Strategies",
+			config: func() Config {
+				c := cfg
+				c.Strategies = nil
+				return c
+			},
+			check: func(results []Result, err error) {
+				require.ErrorIs(t, err, ErrNoStrategies)
+			},
+		},
+		{
+			name: "Unknown Strategy",
+			config: func() Config {
+				c := cfg
+				c.Strategies = []string{"psychic"}
+				return c
+			},
+			check: func(results []Result, err error) {
+				require.ErrorIs(t, err, blackjack.ErrUnknownStrategy)
+			},
+		},
+		{
+			name: "Invalid Decks Number",
+			config: func() Config {
+				c := cfg
+				c.DecksNumber = 9
+				return c
+			},
+			check: func(results []Result, err error) {
+				require.ErrorIs(t, err, blackjack.ErrInvalidDecksNumber)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			results, err := Run(tc.config())
+			tc.check(results, err)
+		})
+	}
+}
+
+func TestResult(t *testing.T) {
+	var r Result
+	r.addRound(blackjack.ResultWin, 3, 2)
+	r.addRound(blackjack.ResultDefeat, -10, 10)
+	r.addRound(blackjack.ResultDraw, 0, 0)
+	r.addRound(blackjack.ResultWin, 5, 5)
+
+	require.Equal(t, int64(4), r.Rounds)
+	require.InDelta(t, 0.375, r.EV(), 1e-9)
+	require.InDelta(t, 0.5, r.WinRate(), 1e-9)
+	require.InDelta(t, 0.25, r.PushRate(), 1e-9)
+	// Results in bets: 1.5, -1, 0, 1
+	require.InDelta(t, 1.229167, r.Variance(), 1e-6)
+
+	var buf bytes.Buffer
+	require.NoError(t, Report(&buf, []Result{r}))
+	require.Contains(t, buf.String(), "+37.50%")
+}
+
+func TestRoundRecorder(t *testing.T) {
+	recorder := &roundRecorder{}
+
+	rounds := [][]blackjack.Event{
+		{
+			{Type: blackjack.EventRoundStart},
+			{Type: blackjack.EventDeal, Cards: deck.MustParseCards("As Kh"), Points: 21},
+			{Type: blackjack.EventDealerStand, Points: 23},
+			{Type: blackjack.EventSettlement, Points: 21, Bet: 100, Payout: 200, Result: blackjack.ResultWin},
+		},
+		{
+			// No bet, the round is not counted
+			{Type: blackjack.EventRoundStart},
+			{Type: blackjack.EventDeal, Cards: deck.MustParseCards("As Kh"), Points: 21},
+			{Type: blackjack.EventDealerStand, Points: 23},
+			{Type: blackjack.EventSettlement, Points: 21, Result: blackjack.ResultWin},
+		},
+		{
+			{Type: blackjack.EventRoundStart},
+			{Type: blackjack.EventDeal, Cards: deck.MustParseCards("10s 6h"), Points: 16},
+			{Type: blackjack.EventDealerStand, Points: 18},
+			{Type: blackjack.EventSettlement, Points: 25, Bet: 10, Result: blackjack.ResultDefeat},
+		},
+	}
+
+	for _, events := range rounds {
+		for _, event := range events {
+			recorder.OnEvent(event)
+		}
+	}
+
+	r := recorder.result
+	require.Equal(t, int64(2), r.Rounds)
+	require.Equal(t, int64(1), r.Blackjacks)
+	require.Equal(t, int64(1), r.DealerBusts)
+	require.Equal(t, int64(1), r.Busts)
+	// The bets differ, the money is measured in them: 1 and -1
+	require.InDelta(t, 0, r.EV(), 1e-9)
+}
+
+func TestDealerBustProbability(t *testing.T) {
+	testCases := []struct {
+		name  string
+		rules blackjack.Rules
+	}{
+		{
+			name: "Dealer Stands On Soft 17",
+		},
+		{
+			name:  "Dealer Hits Soft 17",
+			rules: blackjack.Rules{DealerHitsSoft17: true},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := getValidTestCfg()
+			cfg.Rules = tc.rules
+			cfg.Rounds = 100000
+			cfg.Strategies = []string{blackjack.StrategyDealer}
+
+			probability, err := DealerBustProbability(tc.rules, cfg.DecksNumber)
+			require.NoError(t, err)
+			// About 28% of the dealer hands bust in a six-deck shoe
+			require.InDelta(t, 0.28, probability, 0.015)
+
+			results, err := Run(cfg)
+			require.NoError(t, err)
+			require.InDelta(t, probability, results[0].DealerBustRate(), 0.01)
+		})
+	}
+}
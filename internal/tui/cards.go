@@ -0,0 +1,81 @@
+package tui
+
+import (
+	"course/internal/deck"
+	"strings"
+	"unicode/utf8"
+)
+
+// Height of a drawn card in lines
+const cardHeight = 4
+
+// Draw the cards in a row, the hidden cards face-down after them:
+//
+//	 ___   ___
+//	|A  | |░░░|
+//	| ♠ | |░░░|
+//	|__A| |░░░|
+func drawCards(cards []*deck.Card, hidden int) string {
+	if len(cards) == 0 && hidden == 0 {
+		return strings.Repeat("\n", cardHeight)
+	}
+
+	lines := make([][]string, cardHeight)
+
+	for _, card := range cards {
+		for i, line := range cardFace(card) {
+			lines[i] = append(lines[i], line)
+		}
+	}
+
+	for j := 0; j < hidden; j++ {
+		for i, line := range cardBack() {
+			lines[i] = append(lines[i], line)
+		}
+	}
+
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(strings.TrimRight(strings.Join(line, " "), " "))
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+func cardFace(card *deck.Card) [cardHeight]string {
+	label := card.Rank().Label()
+	symbol := card.Suit().String()
+
+	return [cardHeight]string{
+		" ___ ",
+		"|" + padRight(label, 3) + "|",
+		"| " + symbol + " |",
+		"|" + padLeft(label, 3, "_") + "|",
+	}
+}
+
+func cardBack() [cardHeight]string {
+	return [cardHeight]string{
+		" ___ ",
+		"|░░░|",
+		"|░░░|",
+		"|░░░|",
+	}
+}
+
+func padRight(text string, width int) string {
+	if n := utf8.RuneCountInString(text); n < width {
+		return text + strings.Repeat(" ", width-n)
+	}
+
+	return text
+}
+
+func padLeft(text string, width int, fill string) string {
+	if n := utf8.RuneCountInString(text); n < width {
+		return strings.Repeat(fill, width-n) + text
+	}
+
+	return text
+}
@@ -0,0 +1,364 @@
+/*
+  This package draws the game on the full terminal screen.
+
+  The screen is a game observer: it keeps the table built from the events and repaints it on every
+  event. Every seat and the dealer are drawn with card graphics, the hole card of the dealer face-down,
+  under them the bankroll of the users, the last lines of the game text and the keys of the prompt.
+*/
+
+package tui
+
+import (
+	"bytes"
+	"course/internal/blackjack"
+	"course/internal/deck"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Control sequences of the terminal
+const (
+	enterAltScreen = "\x1b[?1049h"
+	leaveAltScreen = "\x1b[?1049l"
+	clearScreen    = "\x1b[H\x1b[2J"
+)
+
+// Number of the game text lines in the messages panel
+const messageLines = 5
+
+// Width of the panel rulers
+const screenWidth = 72
+
+// Screen
+// Full-screen view of the game. OnEvent and Write are called from the game loop, the screen is not safe
+// for concurrent use.
+type Screen struct {
+	out   io.Writer
+	rules blackjack.Rules
+
+	round int
+	seats []seat
+	// Money of the users at the first round
+	startMoney map[string]int
+	dealer     hand
+	// Hidden cards of the dealer
+	hiddenCards int
+	turn        string
+	prompt      blackjack.PromptKind
+	promptMoney int
+
+	// Unfinished line of the game text
+	partial  string
+	messages []string
+}
+
+type hand struct {
+	cards  []*deck.Card
+	points int
+}
+
+type seat struct {
+	hand
+	name   string
+	bot    bool
+	money  int
+	bet    int
+	result blackjack.RoundResult
+	payout int
+}
+
+// NewScreen
+// Screen drawn to the terminal. The rules tell whether the dealer has a hole card.
+func NewScreen(out io.Writer, rules blackjack.Rules) *Screen {
+	return &Screen{
+		out:        out,
+		rules:      rules,
+		startMoney: make(map[string]int),
+	}
+}
+
+// Open
+// Switch the terminal to the alternate screen and draw the empty table.
+func (s *Screen) Open() error {
+	if _, err := io.WriteString(s.out, enterAltScreen); err != nil {
+		return err
+	}
+
+	return s.repaint()
+}
+
+// Close
+// Return to the normal screen and print the last state of the table on it.
+func (s *Screen) Close() error {
+	if _, err := io.WriteString(s.out, leaveAltScreen); err != nil {
+		return err
+	}
+
+	s.flush()
+	s.turn, s.prompt = "", ""
+
+	_, err := io.WriteString(s.out, s.Render())
+
+	return err
+}
+
+// Write
+// The game text is shown in the messages panel, the screen keeps its last lines.
+func (s *Screen) Write(p []byte) (int, error) {
+	text := s.partial + string(p)
+
+	lines := strings.Split(text, "\n")
+	s.partial = lines[len(lines)-1]
+
+	for _, line := range lines[:len(lines)-1] {
+		s.addMessage(line)
+	}
+
+	return len(p), nil
+}
+
+func (s *Screen) flush() {
+	s.addMessage(s.partial)
+	s.partial = ""
+}
+
+func (s *Screen) addMessage(line string) {
+	line = strings.TrimSpace(line)
+
+	// The input marks are drawn by the action bar
+	line = strings.TrimSpace(strings.TrimPrefix(line, ">>"))
+	if line == "" {
+		return
+	}
+
+	s.messages = append(s.messages, line)
+	if len(s.messages) > messageLines {
+		s.messages = s.messages[len(s.messages)-messageLines:]
+	}
+}
+
+func (s *Screen) OnEvent(event blackjack.Event) {
+	s.apply(event)
+
+	// The drawing errors are not the errors of the game
+	s.repaint()
+}
+
+func (s *Screen) repaint() error {
+	_, err := io.WriteString(s.out, clearScreen+s.Render())
+
+	return err
+}
+
+// Apply the event to the table
+func (s *Screen) apply(event blackjack.Event) {
+	if event.Type != blackjack.EventPrompt {
+		s.turn, s.prompt = "", ""
+	}
+
+	switch event.Type {
+	case blackjack.EventRoundStart:
+		s.round = event.Round
+		s.dealer = hand{}
+		s.hiddenCards = 0
+		s.seats = make([]seat, 0, len(event.Seats))
+
+		for _, player := range event.Seats {
+			s.seats = append(s.seats, seat{name: player.Name, bot: player.Bot, money: player.Money})
+
+			if _, ok := s.startMoney[player.Name]; !ok && !player.Bot {
+				s.startMoney[player.Name] = player.Money
+			}
+		}
+
+	case blackjack.EventPrompt:
+		s.turn, s.prompt, s.promptMoney = event.Name, event.Prompt, event.Money
+		s.flush()
+
+	case blackjack.EventDealerReveal:
+		s.dealer.cards = append(s.dealer.cards, event.Cards...)
+		s.dealer.points = event.Points
+		s.hiddenCards = 0
+
+	case blackjack.EventDealerHit, blackjack.EventDealerStand:
+		s.dealer.cards = append(s.dealer.cards, event.Cards...)
+		s.dealer.points = event.Points
+
+	case blackjack.EventDeal:
+		// The dealer is the only participant of the deal without a seat
+		if s.seat(event.Name) == nil {
+			s.dealer.cards = append(s.dealer.cards, event.Cards...)
+			s.dealer.points = event.Points
+
+			if !s.rules.NoHoleCard {
+				s.hiddenCards = 1
+			}
+			return
+		}
+
+		s.applySeatEvent(event)
+
+	default:
+		s.applySeatEvent(event)
+	}
+}
+
+func (s *Screen) applySeatEvent(event blackjack.Event) {
+	player := s.seat(event.Name)
+	if player == nil {
+		return
+	}
+
+	switch event.Type {
+	case blackjack.EventBet:
+		player.bet = event.Bet
+		player.money = event.Money
+
+	case blackjack.EventDeal, blackjack.EventHit:
+		player.cards = append(player.cards, event.Cards...)
+		player.points = event.Points
+
+	case blackjack.EventSettlement:
+		player.result = event.Result
+		player.payout = event.Payout
+		player.money = event.Money
+	}
+}
+
+func (s *Screen) seat(name string) *seat {
+	for i := range s.seats {
+		if s.seats[i].name == name {
+			return &s.seats[i]
+		}
+	}
+
+	return nil
+}
+
+// Render
+// Text of the screen without the control sequences.
+func (s *Screen) Render() string {
+	var b bytes.Buffer
+
+	title := "Blackjack"
+	if s.round > 0 {
+		title = fmt.Sprintf("Blackjack - round %d", s.round)
+	}
+	fmt.Fprintln(&b, ruler(title))
+
+	dealerTitle := "Dealer"
+	if len(s.dealer.cards) > 0 {
+		dealerTitle = fmt.Sprintf("Dealer (%d)", s.dealer.points)
+	}
+	fmt.Fprintln(&b, dealerTitle)
+	b.WriteString(drawCards(s.dealer.cards, s.hiddenCards))
+
+	for _, player := range s.seats {
+		fmt.Fprintln(&b)
+		fmt.Fprintln(&b, s.seatTitle(player))
+		b.WriteString(drawCards(player.cards, 0))
+	}
+
+	fmt.Fprintln(&b, ruler("Bankroll"))
+	s.renderBankroll(&b)
+
+	fmt.Fprintln(&b, ruler("Messages"))
+	for _, message := range s.messages {
+		fmt.Fprintln(&b, message)
+	}
+
+	fmt.Fprintln(&b, ruler("Actions"))
+	s.renderActions(&b)
+
+	return b.String()
+}
+
+func (s *Screen) seatTitle(player seat) string {
+	title := player.name
+	if player.bot {
+		title += " (bot)"
+	}
+
+	if len(player.cards) > 0 {
+		title += fmt.Sprintf(" - %d points", player.points)
+	}
+
+	if player.bet > 0 {
+		title += fmt.Sprintf(", bet %d c.", player.bet)
+	}
+
+	if player.result != "" {
+		title += fmt.Sprintf(", %s, %d c. paid", player.result, player.payout)
+	}
+
+	if player.name == s.turn {
+		title = "> " + title
+	}
+
+	return title
+}
+
+func (s *Screen) renderBankroll(w io.Writer) {
+	for _, player := range s.seats {
+		if player.bot {
+			continue
+		}
+
+		change := player.money + player.bet - s.startMoney[player.name]
+		if player.result != "" {
+			change = player.money - s.startMoney[player.name]
+		}
+
+		fmt.Fprintf(w, "%-16s money %6d c.   bet %5d c.   session %+d c.\n", player.name, player.money, player.bet, change)
+	}
+}
+
+func (s *Screen) renderActions(w io.Writer) {
+	switch s.prompt {
+	case blackjack.PromptBet:
+		fmt.Fprintf(w, "%s, make your bet from 1 to %d c.\n", s.turn, s.promptMoney)
+		fmt.Fprintln(w, keys(
+			"<amount>", "Bet",
+			string(blackjack.ActionExit), "Exit",
+		))
+		fmt.Fprint(w, ">> ")
+
+	case blackjack.PromptMove:
+		fmt.Fprintf(w, "%s, your move\n", s.turn)
+		fmt.Fprintln(w, keys(
+			string(blackjack.ActionTakeCard), "Take card",
+			string(blackjack.ActionPass), "Pass",
+			string(blackjack.ActionHint), "Hint",
+			string(blackjack.ActionAnalyze), "Odds",
+			string(blackjack.ActionSave), "Save",
+			string(blackjack.ActionLoad), "Load",
+			string(blackjack.ActionExit), "Exit",
+		))
+		fmt.Fprint(w, ">> ")
+
+	default:
+		fmt.Fprintln(w, "Wait for your turn")
+	}
+}
+
+// Key bindings like "[t] Take card  [q] Exit" from the pairs of a key and its action
+func keys(pairs ...string) string {
+	bindings := make([]string, 0, len(pairs)/2)
+
+	for i := 0; i+1 < len(pairs); i += 2 {
+		bindings = append(bindings, fmt.Sprintf("[%s] %s", pairs[i], pairs[i+1]))
+	}
+
+	return strings.Join(bindings, "  ")
+}
+
+func ruler(title string) string {
+	line := "-- " + title + " "
+
+	if len(line) < screenWidth {
+		line += strings.Repeat("-", screenWidth-len(line))
+	}
+
+	return line
+}
@@ -0,0 +1,178 @@
+package tui
+
+import (
+	"bytes"
+	"course/internal/blackjack"
+	"course/internal/deck"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+// Events of a dealt round: Alex and a bot against the dealer, Alex is asked for a move
+func getTestRound() []blackjack.Event {
+	return []blackjack.Event{
+		{
+			Type:  blackjack.EventRoundStart,
+			Round: 2,
+			Seats: []blackjack.Seat{
+				{Name: "Alex", Money: 100},
+				{Name: "Kim", Bot: true, Money: 50},
+			},
+		},
+		{Type: blackjack.EventBet, Name: "Alex", Bet: 10, Money: 90},
+		{Type: blackjack.EventBet, Name: "Kim", Bet: 5, Money: 45},
+		{
+			Type:   blackjack.EventDeal,
+			Name:   "Alex",
+			Cards:  deck.MustParseCards("As 10h"),
+			Points: 21,
+		},
+		{
+			Type:   blackjack.EventDeal,
+			Name:   "Kim",
+			Cards:  deck.MustParseCards("7c Qd"),
+			Points: 17,
+		},
+		{
+			Type:   blackjack.EventDeal,
+			Name:   "dealer",
+			Cards:  deck.MustParseCards("9d"),
+			Points: 9,
+		},
+		{Type: blackjack.EventPrompt, Name: "Alex", Prompt: blackjack.PromptMove, Points: 21, Bet: 10, Money: 90},
+	}
+}
+
+func TestScreen_Render(t *testing.T) {
+	testCases := []struct {
+		name   string
+		rules  blackjack.Rules
+		events func() []blackjack.Event
+		check  func(text string)
+	}{
+		{
+			name:   "Empty Table",
+			events: func() []blackjack.Event { return nil },
+			check: func(text string) {
+				require.Contains(t, text, "-- Blackjack ---")
+				require.Contains(t, text, "Wait for your turn")
+			},
+		},
+		{
+			name:   "Hole Card",
+			events: getTestRound,
+			check: func(text string) {
+				require.Contains(t, text, "-- Blackjack - round 2 ---")
+				require.Contains(t, text, "Dealer (9)\n ___   ___\n|9  | |░░░|\n| ♦ | |░░░|\n|__9| |░░░|\n")
+				require.Contains(t, text, "> Alex - 21 points, bet 10 c.\n ___   ___\n|A  | |10 |\n| ♠ | | ♥ |\n|__A| |_10|\n")
+				require.Contains(t, text, "Kim (bot) - 17 points, bet 5 c.")
+			},
+		},
+		{
+			name:   "No Hole Card",
+			rules:  blackjack.Rules{NoHoleCard: true},
+			events: getTestRound,
+			check: func(text string) {
+				require.Contains(t, text, "Dealer (9)\n ___\n|9  |\n")
+				require.NotContains(t, text, "░")
+			},
+		},
+		{
+			name:   "Move Prompt",
+			events: getTestRound,
+			check: func(text string) {
+				require.Contains(t, text, "Alex, your move\n[t] Take card  [p] Pass")
+				require.True(t, strings.HasSuffix(text, ">> "))
+			},
+		},
+		{
+			name: "Bankroll",
+			events: func() []blackjack.Event {
+				return append(getTestRound(),
+					blackjack.Event{Type: blackjack.EventStand, Name: "Alex", Points: 21},
+					blackjack.Event{Type: blackjack.EventDealerReveal, Name: "dealer", Cards: deck.MustParseCards("8s"), Points: 17},
+					blackjack.Event{Type: blackjack.EventSettlement, Name: "Alex", Result: blackjack.ResultWin, Payout: 25, Money: 115},
+				)
+			},
+			check: func(text string) {
+				require.Contains(t, text, "Alex             money    115 c.   bet    10 c.   session +15 c.")
+				require.Contains(t, text, "Alex - 21 points, bet 10 c., win, 25 c. paid")
+				require.Contains(t, text, "Dealer (17)\n ___   ___\n|9  | |8  |")
+				require.NotContains(t, text, "░")
+				require.NotContains(t, text, "Kim             money")
+				require.Contains(t, text, "Wait for your turn")
+			},
+		},
+		{
+			name: "Bet Prompt",
+			events: func() []blackjack.Event {
+				return append(getTestRound()[:1], blackjack.Event{Type: blackjack.EventPrompt, Name: "Alex", Prompt: blackjack.PromptBet, Money: 100})
+			},
+			check: func(text string) {
+				require.Contains(t, text, "Alex, make your bet from 1 to 100 c.\n[<amount>] Bet  [q] Exit\n>> ")
+				require.Contains(t, text, "session +0 c.")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			screen := NewScreen(&out, tc.rules)
+
+			for _, event := range tc.events() {
+				screen.OnEvent(event)
+			}
+
+			tc.check(screen.Render())
+		})
+	}
+}
+
+func TestScreen_Messages(t *testing.T) {
+	var out bytes.Buffer
+	screen := NewScreen(&out, blackjack.Rules{})
+
+	for i := 1; i <= 7; i++ {
+		fmt.Fprintf(screen, "\nline %d", i)
+	}
+	fmt.Fprint(screen, "\nMake your bet")
+
+	text := screen.Render()
+	require.NotContains(t, text, "line 2\n")
+	require.Contains(t, text, "---\nline 3\n")
+	require.NotContains(t, text, "Make your bet")
+
+	// The unfinished line is shown with the prompt
+	screen.OnEvent(blackjack.Event{Type: blackjack.EventPrompt, Name: "Alex", Prompt: blackjack.PromptBet, Money: 10})
+
+	text = screen.Render()
+	require.NotContains(t, text, "line 3\n")
+	require.Contains(t, text, "line 4\nline 5\nline 6\nline 7\nMake your bet\n")
+}
+
+func TestScreen_Repaint(t *testing.T) {
+	var out bytes.Buffer
+	screen := NewScreen(&out, blackjack.Rules{})
+
+	require.NoError(t, screen.Open())
+	require.True(t, strings.HasPrefix(out.String(), enterAltScreen+clearScreen))
+
+	for _, event := range getTestRound() {
+		screen.OnEvent(event)
+	}
+	require.Equal(t, len(getTestRound())+1, strings.Count(out.String(), clearScreen))
+
+	out.Reset()
+	require.NoError(t, screen.Close())
+
+	// The last table stays on the normal screen without the prompt
+	require.True(t, strings.HasPrefix(out.String(), leaveAltScreen))
+	require.NotContains(t, out.String(), clearScreen)
+	require.Contains(t, out.String(), "Alex - 21 points")
+	require.Contains(t, out.String(), "Wait for your turn")
+}
@@ -2,12 +2,15 @@ package random
 
 import (
 	"math/rand"
+	"sync"
 	"time"
 )
 
 type Characters = string
 
+// Package random source. The package functions lock it, so the games of several goroutines can share it
 var (
+	mu         sync.Mutex
 	randomizer = New()
 )
 
@@ -24,6 +27,8 @@ type Config struct {
 	Seed  int64
 }
 
+// Random
+// Source of random values. It is not safe for concurrent use, every goroutine needs its own one.
 type Random struct {
 	core     *rand.Rand
 	seed     int64
@@ -78,18 +83,31 @@ func (r *Random) RandInt(min, max int) int {
 func (r *Random) Int() int {
 	return r.core.Int()
 }
+
 func Runes(size int) []rune {
+	mu.Lock()
+	defer mu.Unlock()
+
 	return randomizer.Runes(size)
 }
 
 func RandString(size int) string {
+	mu.Lock()
+	defer mu.Unlock()
+
 	return randomizer.RandString(size)
 }
 
 func Int() int {
+	mu.Lock()
+	defer mu.Unlock()
+
 	return randomizer.Int()
 }
 
 func RandInt(min, max int) int {
+	mu.Lock()
+	defer mu.Unlock()
+
 	return randomizer.RandInt(min, max)
 }